@@ -2,21 +2,25 @@ package main
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
-	"github.com/xuri/excelize/v2"
 
 	"card_wizard/internal/cards"
 	"card_wizard/internal/deck"
 	"card_wizard/internal/game"
+	"card_wizard/internal/migrate"
 	"card_wizard/internal/pdf"
+	"card_wizard/internal/project"
+	"card_wizard/internal/tabular"
+	"card_wizard/internal/watch"
 )
 
 // ExcelSelection represents a selected Excel file and its sheets
@@ -27,15 +31,34 @@ type ExcelSelection struct {
 
 // App struct
 type App struct {
-	ctx             context.Context
-	cardsSvc        *cards.Service
-	currentGamePath string // Path to the currently loaded/saved game file
+	ctx      context.Context
+	cardsSvc *cards.Service
+	project  *project.Service        // File work behind the dialogs, and the open game's path
+	recent   *project.RecentProjects // Recently opened or saved projects, kept in the user config dir
+
+	autosave     *project.Autosaver // Writes the open game's changes to a recovery file
+	stopAutosave context.CancelFunc
+
+	watchMu     sync.Mutex
+	sheetWatch  *watch.Watcher // Spreadsheet being watched for changes, if any
+	watched     SpreadsheetUpdate
+	watchedDeck deck.Deck // The deck a watched spreadsheet is synced against
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
+	// Without a config directory, recent projects are simply not remembered
+	recentFile, _ := project.DefaultRecentProjectsFile()
+	autosaveDir, err := project.DefaultAutosaveDir()
+	if err != nil {
+		autosaveDir = filepath.Join(os.TempDir(), "card_wizard")
+	}
+	svc := project.NewService()
 	return &App{
 		cardsSvc: cards.NewService(),
+		project:  svc,
+		recent:   project.NewRecentProjects(recentFile),
+		autosave: project.NewAutosaver(svc, autosaveDir),
 	}
 }
 
@@ -43,6 +66,19 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+
+	autosaveCtx, cancel := context.WithCancel(ctx)
+	a.stopAutosave = cancel
+	go a.autosave.Run(autosaveCtx, project.AutosaveInterval)
+}
+
+// shutdown is called when the app is closing
+func (a *App) shutdown(ctx context.Context) {
+	a.StopWatchingSpreadsheet()
+
+	// Keep changes made since the last autosave for the next start
+	a.stopAutosave()
+	a.autosave.Flush()
 }
 
 // Greet returns a greeting for the given name
@@ -60,12 +96,17 @@ func (a *App) SampleDeck() []cards.Card {
 	return a.cardsSvc.SampleDeck(ctx)
 }
 
-// SelectExcelFile opens a file dialog and returns the path and list of sheets
+// SelectExcelFile opens a file dialog and returns the path and list of sheets.
+// Besides Excel workbooks it accepts OpenDocument spreadsheets, and CSV and TSV
+// files, which have a single sheet.
 func (a *App) SelectExcelFile() (*ExcelSelection, error) {
 	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select Excel File",
+		Title: "Select Spreadsheet",
 		Filters: []runtime.FileFilter{
-			{DisplayName: "Excel Files", Pattern: "*.xlsx;*.xls"},
+			{DisplayName: "Spreadsheets", Pattern: tabular.DialogPattern()},
+			{DisplayName: "Excel Files", Pattern: "*.xlsx;*.xlsm"},
+			{DisplayName: "OpenDocument Spreadsheets", Pattern: "*.ods"},
+			{DisplayName: "CSV/TSV Files", Pattern: "*.csv;*.tsv;*.txt"},
 		},
 	})
 	if err != nil {
@@ -75,143 +116,145 @@ func (a *App) SelectExcelFile() (*ExcelSelection, error) {
 		return nil, nil // User cancelled
 	}
 
-	f, err := excelize.OpenFile(selection)
+	sheets, err := project.DeckSheets(selection)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
 	return &ExcelSelection{
 		FilePath: selection,
-		Sheets:   f.GetSheetList(),
+		Sheets:   sheets,
 	}, nil
 }
 
 // GetExcelHeaders returns the headers from the first row of a specific sheet
 func (a *App) GetExcelHeaders(filePath string, sheetName string) ([]string, error) {
-	f, err := excelize.OpenFile(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	rows, err := f.GetRows(sheetName)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(rows) < 1 {
-		return nil, fmt.Errorf("sheet is empty")
-	}
-
-	return rows[0], nil
+	return project.SheetHeaders(filePath, sheetName)
 }
 
-// ImportCardsWithMapping imports cards using a specific column mapping
-func (a *App) ImportCardsWithMapping(filePath string, sheetName string, mapping map[string]string) ([]deck.Card, error) {
-	f, err := excelize.OpenFile(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	rows, err := f.GetRows(sheetName)
-	if err != nil {
-		return nil, err
-	}
+// ImportCardsWithMapping imports cards using a specific column mapping and
+// reports problems with the rows against the deck being imported into. A strict
+// import returns no cards if any row has an error.
+func (a *App) ImportCardsWithMapping(filePath string, sheetName string, mapping map[string]string, d deck.Deck, strict bool) (tabular.ImportResult, error) {
+	return a.project.ImportCards(filePath, sheetName, mapping, d, strict)
+}
 
-	if len(rows) < 2 {
-		return nil, fmt.Errorf("file is empty or missing header")
-	}
+// GetImportMapping returns the column mapping for a sheet exported by the app,
+// or nil when the sheet needs to be mapped by hand
+func (a *App) GetImportMapping(filePath string, sheetName string) (map[string]string, error) {
+	return project.ImportMapping(filePath, sheetName)
+}
 
-	headers := rows[0]
-	headerMap := make(map[string]int)
-	for i, h := range headers {
-		headerMap[h] = i
-	}
+// SyncCardsWithMapping compares a sheet with a deck's cards, matching rows by the
+// "key" column of the mapping, and returns the changes for the user to confirm
+func (a *App) SyncCardsWithMapping(filePath string, sheetName string, mapping map[string]string, d deck.Deck) (tabular.SyncDiff, error) {
+	return project.SyncCards(filePath, sheetName, mapping, d)
+}
 
-	var cards []deck.Card
+// Events emitted while watching a spreadsheet
+const (
+	EventSpreadsheetChanged = "spreadsheet:changed" // Payload: SpreadsheetUpdate
+	EventSpreadsheetError   = "spreadsheet:error"   // Payload: SpreadsheetUpdate with Error set
+)
 
-	// Compile regex for slugification inside the function or package level
-	// For simplicity, we'll do it here or use a helper if we had one.
-	slugRegex := regexp.MustCompile(`[^a-z0-9]+`)
+// SpreadsheetUpdate carries the changes a watched spreadsheet makes to its deck
+type SpreadsheetUpdate struct {
+	DeckID        string                 `json:"deckId"`
+	FilePath      string                 `json:"filePath"`
+	Sheet         string                 `json:"sheet"`
+	RemoveMissing bool                   `json:"removeMissing"` // Remove cards that have no row when applying Diff
+	Diff          *tabular.SyncDiff      `json:"diff,omitempty"`
+	Fields        []deck.FieldDefinition `json:"fields,omitempty"` // Fields declared by the sheet's metadata or its images
+	Issues        []tabular.Issue        `json:"issues,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+}
 
-	for i, row := range rows {
-		if i == 0 {
-			continue
+// WatchSpreadsheet watches an imported spreadsheet and, each time it is saved,
+// syncs it with the deck using the same mapping and emits the changes as an
+// event. Rows are checked against the deck last passed to SetWatchedDeck,
+// leniently, and cards keep what the sheet has no columns for. Only one
+// spreadsheet is watched at a time.
+func (a *App) WatchSpreadsheet(d deck.Deck, filePath string, sheetName string, mapping map[string]string, removeMissing bool) error {
+	a.StopWatchingSpreadsheet()
+
+	update := SpreadsheetUpdate{DeckID: d.ID, FilePath: filePath, Sheet: sheetName, RemoveMissing: removeMissing}
+	w, err := watch.New(filePath, watch.DefaultDelay, func() {
+		a.watchMu.Lock()
+		current := a.watchedDeck
+		a.watchMu.Unlock()
+
+		result, diff, err := a.project.SyncSheet(filePath, sheetName, mapping, current)
+		if err != nil {
+			u := update
+			u.Error = err.Error()
+			runtime.EventsEmit(a.ctx, EventSpreadsheetError, u)
+			return
 		}
+		u := update
+		u.Diff = &diff
+		u.Fields = result.Fields
+		u.Issues = result.Issues
+		runtime.EventsEmit(a.ctx, EventSpreadsheetChanged, u)
+	}, func(err error) {
+		u := update
+		u.Error = err.Error()
+		runtime.EventsEmit(a.ctx, EventSpreadsheetError, u)
+	})
+	if err != nil {
+		return err
+	}
 
-		// Helper to safely get cell value
-		getCell := func(colName string) string {
-			if colName == "" {
-				return ""
-			}
-			idx, ok := headerMap[colName]
-			if !ok || idx >= len(row) {
-				return ""
-			}
-			return row[idx]
-		}
+	a.watchMu.Lock()
+	a.sheetWatch = w
+	a.watched = update
+	a.watchedDeck = d
+	a.watchMu.Unlock()
+	return nil
+}
 
-		var id string
-
-		// Generate from column if specified
-		if mapping["generateIdFrom"] != "" {
-			raw := getCell(mapping["generateIdFrom"])
-			if raw != "" {
-				// Slugify: lowercase, replace non-alphanum with dash, trim dashes
-				slug := strings.ToLower(raw)
-				slug = slugRegex.ReplaceAllString(slug, "-")
-				slug = strings.Trim(slug, "-")
-				id = slug
-			}
-		}
+// SetWatchedDeck gives the watched spreadsheet the deck as it is now, with the
+// edits made in the app since watching started. Other decks are ignored.
+func (a *App) SetWatchedDeck(d deck.Deck) {
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
 
-		// Fallback
-		if id == "" {
-			id = fmt.Sprintf("card-%d", i)
-		}
+	if a.sheetWatch != nil && a.watched.DeckID == d.ID {
+		a.watchedDeck = d
+	}
+}
 
-		countStr := getCell(mapping["count"])
-		count := 1
-		if countStr != "" {
-			fmt.Sscanf(countStr, "%d", &count)
-		}
+// GetWatchedSpreadsheet returns the deck, file and sheet being watched, or nil
+func (a *App) GetWatchedSpreadsheet() *SpreadsheetUpdate {
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
 
-		card := deck.Card{
-			ID:           id,
-			Count:        count,
-			FrontStyleID: getCell(mapping["frontStyle"]),
-			BackStyleID:  getCell(mapping["backStyle"]),
-			Data:         make(map[string]interface{}),
-		}
+	if a.sheetWatch == nil {
+		return nil
+	}
+	watched := a.watched
+	return &watched
+}
 
-		// Map everything else to Data, excluding the mapped system columns
-		systemCols := map[string]bool{
-			mapping["count"]:      true,
-			mapping["frontStyle"]: true,
-			mapping["backStyle"]:  true,
-		}
-		// NOTE: We explicilty DO NOT add mapping["generateIdFrom"] to systemCols
-		// because the user wants to preserve that column in the data.
-
-		for j, cell := range row {
-			if j < len(headers) {
-				header := headers[j]
-				if !systemCols[header] {
-					card.Data[header] = cell
-				}
-			}
-		}
+// StopWatchingSpreadsheet stops watching the spreadsheet, if one is watched
+func (a *App) StopWatchingSpreadsheet() {
+	a.watchMu.Lock()
+	w := a.sheetWatch
+	a.sheetWatch = nil
+	a.watchMu.Unlock()
 
-		cards = append(cards, card)
+	if w != nil {
+		w.Close()
 	}
+}
 
-	return cards, nil
+// ApplyCardSync applies a confirmed sync diff to the cards it was made from
+func (a *App) ApplyCardSync(cards []deck.Card, diff tabular.SyncDiff, removeMissing bool) []deck.Card {
+	return tabular.ApplySync(cards, diff, removeMissing)
 }
 
-// ExportXLSX exports the current deck data to an Excel file
-func (a *App) ExportXLSX(cards []deck.Card, fields []deck.FieldDefinition) error {
+// ExportXLSX exports a deck's cards to an Excel file, with a hidden sheet
+// describing its fields and styles so it can be imported again as is
+func (a *App) ExportXLSX(d deck.Deck) error {
 	selection, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
 		Title:           "Export Excel File",
 		DefaultFilename: "deck_export.xlsx",
@@ -226,180 +269,275 @@ func (a *App) ExportXLSX(cards []deck.Card, fields []deck.FieldDefinition) error
 		return nil // User cancelled
 	}
 
-	f := excelize.NewFile()
-	sheetName := "Sheet1"
-	index, err := f.NewSheet(sheetName)
+	return project.WriteFile(selection, func(w io.Writer) error {
+		return a.project.ExportXLSXTo(d, w)
+	})
+}
+
+// ExportGameXLSX exports all decks in a game to a single XLSX file with multiple
+// sheets. With thumbnails, the images in image fields are embedded over their
+// cells as well.
+func (a *App) ExportGameXLSX(g game.Game, thumbnails bool) error {
+	selection, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title: "Export Game to Excel",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Excel Files", Pattern: "*.xlsx"},
+		},
+		DefaultFilename: fmt.Sprintf("%s.xlsx", g.Name),
+	})
+	if err != nil || selection == "" {
+		return err
+	}
+
+	return project.WriteFile(selection, func(w io.Writer) error {
+		return a.project.ExportGameXLSXTo(g, w, thumbnails)
+	})
+}
+
+// ExportODS exports a deck's cards to an OpenDocument spreadsheet
+func (a *App) ExportODS(d deck.Deck) error {
+	selection, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export OpenDocument Spreadsheet",
+		DefaultFilename: "deck_export.ods",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "OpenDocument Spreadsheets", Pattern: "*.ods"},
+		},
+	})
 	if err != nil {
 		return err
 	}
-	f.SetActiveSheet(index)
+	if selection == "" {
+		return nil // User cancelled
+	}
 
-	// Define standard columns
-	stdHeaders := []string{"ID", "Count", "Front Style", "Back Style"}
+	return project.WriteFile(selection, func(w io.Writer) error {
+		return a.project.ExportODSTo(d, w)
+	})
+}
 
-	// Combine standard headers with custom fields
-	var allHeaders []string
-	allHeaders = append(allHeaders, stdHeaders...)
-	for _, field := range fields {
-		allHeaders = append(allHeaders, field.Name)
+// ExportGameODS exports all decks in a game to a single ODS file with one sheet per deck
+func (a *App) ExportGameODS(g game.Game) error {
+	selection, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title: "Export Game to OpenDocument Spreadsheet",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "OpenDocument Spreadsheets", Pattern: "*.ods"},
+		},
+		DefaultFilename: fmt.Sprintf("%s.ods", g.Name),
+	})
+	if err != nil || selection == "" {
+		return err
 	}
 
-	// Write headers to first row
-	for i, header := range allHeaders {
-		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
-		f.SetCellValue(sheetName, cell, header)
+	return project.WriteFile(selection, func(w io.Writer) error {
+		return a.project.ExportGameODSTo(g, w)
+	})
+}
+
+// SaveGame saves the game over its file, or asks where to save a game that
+// has none. It returns the path saved to, or "" if the user cancelled.
+func (a *App) SaveGame(g game.Game) (string, error) {
+	if a.project.GamePath() == "" {
+		return a.SaveGameAs(g)
 	}
 
-	// Write Data
-	for i, card := range cards {
-		rowNum := i + 2
+	path, err := a.project.SaveGame(g)
+	if err != nil {
+		return "", err
+	}
+	a.saved(path, g.Name)
+	return path, nil
+}
 
-		// Write Standard Columns
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", rowNum), card.ID)
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", rowNum), card.Count)
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", rowNum), card.FrontStyleID) // This is ID, ideally we export Name if we had access to Deck, but ID is better for roundtrip if it's "style-1"
-		f.SetCellValue(sheetName, fmt.Sprintf("D%d", rowNum), card.BackStyleID)
+// SaveGameAs saves the game to a JSON file the user picks, which becomes the
+// open game. It returns the path saved to, or "" if the user cancelled.
+func (a *App) SaveGameAs(g game.Game) (string, error) {
+	// Refuse values that do not fit their fields before asking where to save
+	if err := project.ValidateGame(&g); err != nil {
+		return "", err
+	}
 
-		// Write Custom Fields
-		for j, field := range fields {
-			// Offset by len(stdHeaders)
-			colNum := j + 1 + len(stdHeaders)
-			cell, _ := excelize.CoordinatesToCellName(colNum, rowNum)
-			val := card.Data[field.Name]
-			f.SetCellValue(sheetName, cell, val)
-		}
+	defaultName := "game.json"
+	if path := a.project.GamePath(); path != "" {
+		defaultName = filepath.Base(path)
+	}
+	selection, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:            "Save Game As",
+		DefaultDirectory: a.project.GameDir(),
+		Filters: []runtime.FileFilter{
+			{DisplayName: "JSON Files", Pattern: "*.json"},
+		},
+		DefaultFilename: defaultName,
+	})
+	if err != nil {
+		return "", err
+	}
+	if selection == "" {
+		return "", nil // User cancelled
 	}
 
-	if err := f.SaveAs(selection); err != nil {
-		return err
+	if err := a.project.SaveGameTo(g, selection); err != nil {
+		return "", err
 	}
+	a.saved(selection, g.Name)
+	return selection, nil
+}
 
-	return nil
+// saved remembers a saved game as a recent project and drops its recovery
+// files, which the save made stale
+func (a *App) saved(path, name string) {
+	a.addRecent(path, name)
+	_ = a.autosave.Saved()
 }
 
-// ExportGameXLSX exports all decks in a game to a single XLSX file with multiple sheets
-func (a *App) ExportGameXLSX(g game.Game) error {
-	selection, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
-		Title: "Export Game to Excel",
+// LoadGame loads a game from a JSON file
+func (a *App) LoadGame() (*game.Game, error) {
+	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Load Game",
 		Filters: []runtime.FileFilter{
-			{DisplayName: "Excel Files", Pattern: "*.xlsx"},
+			{DisplayName: "JSON Files", Pattern: "*.json"},
 		},
-		DefaultFilename: fmt.Sprintf("%s.xlsx", g.Name),
 	})
-	if err != nil || selection == "" {
-		return err
+	if err != nil {
+		return nil, err
+	}
+	if selection == "" {
+		return nil, nil // User cancelled
 	}
 
-	f := excelize.NewFile()
-	defer f.Close()
-
-	// Delete the default Sheet1
-	f.DeleteSheet("Sheet1")
+	return a.openProject(selection)
+}
 
-	// Create a sheet for each deck
-	for deckIdx, d := range g.Decks {
-		sheetName := d.Name
-		if sheetName == "" {
-			sheetName = fmt.Sprintf("Deck %d", deckIdx+1)
+// openProject opens a game file or bundle and remembers it as a recent project
+func (a *App) openProject(path string) (*game.Game, error) {
+	var g *game.Game
+	var result migrate.Result
+	var err error
+	if strings.EqualFold(filepath.Ext(path), project.BundleExt) {
+		var dir string
+		if dir, err = project.BundleWorkDir(path); err != nil {
+			return nil, err
 		}
-
-		// Ensure unique sheet name (Excel has 31 char limit and no duplicates)
-		if len(sheetName) > 31 {
-			sheetName = sheetName[:31]
+		if g, result, err = a.project.OpenBundle(path, dir); err != nil {
+			return nil, err
 		}
+		// Save writes the unpacked game back into the bundle
+		a.project.SetBundlePath(path)
+	} else if g, result, err = a.project.LoadGameFrom(path); err != nil {
+		return nil, err
+	}
 
-		// Create sheet
-		index, err := f.NewSheet(sheetName)
-		if err != nil {
-			return fmt.Errorf("failed to create sheet %s: %w", sheetName, err)
+	a.autosave.Reset()
+	a.addRecent(path, g.Name)
+	a.reportUpgrade(result)
+
+	// Offer changes to this game that were autosaved but never saved
+	if r, ok := a.autosave.Recovery(); ok {
+		restore, err := a.offerRecovery(r)
+		switch {
+		case err != nil:
+			// Keep the changes to offer again next time
+		case restore:
+			return r.Restore(a.project), nil
+		default:
+			_ = os.Remove(r.File)
 		}
+	}
+	return g, nil
+}
 
-		// If this is the first sheet, set it as active
-		if deckIdx == 0 {
-			f.SetActiveSheet(index)
-		}
+// reportUpgrade tells the user when an opened project was saved by another version
+func (a *App) reportUpgrade(result migrate.Result) {
+	switch {
+	case result.Upgraded():
+		runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+			Type:    runtime.WarningDialog,
+			Title:   "Project Upgraded",
+			Message: fmt.Sprintf("This project was saved by an older version of Card Wizard and has been upgraded:\n\n- %s\n\nSaving will write the new format, which older versions cannot open.", strings.Join(result.Applied, "\n- ")),
+		})
+	case result.Newer:
+		runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+			Type:    runtime.WarningDialog,
+			Title:   "Newer Project Format",
+			Message: "This project was saved by a newer version of Card Wizard. Settings this version does not understand are kept, but may not be shown or used. Saving keeps the newer format version.",
+		})
+	}
+}
 
-		// Write headers
-		stdHeaders := []string{"ID", "Count", "Front Style", "Back Style"}
-		var allHeaders []string
-		allHeaders = append(allHeaders, stdHeaders...)
-		for _, field := range d.Fields {
-			allHeaders = append(allHeaders, field.Name)
-		}
+// addRecent remembers a project as recently used. Failing to is not worth
+// failing the save or open over.
+func (a *App) addRecent(path, name string) {
+	_ = a.recent.Add(path, name)
+}
 
-		for i, header := range allHeaders {
-			cell, _ := excelize.CoordinatesToCellName(i+1, 1)
-			f.SetCellValue(sheetName, cell, header)
-		}
+// GetRecentProjects returns the recently opened or saved projects, newest first
+func (a *App) GetRecentProjects() ([]project.RecentProject, error) {
+	return a.recent.List()
+}
 
-		// Write card data
-		for i, card := range d.Cards {
-			rowNum := i + 2
-
-			// Write standard columns
-			f.SetCellValue(sheetName, fmt.Sprintf("A%d", rowNum), card.ID)
-			f.SetCellValue(sheetName, fmt.Sprintf("B%d", rowNum), card.Count)
-			f.SetCellValue(sheetName, fmt.Sprintf("C%d", rowNum), card.FrontStyleID)
-			f.SetCellValue(sheetName, fmt.Sprintf("D%d", rowNum), card.BackStyleID)
-
-			// Write custom fields
-			for j, field := range d.Fields {
-				colNum := j + 1 + len(stdHeaders)
-				cell, _ := excelize.CoordinatesToCellName(colNum, rowNum)
-				val := card.Data[field.Name]
-				f.SetCellValue(sheetName, cell, val)
-			}
-		}
+// OpenRecentProject opens a game file or bundle from the recent projects list,
+// forgetting it if it no longer exists
+func (a *App) OpenRecentProject(path string) (*game.Game, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		_ = a.recent.Remove(path)
+		return nil, fmt.Errorf("%s no longer exists and was removed from the recent projects", filepath.Base(path))
 	}
+	return a.openProject(path)
+}
 
-	if err := f.SaveAs(selection); err != nil {
-		return err
-	}
+// RemoveRecentProject forgets a project in the recent projects list
+func (a *App) RemoveRecentProject(path string) error {
+	return a.recent.Remove(path)
+}
 
-	return nil
+// ClearRecentProjects forgets every recent project
+func (a *App) ClearRecentProjects() error {
+	return a.recent.Clear()
 }
 
-// SaveGame saves the current game to a JSON file
-func (a *App) SaveGame(g game.Game) error {
+// SaveBundle saves the game as a single .cwz file holding its images and fonts,
+// returning the assets that were left out, or nil if the user cancelled
+func (a *App) SaveBundle(g game.Game) (*project.BundleReport, error) {
+	// Refuse values that do not fit their fields before asking where to save
+	if err := project.ValidateGame(&g); err != nil {
+		return nil, err
+	}
+
 	selection, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
-		Title: "Save Game",
+		Title: "Save Bundle",
 		Filters: []runtime.FileFilter{
-			{DisplayName: "JSON Files", Pattern: "*.json"},
+			{DisplayName: "Card Wizard Bundles", Pattern: "*" + project.BundleExt},
 		},
-		DefaultFilename: "game.json",
+		DefaultFilename: "game" + project.BundleExt,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if selection == "" {
-		return nil // User cancelled
-	}
-
-	// Store the game path for relative path resolution
-	a.currentGamePath = selection
-
-	// Convert absolute image paths to relative paths before saving
-	gameDir := filepath.Dir(selection)
-	for i := range g.Decks {
-		g.Decks[i] = a.convertPathsToRelative(g.Decks[i], gameDir)
+		return nil, nil // User cancelled
 	}
 
-	data, err := json.MarshalIndent(g, "", "  ")
+	report, err := a.project.SaveBundleTo(g, selection)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	return os.WriteFile(selection, data, 0644)
+	a.addRecent(selection, g.Name)
+	return &report, nil
 }
 
-// LoadGame loads a game from a JSON file
-func (a *App) LoadGame() (*game.Game, error) {
-	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Load Game",
+// selectBundle asks for a .cwz file to open
+func (a *App) selectBundle(title string) (string, error) {
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: title,
 		Filters: []runtime.FileFilter{
-			{DisplayName: "JSON Files", Pattern: "*.json"},
+			{DisplayName: "Card Wizard Bundles", Pattern: "*" + project.BundleExt},
 		},
 	})
+}
+
+// OpenBundle opens a .cwz file, unpacking it to a working directory in the
+// user's cache. Saving the game packs it back into the bundle.
+func (a *App) OpenBundle() (*game.Game, error) {
+	selection, err := a.selectBundle("Open Bundle")
 	if err != nil {
 		return nil, err
 	}
@@ -407,38 +545,119 @@ func (a *App) LoadGame() (*game.Game, error) {
 		return nil, nil // User cancelled
 	}
 
-	// Store the game path for relative path resolution
-	a.currentGamePath = selection
+	return a.openProject(selection)
+}
 
-	data, err := os.ReadFile(selection)
+// ExtractBundle unpacks a .cwz file into a folder the user picks and opens the
+// game there, turning the bundle back into a regular project
+func (a *App) ExtractBundle() (*game.Game, error) {
+	selection, err := a.selectBundle("Extract Bundle")
 	if err != nil {
 		return nil, err
 	}
+	if selection == "" {
+		return nil, nil // User cancelled
+	}
 
-	var g game.Game
-	if err := json.Unmarshal(data, &g); err != nil {
-		// Fallback: Try to load as a single Deck and wrap it in a Game
-		var d deck.Deck
-		if err2 := json.Unmarshal(data, &d); err2 == nil {
-			// It's a deck!
-			if d.ID == "" {
-				d.ID = "deck-1" // Assign a default ID
-			}
-			g = game.Game{
-				Name:  d.Name,
-				Decks: []deck.Deck{d},
-			}
-		} else {
-			return nil, err // Return original error
-		}
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Folder to Extract the Bundle to",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return nil, nil // User cancelled
 	}
 
-	return &g, nil
+	g, result, err := a.project.OpenBundle(selection, dir)
+	if err != nil {
+		return nil, err
+	}
+	a.autosave.Reset()
+	a.addRecent(a.project.GamePath(), g.Name)
+	a.reportUpgrade(result)
+	return g, nil
 }
 
 // NewGame resets the current game path, effectively starting a new project
 func (a *App) NewGame() {
-	a.currentGamePath = ""
+	a.project.SetGamePath("")
+	a.autosave.Reset()
+}
+
+// UpdateAutosave hands the backend the latest copy of the game, which is
+// written to its recovery file every AutosaveInterval
+func (a *App) UpdateAutosave(g game.Game) {
+	a.autosave.Update(g)
+}
+
+// CheckRecovery offers, one at a time, to restore games with autosaved changes
+// that were never saved, newest first. Declined changes are deleted. It
+// returns the restored game, or nil if there was none.
+func (a *App) CheckRecovery() (*game.Game, error) {
+	recent, _ := a.recent.List()
+	paths := make([]string, len(recent))
+	for i, p := range recent {
+		paths[i] = p.Path
+	}
+
+	for _, r := range a.autosave.Find(paths) {
+		restore, err := a.offerRecovery(r)
+		if err != nil {
+			return nil, err
+		}
+		if restore {
+			return r.Restore(a.project), nil
+		}
+		if err := os.Remove(r.File); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// offerRecovery asks whether to restore autosaved changes
+func (a *App) offerRecovery(r project.Recovery) (bool, error) {
+	source := "an unsaved game"
+	if r.BundlePath != "" {
+		source = filepath.Base(r.BundlePath)
+	} else if r.GamePath != "" {
+		source = r.GamePath
+	}
+	result, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+		Type:          runtime.QuestionDialog,
+		Title:         "Restore Unsaved Changes?",
+		Message:       fmt.Sprintf("Changes to \"%s\" (%s) from %s were never saved.\n\nRestore them? Otherwise they are deleted.", r.Game.Name, source, r.SavedAt.Format("Jan 2 15:04")),
+		Buttons:       []string{"Restore", "Discard"},
+		DefaultButton: "Restore",
+		CancelButton:  "Discard",
+	})
+	if err != nil {
+		return false, err
+	}
+	// Linux and Windows answer question dialogs with Yes or No
+	return result == "Restore" || result == "Yes", nil
+}
+
+// ListSnapshots returns the snapshots kept of the open game's saves, newest first
+func (a *App) ListSnapshots() ([]project.Snapshot, error) {
+	return a.project.Snapshots()
+}
+
+// DiffSnapshots returns what changed from one snapshot of the open game to another
+func (a *App) DiffSnapshots(fromID string, toID string) (game.Diff, error) {
+	return a.project.DiffSnapshots(fromID, toID)
+}
+
+// DiffWithSnapshot returns what changed from a snapshot to the game being edited
+func (a *App) DiffWithSnapshot(id string, g game.Game) (game.Diff, error) {
+	return a.project.DiffWithSnapshot(id, g)
+}
+
+// RestoreSnapshot returns a snapshot of the open game to replace the game being
+// edited; saving it writes it back to the game file
+func (a *App) RestoreSnapshot(id string) (*game.Game, error) {
+	return a.project.RestoreSnapshot(id)
 }
 
 // GeneratePDF generates a PDF for the deck
@@ -457,8 +676,27 @@ func (a *App) GeneratePDF(d deck.Deck) error {
 		return nil // User cancelled
 	}
 
-	gen := pdf.NewGenerator()
-	return gen.Generate(d, selection)
+	return a.project.GeneratePDFTo(d, selection)
+}
+
+// GenerateCalibrationPDF saves a double-sided sheet for measuring how far the printer
+// shifts back pages, using the deck's paper, duplex mode and current back offsets
+func (a *App) GenerateCalibrationPDF(d deck.Deck) error {
+	selection, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title: "Save Calibration PDF",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "PDF Files", Pattern: "*.pdf"},
+		},
+		DefaultFilename: "calibration.pdf",
+	})
+	if err != nil {
+		return err
+	}
+	if selection == "" {
+		return nil // User cancelled
+	}
+
+	return a.project.GenerateCalibrationPDFTo(d, selection)
 }
 
 // SelectImageFile opens a file dialog to select an image
@@ -469,54 +707,16 @@ func (a *App) SelectImageFile() (string, error) {
 			{DisplayName: "Images", Pattern: "*.png;*.jpg;*.jpeg;*.gif;*.webp"},
 		},
 	})
+	if selection != "" {
+		// The card may keep this path if copying it into the project fails
+		a.project.AllowFile(selection)
+	}
 	return selection, err
 }
 
 // AddProjectImage copies an image to the project's "images" directory
 func (a *App) AddProjectImage(srcPath string) (string, error) {
-	if a.currentGamePath == "" {
-		return "", fmt.Errorf("no game loaded")
-	}
-
-	gameDir := filepath.Dir(a.currentGamePath)
-	imagesDir := filepath.Join(gameDir, "images")
-
-	// Create images directory if it doesn't exist
-	if err := os.MkdirAll(imagesDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create images directory: %w", err)
-	}
-
-	// Get filename from source path
-	fileName := filepath.Base(srcPath)
-	destPath := filepath.Join(imagesDir, fileName)
-
-	// Check if file already exists
-	// If it does, we generate a unique name to avoid overwriting accidentally on "Add"
-	// For "Replace", we will have a specific method
-	ext := filepath.Ext(fileName)
-	name := strings.TrimSuffix(fileName, ext)
-	counter := 1
-	for {
-		if _, err := os.Stat(destPath); os.IsNotExist(err) {
-			break
-		}
-		// File exists, try next counter
-		fileName = fmt.Sprintf("%s_%d%s", name, counter, ext)
-		destPath = filepath.Join(imagesDir, fileName)
-		counter++
-	}
-
-	input, err := os.ReadFile(srcPath)
-	if err != nil {
-		return "", err
-	}
-
-	if err := os.WriteFile(destPath, input, 0644); err != nil {
-		return "", err
-	}
-
-	// Return relative path using forward slashes
-	return filepath.ToSlash(filepath.Join("images", fileName)), nil
+	return a.project.AddImage(srcPath)
 }
 
 // SelectImageFiles opens a file dialog to select multiple images
@@ -527,90 +727,30 @@ func (a *App) SelectImageFiles() ([]string, error) {
 			{DisplayName: "Images", Pattern: "*.png;*.jpg;*.jpeg;*.gif;*.webp"},
 		},
 	})
+	for _, path := range selection {
+		a.project.AllowFile(path)
+	}
 	return selection, err
 }
 
 // AddProjectImages adds multiple images to the project
 func (a *App) AddProjectImages(srcPaths []string) ([]string, error) {
-	var addedPaths []string
-	var errs []string
-
-	for _, srcPath := range srcPaths {
-		path, err := a.AddProjectImage(srcPath)
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("%s: %v", filepath.Base(srcPath), err))
-		} else {
-			addedPaths = append(addedPaths, path)
-		}
-	}
-
-	if len(errs) > 0 {
-		return addedPaths, fmt.Errorf("some images failed to import: %s", strings.Join(errs, "; "))
-	}
-
-	return addedPaths, nil
+	return a.project.AddImages(srcPaths)
 }
 
 // ListProjectImages returns a list of filenames in the project's "images" directory
 func (a *App) ListProjectImages() ([]string, error) {
-	if a.currentGamePath == "" {
-		return nil, fmt.Errorf("no game loaded")
-	}
-
-	gameDir := filepath.Dir(a.currentGamePath)
-	imagesDir := filepath.Join(gameDir, "images")
-
-	// Create images directory if it doesn't exist (just in case)
-	if err := os.MkdirAll(imagesDir, 0755); err != nil {
-		return nil, nil // Return empty if we can't create it, or error? Let's return empty.
-	}
-
-	files, err := os.ReadDir(imagesDir)
-	if err != nil {
-		return nil, err
-	}
-
-	var images []string
-	for _, file := range files {
-		if !file.IsDir() {
-			// Filter for image extensions if needed, but for now assuming mostly images
-			ext := strings.ToLower(filepath.Ext(file.Name()))
-			if ext == ".png" || ext == ".jpg" || ext == ".jpeg" || ext == ".gif" || ext == ".webp" {
-				images = append(images, file.Name())
-			}
-		}
-	}
-
-	return images, nil
+	return a.project.ListImages()
 }
 
 // DeleteProjectImage deletes an image from the project's "images" directory
 func (a *App) DeleteProjectImage(filename string) error {
-	if a.currentGamePath == "" {
-		return fmt.Errorf("no game loaded")
-	}
-
-	gameDir := filepath.Dir(a.currentGamePath)
-	imagePath := filepath.Join(gameDir, "images", filename)
-
-	return os.Remove(imagePath)
+	return a.project.DeleteImage(filename)
 }
 
 // ReplaceProjectImage overwrites a project image with a new file
 func (a *App) ReplaceProjectImage(targetFilename string, srcPath string) error {
-	if a.currentGamePath == "" {
-		return fmt.Errorf("no game loaded")
-	}
-
-	gameDir := filepath.Dir(a.currentGamePath)
-	destPath := filepath.Join(gameDir, "images", targetFilename)
-
-	input, err := os.ReadFile(srcPath)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(destPath, input, 0644)
+	return a.project.ReplaceImage(targetFilename, srcPath)
 }
 
 // SelectFontFile opens a file dialog to select a font
@@ -621,26 +761,26 @@ func (a *App) SelectFontFile() (string, error) {
 			{DisplayName: "Fonts", Pattern: "*.ttf;*.otf;*.woff;*.woff2"},
 		},
 	})
+	if selection != "" {
+		// Fonts stay where the user picked them, so serve them from there
+		a.project.AllowFile(selection)
+	}
 	return selection, err
 }
 
 // LoadImageAsDataURL reads a local image file and returns base64 content
 func (a *App) LoadImageAsDataURL(path string) (string, error) {
-	// Resolve relative paths against the deck directory
-	resolvedPath := a.ResolveImagePath(path)
-
-	data, err := os.ReadFile(resolvedPath)
-	if err != nil {
-		return "", err
-	}
-
-	mimeType := "image/png" // default
-	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data)), nil
+	return a.project.ImageDataURL(path)
 }
 
 // GetPDFLayout returns the layout configuration for the PDF
 func (a *App) GetPDFLayout(d deck.Deck) (deck.PDFLayout, error) {
-	return pdf.CalculateLayout(d), nil
+	return pdf.CalculateLayout(d)
+}
+
+// GetPaperSizes returns the preset paper sizes available for PDF generation
+func (a *App) GetPaperSizes() []pdf.PaperSize {
+	return pdf.PaperSizes()
 }
 
 // SaveImages saves a map of filename:base64content to the user's computer
@@ -655,77 +795,10 @@ func (a *App) SaveImages(images map[string]string) error {
 		return nil // User cancelled
 	}
 
-	for filename, b64Data := range images {
-		// Data URI: "data:image/png;base64,..."
-		parts := strings.Split(b64Data, ",")
-		if len(parts) != 2 {
-			continue // Skip invalid data
-		}
-
-		dec, err := base64.StdEncoding.DecodeString(parts[1])
-		if err != nil {
-			return fmt.Errorf("failed to decode image %s: %w", filename, err)
-		}
-
-		path := filepath.Join(selection, filename)
-		if err := os.WriteFile(path, dec, 0644); err != nil {
-			return fmt.Errorf("failed to save image %s: %w", filename, err)
-		}
-	}
-
-	return nil
+	return project.SaveDataURLs(images, selection)
 }
 
 // ResolveImagePath resolves a potentially relative image path to an absolute path
 func (a *App) ResolveImagePath(path string) string {
-	// If already absolute, return as-is
-	if filepath.IsAbs(path) {
-		return path
-	}
-
-	// If no game is loaded, return path as-is (will likely fail, but that's expected)
-	if a.currentGamePath == "" {
-		return path
-	}
-
-	// Resolve relative to game directory
-	gameDir := filepath.Dir(a.currentGamePath)
-	return filepath.Join(gameDir, path)
-}
-
-// convertPathsToRelative converts all absolute image paths in a deck to relative paths
-func (a *App) convertPathsToRelative(d deck.Deck, deckDir string) deck.Deck {
-	// Convert paths in card data
-	for i := range d.Cards {
-		d.Cards[i].Data = a.convertMapPathsToRelative(d.Cards[i].Data, deckDir)
-	}
-
-	return d
-}
-
-// convertMapPathsToRelative converts absolute paths in a map to relative paths
-func (a *App) convertMapPathsToRelative(data map[string]interface{}, deckDir string) map[string]interface{} {
-	result := make(map[string]interface{})
-
-	for key, value := range data {
-		if strValue, ok := value.(string); ok {
-			// Check if this looks like a file path (contains path separators or drive letters)
-			if strings.Contains(strValue, string(filepath.Separator)) || strings.Contains(strValue, "/") || strings.Contains(strValue, ":\\") {
-				// Try to make it relative
-				if filepath.IsAbs(strValue) {
-					relPath, err := filepath.Rel(deckDir, strValue)
-					if err == nil {
-						// Successfully made relative - use forward slashes for cross-platform compatibility
-						relPath = filepath.ToSlash(relPath)
-						result[key] = relPath
-						continue
-					}
-				}
-			}
-		}
-		// Keep value as-is if not a convertible path
-		result[key] = value
-	}
-
-	return result
+	return a.project.ResolvePath(path)
 }
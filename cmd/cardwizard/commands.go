@@ -0,0 +1,426 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+	"card_wizard/internal/project"
+	"card_wizard/internal/tabular"
+)
+
+// runBuild generates the print PDF for a deck
+func runBuild(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("build", "game.json [--deck ID] --out deck.pdf", stderr)
+	deckID := fs.String("deck", "", "ID or name of the deck to build; optional when the game has one deck")
+	out := fs.String("out", "", "PDF file to write")
+	calibration := fs.Bool("calibration", false, "write the back page calibration sheet instead of the cards")
+	gamePath, err := parseGameArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		fmt.Fprintln(stderr, "cardwizard build: --out is required")
+		return errUsage
+	}
+
+	svc, g, err := loadGame(gamePath)
+	if err != nil {
+		return err
+	}
+	d, err := findDeck(g, *deckID)
+	if err != nil {
+		return err
+	}
+
+	if *calibration {
+		err = svc.GenerateCalibrationPDFTo(*d, *out)
+	} else {
+		// Images pre-rendered by the app may be older than the file's cards, so
+		// render every card from its layout
+		d.RenderedCards = nil
+		err = svc.GeneratePDFTo(*d, *out)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Wrote %s for deck %s\n", *out, d.Name)
+	return nil
+}
+
+// runExportXLSX exports decks to an Excel workbook that imports again as is
+func runExportXLSX(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("export-xlsx", "game.json [--deck ID] --out cards.xlsx", stderr)
+	deckID := fs.String("deck", "", "ID or name of the deck to export; all decks when empty")
+	out := fs.String("out", "", "workbook to write")
+	thumbnails := fs.Bool("thumbnails", false, "embed thumbnails of the images in image fields")
+	gamePath, err := parseGameArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		fmt.Fprintln(stderr, "cardwizard export-xlsx: --out is required")
+		return errUsage
+	}
+
+	svc, g, err := loadGame(gamePath)
+	if err != nil {
+		return err
+	}
+	if *deckID != "" {
+		d, err := findDeck(g, *deckID)
+		if err != nil {
+			return err
+		}
+		g.Decks = []deck.Deck{*d}
+	}
+
+	err = project.WriteFile(*out, func(w io.Writer) error {
+		return svc.ExportGameXLSXTo(*g, w, *thumbnails)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Wrote %s with %d decks\n", *out, len(g.Decks))
+	return nil
+}
+
+// sheetFlags are the flags of commands that read a spreadsheet
+type sheetFlags struct {
+	file    *string
+	sheet   *string
+	mapping mappingFlag
+}
+
+func addSheetFlags(fs *flag.FlagSet) sheetFlags {
+	f := sheetFlags{
+		file:    fs.String("file", "", "spreadsheet to read (.xlsx, .ods, .csv or .tsv)"),
+		sheet:   fs.String("sheet", "", "sheet to read; the deck's exported sheet or the first sheet when empty"),
+		mapping: mappingFlag{},
+	}
+	fs.Var(f.mapping, "map", "column mapping as key=Header, repeatable; keys are "+
+		tabular.MapID+", "+tabular.MapGenerateIDFrom+", "+tabular.MapCount+", "+tabular.MapFrontStyle+", "+
+		tabular.MapBackStyle+" and "+tabular.MapKey+". Workbooks exported by Card Wizard need none.")
+	return f
+}
+
+// sheet is a spreadsheet sheet read for a deck
+type sheet struct {
+	name     string
+	rows     [][]string
+	mapping  map[string]string
+	metadata *tabular.SheetMetadata
+	pictures []tabular.Picture
+}
+
+// read reads the sheet to import into deckID, with the mapping of an exported
+// workbook overridden by any --map flags. Without --sheet, the sheet exported
+// from the deck is read, or else the first sheet.
+func (f sheetFlags) read(deckID string) (*sheet, error) {
+	src, err := tabular.Open(*f.file)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	meta, err := tabular.ReadMetadata(src)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &sheet{name: *f.sheet}
+	if s.name == "" {
+		for _, name := range src.Sheets() {
+			if m := meta[name]; m != nil && m.DeckID == deckID {
+				s.name = name
+				break
+			}
+			if s.name == "" && name != tabular.MetadataSheet {
+				s.name = name
+			}
+		}
+	}
+	if s.rows, err = src.Rows(s.name); err != nil {
+		return nil, err
+	}
+	s.metadata = meta[s.name]
+	s.mapping = make(map[string]string)
+	if s.metadata != nil {
+		s.mapping = s.metadata.Mapping()
+	}
+	for k, v := range f.mapping {
+		s.mapping[k] = v
+	}
+
+	if ps, ok := src.(tabular.PictureSource); ok {
+		if s.pictures, err = ps.Pictures(s.name); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// importOptions returns the options for importing the sheet into the game in
+// gameDir. Embedded images are only saved when save is set.
+func (s *sheet) importOptions(gameDir string, strict, save bool) tabular.ImportOptions {
+	opts := tabular.ImportOptions{
+		Strict:   strict,
+		ImageDir: gameDir,
+		Metadata: s.metadata,
+		Pictures: s.pictures,
+	}
+	if save {
+		opts.SaveImage = func(fileName string, data []byte) (string, error) {
+			return project.SaveImage(gameDir, fileName, data, true)
+		}
+	}
+	return opts
+}
+
+// printIssues writes import issues one per line
+func printIssues(w io.Writer, source string, issues []tabular.Issue) {
+	for _, issue := range issues {
+		column := ""
+		if issue.Column != "" {
+			column = fmt.Sprintf(" [%s]", issue.Column)
+		}
+		fmt.Fprintf(w, "%s:%d:%s %s: %s\n", source, issue.Row, column, issue.Severity, issue.Message)
+	}
+}
+
+// runImport replaces a deck's cards with a sheet's, or syncs them, and saves the game
+func runImport(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("import", "game.json --deck ID --file cards.xlsx [--map key=Header]...", stderr)
+	deckID := fs.String("deck", "", "ID or name of the deck to import into; optional when the game has one deck")
+	sf := addSheetFlags(fs)
+	sync := fs.Bool("sync", false, "update cards matched by the key column instead of replacing all cards")
+	removeMissing := fs.Bool("remove-missing", false, "with --sync, remove cards that have no row in the sheet")
+	strict := fs.Bool("strict", false, "import nothing when any row has an error")
+	out := fs.String("out", "", "game file to write; the input game file when empty")
+	gamePath, err := parseGameArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *sf.file == "" {
+		fmt.Fprintln(stderr, "cardwizard import: --file is required")
+		return errUsage
+	}
+	if *out == "" {
+		*out = gamePath
+	}
+
+	svc, g, err := loadGame(gamePath)
+	if err != nil {
+		return err
+	}
+	d, err := findDeck(g, *deckID)
+	if err != nil {
+		return err
+	}
+	s, err := sf.read(d.ID)
+	if err != nil {
+		return err
+	}
+	source := filepath.Base(*sf.file)
+
+	// A sync checks the sheet like an import and uses its rows with the
+	// embedded pictures saved. Bad counts are reported here, so the sync's own
+	// issues are not printed again.
+	result, err := tabular.ImportCards(s.rows, s.mapping, *d, s.importOptions(svc.GameDir(), *strict, true))
+	if err != nil {
+		return err
+	}
+	printIssues(stderr, source, result.Issues)
+	if result.Cards == nil {
+		return errInvalid
+	}
+
+	if *sync {
+		diff, err := tabular.SyncCards(*d, result.Rows, s.mapping)
+		if err != nil {
+			return err
+		}
+		*d = tabular.MergeImport(*d, tabular.ApplySync(d.Cards, diff, *removeMissing), result.Fields, s.rows[0])
+		removed := 0
+		if *removeMissing {
+			removed = len(diff.Removed)
+		}
+		fmt.Fprintf(stdout, "Synced deck %s: %d added, %d changed, %d removed, %d unchanged\n",
+			d.Name, len(diff.Added), len(diff.Changed), removed, diff.Unchanged)
+	} else {
+		*d = tabular.MergeImport(*d, result.Cards, result.Fields, s.rows[0])
+		fmt.Fprintf(stdout, "Imported %d cards into deck %s\n", len(result.Cards), d.Name)
+	}
+
+	// Paths in the game and the sheet are relative to the game's folder
+	outDir, err := filepath.Abs(filepath.Dir(*out))
+	if err != nil {
+		return err
+	}
+	if gameDir, _ := filepath.Abs(svc.GameDir()); outDir != gameDir {
+		rebasePaths(svc, g, outDir)
+	}
+	return svc.SaveGameTo(*g, *out)
+}
+
+// rebasePaths rewrites the relative image and font paths of a game's decks to
+// be relative to dir, so that they point at the same files once the game is
+// saved there. URLs and data URLs are left alone.
+func rebasePaths(svc *project.Service, g *game.Game, dir string) {
+	rebase := func(p string) string {
+		if p == "" || filepath.IsAbs(p) || strings.Contains(p, "://") || strings.HasPrefix(p, "data:") {
+			return p
+		}
+		abs, err := filepath.Abs(svc.ResolvePath(p))
+		if err != nil {
+			return p
+		}
+		if rel, err := filepath.Rel(dir, abs); err == nil {
+			return filepath.ToSlash(rel)
+		}
+		return abs
+	}
+
+	for i := range g.Decks {
+		d := &g.Decks[i]
+		for _, c := range d.Cards {
+			for _, f := range d.Fields {
+				if f.Type != deck.FieldImage && f.Type != deck.FieldIcon {
+					continue
+				}
+				if p, ok := c.Data[f.Name].(string); ok {
+					c.Data[f.Name] = rebase(p)
+				}
+			}
+		}
+		for _, styles := range []map[string]deck.CardLayout{d.FrontStyles, d.BackStyles} {
+			for _, layout := range styles {
+				for j, el := range layout.Elements {
+					if el.Type == "image" {
+						layout.Elements[j].StaticText = rebase(el.StaticText)
+					}
+				}
+			}
+		}
+		for j := range d.CustomFonts {
+			d.CustomFonts[j].Path = rebase(d.CustomFonts[j].Path)
+		}
+	}
+}
+
+// runValidate checks a game's decks, or a sheet against a deck without importing it
+func runValidate(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("validate", "game.json [--deck ID] [--file cards.xlsx [--map key=Header]...]", stderr)
+	deckID := fs.String("deck", "", "ID or name of the deck to check; all decks when empty")
+	sf := addSheetFlags(fs)
+	strict := fs.Bool("strict", false, "fail on warnings as well as errors")
+	gamePath, err := parseGameArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	_, g, err := loadGame(gamePath)
+	if err != nil {
+		return err
+	}
+	gameDir := filepath.Dir(gamePath)
+
+	var issues []tabular.Issue
+	source := filepath.Base(gamePath)
+	if *sf.file != "" {
+		d, err := findDeck(g, *deckID)
+		if err != nil {
+			return err
+		}
+		s, err := sf.read(d.ID)
+		if err != nil {
+			return err
+		}
+		result, err := tabular.ImportCards(s.rows, s.mapping, *d, s.importOptions(gameDir, false, false))
+		if err != nil {
+			return err
+		}
+		issues = result.Issues
+		source = filepath.Base(*sf.file)
+	} else {
+		decks := g.Decks
+		if *deckID != "" {
+			d, err := findDeck(g, *deckID)
+			if err != nil {
+				return err
+			}
+			decks = []deck.Deck{*d}
+		}
+		for _, d := range decks {
+			issues = append(issues, deckIssues(d, gameDir)...)
+		}
+	}
+
+	printIssues(stderr, source, issues)
+	errs, warnings := 0, 0
+	for _, issue := range issues {
+		if issue.Severity == tabular.SeverityError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	fmt.Fprintf(stdout, "%d errors, %d warnings\n", errs, warnings)
+	if errs > 0 || (*strict && warnings > 0) {
+		return errInvalid
+	}
+	return nil
+}
+
+// deckIssues checks a saved deck: field definitions and values, that styles the
+// cards use exist and that image files are present. Row is the card's position
+// in the deck.
+func deckIssues(d deck.Deck, gameDir string) []tabular.Issue {
+	var issues []tabular.Issue
+	report := func(row int, column, severity, format string, args ...any) {
+		issues = append(issues, tabular.Issue{
+			Row:      row,
+			Column:   column,
+			Severity: severity,
+			Message:  fmt.Sprintf("deck %s: ", d.ID) + fmt.Sprintf(format, args...),
+		})
+	}
+
+	coerced := d
+	coerced.CoerceFields()
+	if err := coerced.ValidateFields(); err != nil {
+		report(0, "", tabular.SeverityError, "%v", err)
+	}
+
+	for i, c := range d.Cards {
+		if id := c.FrontStyleID; id != "" && !hasStyle(d.FrontStyles, id) {
+			report(i+1, "", tabular.SeverityError, "card %s uses missing front style %q", c.ID, id)
+		}
+		if id := c.BackStyleID; id != "" && !hasStyle(d.BackStyles, id) {
+			report(i+1, "", tabular.SeverityError, "card %s uses missing back style %q", c.ID, id)
+		}
+		for _, f := range d.Fields {
+			value := c.Data[f.Name]
+			if deck.IsEmpty(value) {
+				if f.Required {
+					report(i+1, f.Name, tabular.SeverityError, "card %s: required field %s is empty", c.ID, f.Name)
+				}
+				continue
+			}
+			path, ok := value.(string)
+			if ok && (f.Type == deck.FieldImage || f.Type == deck.FieldIcon) && !tabular.ImageExists(path, gameDir) {
+				report(i+1, f.Name, tabular.SeverityWarning, "card %s: image file %s not found", c.ID, path)
+			}
+		}
+	}
+	return issues
+}
+
+func hasStyle(styles map[string]deck.CardLayout, id string) bool {
+	_, ok := styles[id]
+	return ok
+}
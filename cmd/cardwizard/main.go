@@ -0,0 +1,181 @@
+// Command cardwizard builds print files from Card Wizard games without the app,
+// for scripts and CI.
+//
+// Usage:
+//
+//	cardwizard build game.json [--deck ID] --out deck.pdf [--calibration]
+//	cardwizard export-xlsx game.json [--deck ID] --out cards.xlsx [--thumbnails]
+//	cardwizard import game.json --deck ID --file cards.xlsx [--sheet NAME] [--map key=Header]... [--sync] [--remove-missing] [--strict] [--out game.json]
+//	cardwizard validate game.json [--deck ID] [--file cards.xlsx [--sheet NAME] [--map key=Header]...]
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+	"card_wizard/internal/project"
+)
+
+const usage = `Usage: cardwizard <command> game.json [flags]
+
+Commands:
+  build        Generate a print PDF for a deck
+  export-xlsx  Export decks to an Excel workbook
+  import       Import or sync a deck's cards from a spreadsheet
+  validate     Check a game, or a spreadsheet against a deck
+
+Run "cardwizard <command> -h" for the flags of a command.
+`
+
+// errUsage marks errors in the command line rather than in the files
+var errUsage = errors.New("usage")
+
+// errInvalid is returned when validation or a strict import finds errors
+var errInvalid = errors.New("validation failed")
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	case errors.Is(err, errInvalid):
+		os.Exit(1)
+	default:
+		fmt.Fprintln(os.Stderr, "cardwizard:", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command in args, writing reports to stdout and problems to stderr
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return errUsage
+	}
+
+	commands := map[string]func(args []string, stdout, stderr io.Writer) error{
+		"build":       runBuild,
+		"export-xlsx": runExportXLSX,
+		"import":      runImport,
+		"validate":    runValidate,
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			fmt.Fprint(stdout, usage)
+			return nil
+		}
+		fmt.Fprintf(stderr, "cardwizard: unknown command %q\n\n%s", args[0], usage)
+		return errUsage
+	}
+	return cmd(args[1:], stdout, stderr)
+}
+
+// newFlagSet returns a flag set for a command that reports errors to stderr
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: cardwizard %s %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseGameArgs parses flags, which may come before or after the game file, and
+// returns the game file
+func parseGameArgs(fs *flag.FlagSet, args []string) (string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return "", err
+			}
+			return "", errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return "", errUsage
+	}
+	return positional[0], nil
+}
+
+// mappingFlag collects repeated --map key=Header flags into a column mapping
+type mappingFlag map[string]string
+
+func (m mappingFlag) String() string {
+	var pairs []string
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (m mappingFlag) Set(value string) error {
+	key, header, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("want key=Header, got %q", value)
+	}
+	m[key] = header
+	return nil
+}
+
+// loadGame reads a game file, upgrading files saved by older versions, and
+// returns it with a project service that has it open
+func loadGame(path string) (*project.Service, *game.Game, error) {
+	svc := project.NewService()
+	g, _, err := svc.LoadGameFrom(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return svc, g, nil
+}
+
+// findDeck returns the deck with the given ID or name. With no ID, a game with
+// a single deck returns it.
+func findDeck(g *game.Game, id string) (*deck.Deck, error) {
+	if id == "" {
+		if len(g.Decks) == 1 {
+			return &g.Decks[0], nil
+		}
+		return nil, fmt.Errorf("game has %d decks; choose one with --deck (%s)", len(g.Decks), deckIDs(g))
+	}
+	for i := range g.Decks {
+		if g.Decks[i].ID == id {
+			return &g.Decks[i], nil
+		}
+	}
+	for i := range g.Decks {
+		if strings.EqualFold(g.Decks[i].Name, id) {
+			return &g.Decks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no deck %q in game; decks are %s", id, deckIDs(g))
+}
+
+func deckIDs(g *game.Game) string {
+	ids := make([]string, len(g.Decks))
+	for i, d := range g.Decks {
+		ids[i] = d.ID
+	}
+	return strings.Join(ids, ", ")
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+	"card_wizard/internal/tabular"
+)
+
+// exampleGame copies the example game into a temporary directory
+func exampleGame(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, os.DirFS("../../example_deck")); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "example_game.json")
+}
+
+func runCommand(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	err := run(args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), err
+}
+
+func TestBuild(t *testing.T) {
+	gamePath := exampleGame(t)
+	out := filepath.Join(filepath.Dir(gamePath), "weapons.pdf")
+
+	if _, _, err := runCommand(t, "build", gamePath, "--out", out); err == nil || !strings.Contains(err.Error(), "choose one with --deck") {
+		t.Errorf("build without --deck error = %v, want a request for --deck", err)
+	}
+
+	if _, _, err := runCommand(t, "build", gamePath, "--deck", "weapon-deck", "--out", out); err != nil {
+		t.Fatalf("build error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || !bytes.HasPrefix(data, []byte("%PDF")) {
+		t.Errorf("build wrote %d bytes (%v), want a PDF", len(data), err)
+	}
+}
+
+func TestBuildWithCustomFont(t *testing.T) {
+	gamePath := exampleGame(t)
+	dir := filepath.Dir(gamePath)
+	out := filepath.Join(dir, "weapons.pdf")
+
+	// Print every front style's text in a custom font
+	svc, g, err := loadGame(gamePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &g.Decks[0]
+	d.CustomFonts = []deck.CustomFont{{Name: "Title", Path: "fonts/title.ttf", Family: "Title Font"}}
+	for _, layout := range d.FrontStyles {
+		for i := range layout.Elements {
+			if layout.Elements[i].Type == "text" {
+				layout.Elements[i].FontFamily = "'Title Font', sans-serif"
+			}
+		}
+	}
+	if err := svc.SaveGameTo(*g, gamePath); err != nil {
+		t.Fatal(err)
+	}
+	font := filepath.Join(dir, "fonts", "title.ttf")
+	if err := os.Mkdir(filepath.Dir(font), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"missing", nil, "failed to load font Title Font"},
+		{"unparseable", []byte("not a font"), "failed to parse font Title Font"},
+		{"valid", goregular.TTF, ""},
+	}
+	for _, tt := range tests {
+		if tt.data != nil {
+			if err := os.WriteFile(font, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, _, err := runCommand(t, "build", gamePath, "--deck", "weapon-deck", "--out", out)
+		if tt.want == "" && err != nil {
+			t.Errorf("build with a %s font error = %v", tt.name, err)
+		}
+		if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("build with a %s font error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestExportAndImport(t *testing.T) {
+	gamePath := exampleGame(t)
+	dir := filepath.Dir(gamePath)
+	workbook := filepath.Join(dir, "cards.xlsx")
+
+	if _, _, err := runCommand(t, "export-xlsx", gamePath, "--out", workbook); err != nil {
+		t.Fatalf("export-xlsx error = %v", err)
+	}
+
+	// An exported workbook syncs back without changes or a mapping
+	stdout, _, err := runCommand(t, "import", gamePath, "--deck", "weapon-deck", "--file", workbook, "--sync")
+	if err != nil {
+		t.Fatalf("import --sync error = %v", err)
+	}
+	if !strings.Contains(stdout, "0 added, 0 changed, 0 removed") {
+		t.Errorf("import --sync = %q, want no changes", stdout)
+	}
+
+	// A CSV replaces the deck's cards, adding its new columns as fields
+	csv := filepath.Join(dir, "cards.csv")
+	if err := os.WriteFile(csv, []byte("Name,Power\nAxe,3\nBow,2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "imported.json")
+	if _, _, err := runCommand(t, "import", gamePath, "--deck", "Weapon Arsenal Deck", "--file", csv,
+		"--map", "generateIdFrom=Name", "--out", out); err != nil {
+		t.Fatalf("import error = %v", err)
+	}
+	_, g, err := loadGame(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := findDeck(g, "weapon-deck")
+	if len(d.Cards) != 2 || d.Cards[0].ID != "axe" || d.Cards[0].Data["Power"] != "3" {
+		t.Errorf("imported cards = %+v, want axe and bow", d.Cards)
+	}
+	if d.Fields[len(d.Fields)-1].Name != "Power" {
+		t.Errorf("imported fields = %+v, want Power added", d.Fields)
+	}
+	if g.FormatVersion != game.FormatVersion {
+		t.Errorf("imported game format = %d, want %d", g.FormatVersion, game.FormatVersion)
+	}
+}
+
+func TestImportToAnotherFolder(t *testing.T) {
+	gamePath := exampleGame(t)
+	dir := filepath.Dir(gamePath)
+	workbook := filepath.Join(dir, "cards.xlsx")
+	if _, _, err := runCommand(t, "export-xlsx", gamePath, "--out", workbook); err != nil {
+		t.Fatalf("export-xlsx error = %v", err)
+	}
+
+	// Image paths kept from the game point at the same files from the new folder
+	out := filepath.Join(dir, "copies", "synced.json")
+	if err := os.Mkdir(filepath.Dir(out), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := runCommand(t, "import", gamePath, "--deck", "weapon-deck", "--file", workbook, "--sync", "--out", out); err != nil {
+		t.Fatalf("import --sync --out error = %v", err)
+	}
+	_, g, err := loadGame(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := findDeck(g, "weapon-deck")
+	image, _ := d.Cards[0].Data["image"].(string)
+	if image != "../images/bronze_weapon_card_back.png" || !tabular.ImageExists(image, filepath.Dir(out)) {
+		t.Errorf("synced card image = %q, want a path to the game's image from %s", image, filepath.Dir(out))
+	}
+}
+
+func TestImportSyncReportsIssues(t *testing.T) {
+	gamePath := exampleGame(t)
+	csv := filepath.Join(filepath.Dir(gamePath), "counts.csv")
+	if err := os.WriteFile(csv, []byte("ID,Count\nrusty-dagger,two\nwooden-club,lots\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, err := runCommand(t, "import", gamePath, "--deck", "weapon-deck", "--file", csv,
+		"--map", "id=ID", "--map", "count=Count", "--sync")
+	if err != nil {
+		t.Fatalf("import --sync error = %v", err)
+	}
+	if strings.Count(stderr, "is not a whole number") != 2 {
+		t.Errorf("import --sync output = %q, want each bad count reported once", stderr)
+	}
+
+	_, _, err = runCommand(t, "import", gamePath, "--deck", "weapon-deck", "--file", csv,
+		"--map", "id=ID", "--map", "count=Count", "--sync", "--strict")
+	if !errors.Is(err, errInvalid) {
+		t.Errorf("import --sync --strict error = %v, want errInvalid", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	gamePath := exampleGame(t)
+	if stdout, stderr, err := runCommand(t, "validate", gamePath); err != nil {
+		t.Fatalf("validate error = %v\n%s%s", err, stdout, stderr)
+	}
+
+	// Break a card's style and image
+	svc, g, err := loadGame(gamePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Decks[0].Cards[0].FrontStyleID = "gone"
+	g.Decks[0].Cards[1].Data["image"] = "images/missing.png"
+	if err := svc.SaveGameTo(*g, gamePath); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, err := runCommand(t, "validate", gamePath)
+	if !errors.Is(err, errInvalid) {
+		t.Errorf("validate error = %v, want errInvalid", err)
+	}
+	for _, want := range []string{`missing front style "gone"`, "image file images/missing.png not found"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("validate output = %q, want it to mention %q", stderr, want)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	if _, _, err := runCommand(t); !errors.Is(err, errUsage) {
+		t.Errorf("no command error = %v, want errUsage", err)
+	}
+	if _, _, err := runCommand(t, "bake"); !errors.Is(err, errUsage) {
+		t.Errorf("unknown command error = %v, want errUsage", err)
+	}
+	if _, _, err := runCommand(t, "build", "a.json", "b.json", "--out", "x.pdf"); !errors.Is(err, errUsage) {
+		t.Errorf("two game files error = %v, want errUsage", err)
+	}
+}
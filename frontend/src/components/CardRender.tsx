@@ -12,6 +12,13 @@ interface CardRenderProps {
 
 const MM_TO_PX = 3.7795275591;
 
+// formatValue shows typed card values (numbers, booleans) as text
+const formatValue = (value: any) => {
+  if (value === null || value === undefined) return '';
+  if (typeof value === 'boolean') return value ? 'Yes' : 'No';
+  return String(value);
+};
+
 export function CardRender({ card, deck, mode, scale = 1, border = true, className }: CardRenderProps) {
   const styles = mode === 'front' ? deck.frontStyles : deck.backStyles;
   // Determine effective style ID
@@ -107,7 +114,7 @@ export function CardRender({ card, deck, mode, scale = 1, border = true, classNa
                 />
             </svg>
           ) : (
-            el.field ? card.data[el.field] : el.staticText
+            el.field ? formatValue(card.data[el.field]) : el.staticText
           )}
         </div>
       ))}
@@ -1,11 +1,12 @@
-import { Container, Title, TextInput, NumberInput, Group, Button, Stack, Paper, Text, Select, Tabs, ActionIcon, Modal, Anchor, Menu, Switch } from '@mantine/core';
-import { Deck } from '../types';
-import { ExportXLSX, SelectFontFile, SelectExcelFile, GetExcelHeaders, ImportCardsWithMapping } from '../../wailsjs/go/main/App';
-import { main } from '../../wailsjs/go/models';
+import { Container, Title, TextInput, NumberInput, Group, Button, Stack, Paper, Text, Select, Tabs, ActionIcon, Modal, Anchor, Menu, Switch, Table, ScrollArea } from '@mantine/core';
+import { Deck, Imposition, FieldDefinition } from '../types';
+import { ExportXLSX, ExportODS, SelectFontFile, SelectExcelFile, GetExcelHeaders, ImportCardsWithMapping, GetImportMapping, SyncCardsWithMapping, ApplyCardSync, WatchSpreadsheet, SetWatchedDeck, StopWatchingSpreadsheet, GetWatchedSpreadsheet, GetPaperSizes, GenerateCalibrationPDF } from '../../wailsjs/go/main/App';
+import { main, pdf, tabular } from '../../wailsjs/go/models';
 import { notifications } from '@mantine/notifications';
+import { EventsOn } from '../../wailsjs/runtime/runtime';
 import { SpreadsheetView } from './SpreadsheetView';
 import { IconTable, IconSettings, IconPlus, IconTrash, IconHelp, IconEye, IconDatabase } from '@tabler/icons-react';
-import { useState } from 'react';
+import { useEffect, useState } from 'react';
 
 interface DeckDetailsProps {
   deck: Deck;
@@ -36,13 +37,78 @@ export function DeckDetails({ deck, setDeck, onDeckLoad, onNavigateToHelp, onDel
 
   const [mappingModalOpen, setMappingModalOpen] = useState(false);
   const [excelHeaders, setExcelHeaders] = useState<string[]>([]);
-  const [columnMapping, setColumnMapping] = useState({
+  const [columnMapping, setColumnMapping] = useState<Record<string, string>>({
+      id: '',
       generateIdFrom: '',
       count: '',
       frontStyle: '',
-      backStyle: ''
+      backStyle: '',
+      key: ''
   });
+  const [syncDiff, setSyncDiff] = useState<tabular.SyncDiff | null>(null);
+  const [removeMissing, setRemoveMissing] = useState(false);
+  const [watchFile, setWatchFile] = useState(false);
+  const [strictImport, setStrictImport] = useState(false);
+  const [importIssues, setImportIssues] = useState<tabular.Issue[] | null>(null);
+  const [watched, setWatched] = useState<main.SpreadsheetUpdate | null>(null);
   const [importTarget, setImportTarget] = useState<{path: string, sheet: string} | null>(null);
+  const [paperSizes, setPaperSizes] = useState<pdf.PaperSize[]>([]);
+
+  useEffect(() => {
+    GetPaperSizes().then(setPaperSizes).catch(err => console.error('Failed to load paper sizes:', err));
+  }, []);
+
+  useEffect(() => {
+    GetWatchedSpreadsheet().then(setWatched).catch(() => setWatched(null));
+  }, [deck.id]);
+
+  // Reloads of a watched spreadsheet are synced against the deck as it is now
+  useEffect(() => {
+    if (watched && watched.deckId === deck.id) {
+      SetWatchedDeck(deck as any).catch(err => console.error('Failed to update watched deck:', err));
+    }
+  }, [deck, watched]);
+
+  // Live refresh: the backend syncs a watched spreadsheet each time it is saved
+  useEffect(() => {
+    const offChanged = EventsOn('spreadsheet:changed', async (update: main.SpreadsheetUpdate) => {
+      if (update.deckId !== deck.id || !update.diff) return;
+      const diff = update.diff;
+      try {
+          const cards = await ApplyCardSync(deck.cards as any, diff, update.removeMissing);
+          const removed = update.removeMissing ? diff.removed?.length || 0 : 0;
+          const issues = update.issues?.length ? ` (${update.issues.length} issues)` : '';
+          mergeImportedCards(cards, `Reloaded ${fileName(update.filePath)}: added ${diff.added?.length || 0}, updated ${diff.changed?.length || 0}, removed ${removed} cards${issues}`, (update.fields || []) as FieldDefinition[]);
+      } catch (err) {
+          notifications.show({ title: 'Spreadsheet Reload Failed', message: String(err), color: 'red' });
+      }
+    });
+    const offError = EventsOn('spreadsheet:error', (update: main.SpreadsheetUpdate) => {
+      if (update.deckId !== deck.id) return;
+      notifications.show({ title: 'Spreadsheet Reload Failed', message: update.error, color: 'red' });
+    });
+    return () => {
+      offChanged();
+      offError();
+    };
+  });
+
+  const fileName = (path: string) => path.split(/[\\/]/).pop() || path;
+
+  const startWatching = async (target = importTarget, mapping = columnMapping) => {
+      if (!watchFile || !target) return;
+      try {
+          await WatchSpreadsheet(deck as any, target.path, target.sheet, mapping, removeMissing);
+          setWatched(await GetWatchedSpreadsheet());
+      } catch (err) {
+          notifications.show({ title: 'Could not watch spreadsheet', message: String(err), color: 'red' });
+      }
+  };
+
+  const stopWatching = async () => {
+      await StopWatchingSpreadsheet();
+      setWatched(null);
+  };
 
 
   const handleImportClick = async () => {
@@ -74,10 +140,24 @@ export function DeckDetails({ deck, setDeck, onDeckLoad, onNavigateToHelp, onDel
       try {
           const headers = await GetExcelHeaders(path, sheet);
           setExcelHeaders(headers);
-          setImportTarget({ path, sheet });
+          const target = { path, sheet };
+          setImportTarget(target);
+
+          // Workbooks exported by Card Wizard carry their own mapping
+          const exported = await GetImportMapping(path, sheet);
+          if (exported) {
+              const mapping = { id: '', generateIdFrom: '', count: '', frontStyle: '', backStyle: '', key: '', ...exported };
+              setColumnMapping(mapping);
+              if (deck.cards.length > 0) {
+                  await performSync(target, mapping);
+              } else {
+                  await performImport(target, mapping);
+              }
+              return;
+          }
 
           // Auto-guess mapping
-          const mapping = { generateIdFrom: '', count: '', frontStyle: '', backStyle: '' };
+          const mapping = { id: '', generateIdFrom: '', count: '', frontStyle: '', backStyle: '', key: '' };
           const lowerHeaders = headers.map(h => h.toLowerCase());
 
           const findHeader = (keywords: string[]) => {
@@ -99,6 +179,11 @@ export function DeckDetails({ deck, setDeck, onDeckLoad, onNavigateToHelp, onDel
 
           mapping.generateIdFrom = findHeader(['name', 'card name', 'title', 'id', 'identifier']); // Added 'id' keywords here as fallback since we removed specific ID column logic
 
+          // Match existing cards on an ID column (e.g. from an export) or the ID source field
+          const exactHeader = (name: string) => headers.find(h => h.toLowerCase() === name) || '';
+          mapping.id = exactHeader('id');
+          mapping.key = exactHeader('id') || (deck.fields.some(f => f.name === mapping.generateIdFrom) ? mapping.generateIdFrom : '');
+
           setColumnMapping(mapping);
           setMappingModalOpen(true);
 
@@ -107,90 +192,141 @@ export function DeckDetails({ deck, setDeck, onDeckLoad, onNavigateToHelp, onDel
       }
   };
 
-  const performImport = async () => {
-      if (!importTarget) return;
+  const performImport = async (target = importTarget, mapping = columnMapping) => {
+      if (!target) return;
       setMappingModalOpen(false);
 
       try {
-          const cards = await ImportCardsWithMapping(importTarget.path, importTarget.sheet, columnMapping);
-          if (cards) {
-             // Style Reconciliation
-             let newFrontStyles = { ...deck.frontStyles };
-             let newBackStyles = { ...deck.backStyles };
-             let stylesChanged = false;
-
-             const resolveStyle = (styleNameOrId: string, type: 'front' | 'back') => {
-                if (!styleNameOrId) return type === 'front' ? 'default-front' : 'default-back';
-
-                const styles = type === 'front' ? newFrontStyles : newBackStyles;
-
-                // 1. Check if it matches an existing ID
-                if (styles[styleNameOrId]) return styleNameOrId;
-
-                // 2. Check if it matches an existing Name (case insensitive?)
-                const foundEntry = Object.entries(styles).find(([_, s]) => s.name.toLowerCase() === styleNameOrId.toLowerCase());
-                if (foundEntry) return foundEntry[0];
-
-                // 3. Create New Style
-                const newId = `${type}-style-${Date.now()}-${Math.random().toString(36).substr(2, 5)}`;
-                const newStyle = { name: styleNameOrId, elements: [] };
-
-                if (type === 'front') {
-                    newFrontStyles = { ...newFrontStyles, [newId]: newStyle };
-                } else {
-                    newBackStyles = { ...newBackStyles, [newId]: newStyle };
-                }
-                stylesChanged = true;
-                return newId;
-             };
-
-             const reconciledCards = cards.map(c => ({
-                 ...c,
-                 frontStyleId: resolveStyle(c.frontStyleId, 'front'),
-                 backStyleId: resolveStyle(c.backStyleId, 'back')
-             }));
-
-             // Infer Fields
-             let fields = deck.fields;
-             if (reconciledCards.length > 0) {
-                 const allKeys = new Set<string>();
-                 deck.fields.forEach(f => allKeys.add(f.name));
-
-                 reconciledCards.forEach(c => Object.keys(c.data).forEach(k => allKeys.add(k)));
-
-                 // Update fields list if new fields found
-                 if (allKeys.size > deck.fields.length) {
-                     fields = Array.from(allKeys).map(k => {
-                         const existing = deck.fields.find(f => f.name === k);
-                         return existing || { name: k, type: 'text' };
-                     });
-                 }
-             }
-
-             setDeck({
-                 ...deck,
-                 frontStyles: stylesChanged ? newFrontStyles : deck.frontStyles,
-                 backStyles: stylesChanged ? newBackStyles : deck.backStyles,
-                 cards: reconciledCards as any,
-                 fields
-             });
-
-             notifications.show({ title: 'Success', message: `Imported ${reconciledCards.length} cards` });
+          const result = await ImportCardsWithMapping(target.path, target.sheet, mapping, deck as any, strictImport);
+          if (result.issues?.length > 0) {
+             setImportIssues(result.issues);
+          }
+          if (!result.cards) {
+             notifications.show({ title: 'Import Stopped', message: 'Fix the errors in the sheet or turn off strict validation', color: 'red' });
+             return;
           }
+          mergeImportedCards(result.cards, `Imported ${result.cards.length} cards`, result.fields as FieldDefinition[]);
+          await startWatching(target, mapping);
       } catch (err) {
           notifications.show({ title: 'Import Failed', message: String(err), color: 'red' });
       }
   };
 
+  const performSync = async (target = importTarget, mapping = columnMapping) => {
+      if (!target) return;
+      setMappingModalOpen(false);
+
+      try {
+          const diff = await SyncCardsWithMapping(target.path, target.sheet, mapping, deck as any);
+          if (diff.issues?.length > 0) {
+             setImportIssues(diff.issues);
+          }
+          setRemoveMissing(false);
+          setSyncDiff(diff);
+      } catch (err) {
+          notifications.show({ title: 'Sync Failed', message: String(err), color: 'red' });
+      }
+  };
+
+  const applySync = async () => {
+      if (!syncDiff) return;
+      const diff = syncDiff;
+      setSyncDiff(null);
+
+      try {
+          const cards = await ApplyCardSync(deck.cards as any, diff, removeMissing);
+          const removed = removeMissing ? diff.removed?.length || 0 : 0;
+          mergeImportedCards(cards, `Added ${diff.added?.length || 0}, updated ${diff.changed?.length || 0}, removed ${removed} cards`);
+          await startWatching();
+      } catch (err) {
+          notifications.show({ title: 'Sync Failed', message: String(err), color: 'red' });
+      }
+  };
+
+  // mergeImportedCards replaces the deck's cards, creating styles and fields the cards refer to
+  const mergeImportedCards = (cards: any[], message: string, declaredFields: FieldDefinition[] = []) => {
+      // Style Reconciliation
+      let newFrontStyles = { ...deck.frontStyles };
+      let newBackStyles = { ...deck.backStyles };
+      let stylesChanged = false;
+
+      const resolveStyle = (styleNameOrId: string, type: 'front' | 'back') => {
+         if (!styleNameOrId) return type === 'front' ? 'default-front' : 'default-back';
+
+         const styles = type === 'front' ? newFrontStyles : newBackStyles;
+
+         // 1. Check if it matches an existing ID
+         if (styles[styleNameOrId]) return styleNameOrId;
+
+         // 2. Check if it matches an existing Name (case insensitive?)
+         const foundEntry = Object.entries(styles).find(([_, s]) => s.name.toLowerCase() === styleNameOrId.toLowerCase());
+         if (foundEntry) return foundEntry[0];
+
+         // 3. Create New Style
+         const newId = `${type}-style-${Date.now()}-${Math.random().toString(36).substr(2, 5)}`;
+         const newStyle = { name: styleNameOrId, elements: [] };
+
+         if (type === 'front') {
+             newFrontStyles = { ...newFrontStyles, [newId]: newStyle };
+         } else {
+             newBackStyles = { ...newBackStyles, [newId]: newStyle };
+         }
+         stylesChanged = true;
+         return newId;
+      };
+
+      const reconciledCards = cards.map(c => ({
+          ...c,
+          frontStyleId: resolveStyle(c.frontStyleId, 'front'),
+          backStyleId: resolveStyle(c.backStyleId, 'back')
+      }));
+
+      // Infer Fields
+      let fields = deck.fields;
+      if (reconciledCards.length > 0) {
+          const allKeys = new Set<string>();
+          deck.fields.forEach(f => allKeys.add(f.name));
+
+          reconciledCards.forEach(c => Object.keys(c.data).forEach(k => allKeys.add(k)));
+
+          // Update fields list if new fields found
+          if (allKeys.size > deck.fields.length) {
+              fields = Array.from(allKeys).map(k => {
+                  const existing = deck.fields.find(f => f.name === k) || declaredFields.find(f => f.name === k);
+                  return existing || { name: k, type: 'text' };
+              });
+          }
+      }
+
+      setDeck({
+          ...deck,
+          frontStyles: stylesChanged ? newFrontStyles : deck.frontStyles,
+          backStyles: stylesChanged ? newBackStyles : deck.backStyles,
+          cards: reconciledCards as any,
+          fields
+      });
+
+      notifications.show({ title: 'Success', message });
+  };
+
   const handleExport = async () => {
     try {
-      await ExportXLSX(deck.cards as any, deck.fields);
+      await ExportXLSX(deck as any);
       notifications.show({ title: 'Success', message: 'Deck exported to Excel' });
     } catch (err) {
       notifications.show({ title: 'Error', message: String(err), color: 'red' });
     }
   };
 
+  const handleExportODS = async () => {
+    try {
+      await ExportODS(deck as any);
+      notifications.show({ title: 'Success', message: 'Deck exported to ODS' });
+    } catch (err) {
+      notifications.show({ title: 'Error', message: String(err), color: 'red' });
+    }
+  };
+
 
 
   const handlePresetChange = (value: string | null) => {
@@ -203,6 +339,21 @@ export function DeckDetails({ deck, setDeck, onDeckLoad, onNavigateToHelp, onDel
 
   const mmToInches = (mm: number) => (mm / 25.4).toFixed(2);
 
+  // Blank inputs clear the override so the automatic layout applies
+  const optionalNumber = (val: string | number) => (val === '' ? undefined : Number(val));
+
+  const setImposition = (changes: Partial<Imposition>) => {
+    setDeck({ ...deck, imposition: { ...deck.imposition, ...changes } });
+  };
+
+  const handlePrintCalibration = async () => {
+    try {
+      await GenerateCalibrationPDF(deck as any);
+    } catch (err) {
+      notifications.show({ title: 'Error', message: `Failed to generate calibration sheet: ${err}`, color: 'red' });
+    }
+  };
+
   const handleAddFont = async () => {
     try {
       const path = await SelectFontFile();
@@ -283,9 +434,17 @@ export function DeckDetails({ deck, setDeck, onDeckLoad, onNavigateToHelp, onDel
                         <Button variant="outline" leftSection={<IconDatabase size={16} />}>Data</Button>
                     </Menu.Target>
                     <Menu.Dropdown>
-                        <Menu.Label>Excel</Menu.Label>
-                        <Menu.Item leftSection={<IconPlus size={14} />} onClick={handleImportClick}>Import XLSX</Menu.Item>
+                        <Menu.Label>Spreadsheet</Menu.Label>
+                        <Menu.Item leftSection={<IconPlus size={14} />} onClick={handleImportClick}>Import XLSX / ODS / CSV</Menu.Item>
                         <Menu.Item leftSection={<IconTable size={14} />} onClick={handleExport}>Export XLSX</Menu.Item>
+                        <Menu.Item leftSection={<IconTable size={14} />} onClick={handleExportODS}>Export ODS</Menu.Item>
+                        {watched && watched.deckId === deck.id && (
+                            <>
+                                <Menu.Divider />
+                                <Menu.Label>Watching {fileName(watched.filePath)}</Menu.Label>
+                                <Menu.Item leftSection={<IconEye size={14} />} onClick={stopWatching}>Stop Watching</Menu.Item>
+                            </>
+                        )}
                     </Menu.Dropdown>
                  </Menu>
              </Group>
@@ -336,17 +495,118 @@ export function DeckDetails({ deck, setDeck, onDeckLoad, onNavigateToHelp, onDel
                     />
                   </Group>
 
+                  <Group grow align="flex-end">
+                    <NumberInput
+                      label="Bleed (mm)"
+                      description="Extra area around each card that is trimmed off"
+                      min={0}
+                      step={0.5}
+                      value={deck.bleed || 0}
+                      onChange={(val) => setDeck({ ...deck, bleed: Number(val) })}
+                    />
+                    <Switch
+                      label="Keep bleed in PDF (for print shops)"
+                      checked={!!deck.keepBleed}
+                      onChange={(e) => setDeck({ ...deck, keepBleed: e.currentTarget.checked })}
+                    />
+                  </Group>
+
                   <Select
                     label="Paper Size"
                     description="Paper size for PDF generation"
                     value={deck.paperSize || 'letter'}
-                    onChange={(val) => setDeck({ ...deck, paperSize: (val as 'letter' | 'a4') || 'letter' })}
+                    onChange={(val) => setDeck({ ...deck, paperSize: val || 'letter' })}
+                    data={[
+                      ...paperSizes.map(size => ({ value: size.id, label: size.name })),
+                      { value: 'custom', label: 'Custom' },
+                    ]}
+                  />
+
+                  {deck.paperSize === 'custom' && (
+                    <Group grow>
+                      <NumberInput
+                        label="Paper Width (mm)"
+                        min={1}
+                        value={deck.paperWidth || 0}
+                        onChange={(val) => setDeck({ ...deck, paperWidth: Number(val) })}
+                        description={`${mmToInches(deck.paperWidth || 0)}"`}
+                      />
+                      <NumberInput
+                        label="Paper Height (mm)"
+                        min={1}
+                        value={deck.paperHeight || 0}
+                        onChange={(val) => setDeck({ ...deck, paperHeight: Number(val) })}
+                        description={`${mmToInches(deck.paperHeight || 0)}"`}
+                      />
+                    </Group>
+                  )}
+
+                  <Select
+                    label="Orientation"
+                    description="Auto picks whichever orientation fits more cards"
+                    value={deck.orientation || 'auto'}
+                    onChange={(val) => setDeck({ ...deck, orientation: (val as 'auto' | 'portrait' | 'landscape') || 'auto' })}
                     data={[
-                      { value: 'letter', label: 'Letter (8.5" × 11")' },
-                      { value: 'a4', label: 'A4 (210mm × 297mm)' },
+                      { value: 'auto', label: 'Auto' },
+                      { value: 'portrait', label: 'Portrait' },
+                      { value: 'landscape', label: 'Landscape' },
                     ]}
                   />
 
+                  <Paper withBorder p="sm">
+                    <Text fw={500}>Imposition</Text>
+                    <Text size="xs" c="dimmed" mb="xs">Leave blank to let the layout pick automatically</Text>
+                    <Stack gap="xs">
+                      <Group grow>
+                        <NumberInput label="Top Margin (mm)" min={0} step={0.5} value={deck.imposition?.marginTop ?? ''} onChange={(val) => setImposition({ marginTop: optionalNumber(val) })} />
+                        <NumberInput label="Bottom Margin (mm)" min={0} step={0.5} value={deck.imposition?.marginBottom ?? ''} onChange={(val) => setImposition({ marginBottom: optionalNumber(val) })} />
+                        <NumberInput label="Left Margin (mm)" min={0} step={0.5} value={deck.imposition?.marginLeft ?? ''} onChange={(val) => setImposition({ marginLeft: optionalNumber(val) })} />
+                        <NumberInput label="Right Margin (mm)" min={0} step={0.5} value={deck.imposition?.marginRight ?? ''} onChange={(val) => setImposition({ marginRight: optionalNumber(val) })} />
+                      </Group>
+                      <Group grow>
+                        <NumberInput label="Gutter (mm)" description="Space between cards" min={0} step={0.5} value={deck.imposition?.gutter ?? ''} onChange={(val) => setImposition({ gutter: optionalNumber(val) })} />
+                        <NumberInput label="Columns" description="0 for auto" min={0} value={deck.imposition?.cols || 0} onChange={(val) => setImposition({ cols: Number(val) || 0 })} />
+                        <NumberInput label="Rows" description="0 for auto" min={0} value={deck.imposition?.rows || 0} onChange={(val) => setImposition({ rows: Number(val) || 0 })} />
+                        <NumberInput label="Printer Margin (mm)" description="Non-printable edge" min={0} step={0.5} value={deck.imposition?.printerMargin || 0} onChange={(val) => setImposition({ printerMargin: Number(val) || 0 })} />
+                      </Group>
+                      <Group grow>
+                        <Select
+                          label="Horizontal Alignment"
+                          value={deck.imposition?.horizontalAlign || 'center'}
+                          onChange={(val) => setImposition({ horizontalAlign: (val as Imposition['horizontalAlign']) || 'center' })}
+                          data={[
+                            { value: 'left', label: 'Left' },
+                            { value: 'center', label: 'Center' },
+                            { value: 'right', label: 'Right' },
+                          ]}
+                        />
+                        <Select
+                          label="Vertical Alignment"
+                          value={deck.imposition?.verticalAlign || 'middle'}
+                          onChange={(val) => setImposition({ verticalAlign: (val as Imposition['verticalAlign']) || 'middle' })}
+                          data={[
+                            { value: 'top', label: 'Top' },
+                            { value: 'middle', label: 'Middle' },
+                            { value: 'bottom', label: 'Bottom' },
+                          ]}
+                        />
+                      </Group>
+                    </Stack>
+                  </Paper>
+
+                  <Paper withBorder p="sm">
+                    <Group justify="space-between">
+                      <Text fw={500}>Duplex Calibration</Text>
+                      <Button size="xs" variant="light" onClick={handlePrintCalibration}>Print Calibration Sheet</Button>
+                    </Group>
+                    <Text size="xs" c="dimmed" mb="xs">Shift and rotate back pages to cancel your printer's drift. Print the calibration sheet double-sided to measure it.</Text>
+                    <Group grow>
+                      <NumberInput label="Back X Offset (mm)" step={0.1} decimalScale={2} value={deck.backOffsetX || 0} onChange={(val) => setDeck({ ...deck, backOffsetX: Number(val) || 0 })} />
+                      <NumberInput label="Back Y Offset (mm)" step={0.1} decimalScale={2} value={deck.backOffsetY || 0} onChange={(val) => setDeck({ ...deck, backOffsetY: Number(val) || 0 })} />
+                      <NumberInput label="Back Rotation (°)" step={0.05} decimalScale={3} value={deck.backRotation || 0} onChange={(val) => setDeck({ ...deck, backRotation: Number(val) || 0 })} />
+                    </Group>
+                  </Paper>
+
                   <Text size="sm" c="dimmed">
                     Current Card Count: {deck.cards.length}
                   </Text>
@@ -431,9 +691,18 @@ export function DeckDetails({ deck, setDeck, onDeckLoad, onNavigateToHelp, onDel
 
       <Modal opened={mappingModalOpen} onClose={() => setMappingModalOpen(false)} title="Map Columns" size="lg">
           <Stack>
-              <Text size="sm" c="dimmed">Map your Excel columns to the required Card Wizard fields.</Text>
+              <Text size="sm" c="dimmed">Map your spreadsheet columns to the required Card Wizard fields.</Text>
 
               <Group grow>
+                <Select
+                    label="Card ID Column"
+                    placeholder="Select column holding card IDs"
+                    data={excelHeaders}
+                    value={columnMapping.id}
+                    onChange={(val) => setColumnMapping(prev => ({ ...prev, id: val || '' }))}
+                    searchable
+                    clearable
+                />
                 <Select
                     label="Auto-generate ID from"
                     placeholder="Select column to slugify"
@@ -475,17 +744,141 @@ export function DeckDetails({ deck, setDeck, onDeckLoad, onNavigateToHelp, onDel
                 />
               </Group>
 
+              <Select
+                  label="Match Existing Cards By"
+                  description="Used by Sync: rows are matched to cards by this column (a field, or a column of card IDs). Leave empty to match by the generated ID."
+                  placeholder="Generated ID"
+                  data={excelHeaders}
+                  value={columnMapping.key}
+                  onChange={(val) => setColumnMapping(prev => ({ ...prev, key: val || '' }))}
+                  searchable
+                  clearable
+              />
+
+              <Switch
+                  label="Strict validation: import nothing if any row has an error"
+                  checked={strictImport}
+                  onChange={(e) => setStrictImport(e.currentTarget.checked)}
+              />
+
+              <Switch
+                  label="Watch the file and reload cards when it is saved"
+                  checked={watchFile}
+                  onChange={(e) => setWatchFile(e.currentTarget.checked)}
+              />
+
               <Text size="xs" c="dimmed" mt="sm">
                   * Unmapped columns will be imported as data fields automatically.
               </Text>
 
               <Group justify="flex-end" mt="md">
                   <Button variant="default" onClick={() => setMappingModalOpen(false)}>Cancel</Button>
-                  <Button onClick={performImport}>Import Cards</Button>
+                  {deck.cards.length > 0 && (
+                      <Button variant="light" onClick={() => performSync()}>Sync with Deck</Button>
+                  )}
+                  <Button onClick={() => performImport()}>Import Cards</Button>
               </Group>
           </Stack>
       </Modal>
 
+      <Modal opened={importIssues !== null} onClose={() => setImportIssues(null)} title="Import Report" size="xl">
+          {importIssues && (
+              <Stack>
+                  <Text size="sm">
+                      {importIssues.filter(i => i.severity === 'error').length} errors, {importIssues.filter(i => i.severity === 'warning').length} warnings.
+                  </Text>
+                  <ScrollArea.Autosize mah={400}>
+                      <Table striped withTableBorder>
+                          <Table.Thead>
+                              <Table.Tr>
+                                  <Table.Th>Row</Table.Th>
+                                  <Table.Th>Column</Table.Th>
+                                  <Table.Th>Severity</Table.Th>
+                                  <Table.Th>Message</Table.Th>
+                              </Table.Tr>
+                          </Table.Thead>
+                          <Table.Tbody>
+                              {importIssues.map((issue, idx) => (
+                                  <Table.Tr key={idx}>
+                                      <Table.Td>{issue.row}</Table.Td>
+                                      <Table.Td>{issue.column}</Table.Td>
+                                      <Table.Td>
+                                          <Text size="sm" c={issue.severity === 'error' ? 'red' : 'orange'}>{issue.severity}</Text>
+                                      </Table.Td>
+                                      <Table.Td>{issue.message}</Table.Td>
+                                  </Table.Tr>
+                              ))}
+                          </Table.Tbody>
+                      </Table>
+                  </ScrollArea.Autosize>
+                  <Group justify="flex-end">
+                      <Button onClick={() => setImportIssues(null)}>Close</Button>
+                  </Group>
+              </Stack>
+          )}
+      </Modal>
+
+      <Modal opened={syncDiff !== null} onClose={() => setSyncDiff(null)} title="Review Sync" size="xl">
+          {syncDiff && (
+              <Stack>
+                  <Text size="sm">
+                      {syncDiff.added?.length || 0} new, {syncDiff.changed?.length || 0} changed, {syncDiff.removed?.length || 0} missing from the sheet, {syncDiff.unchanged} unchanged.
+                  </Text>
+                  {syncDiff.skipped?.length > 0 && (
+                      <Text size="sm" c="orange">Skipped rows with an empty key: {syncDiff.skipped.join(', ')}</Text>
+                  )}
+
+                  <ScrollArea.Autosize mah={360}>
+                      <Table striped withTableBorder>
+                          <Table.Thead>
+                              <Table.Tr>
+                                  <Table.Th>Card</Table.Th>
+                                  <Table.Th>Field</Table.Th>
+                                  <Table.Th>Old</Table.Th>
+                                  <Table.Th>New</Table.Th>
+                              </Table.Tr>
+                          </Table.Thead>
+                          <Table.Tbody>
+                              {syncDiff.added?.map(c => (
+                                  <Table.Tr key={`added-${c.id}`}>
+                                      <Table.Td>{c.id}</Table.Td>
+                                      <Table.Td colSpan={3}><Text size="sm" c="green">New card</Text></Table.Td>
+                                  </Table.Tr>
+                              ))}
+                              {syncDiff.changed?.flatMap(change => change.changes.map(fc => (
+                                  <Table.Tr key={`changed-${change.card.id}-${fc.field}`}>
+                                      <Table.Td>{change.card.id} (row {change.row})</Table.Td>
+                                      <Table.Td>{fc.field}</Table.Td>
+                                      <Table.Td><Text size="sm" c="dimmed">{fc.old}</Text></Table.Td>
+                                      <Table.Td>{fc.new}</Table.Td>
+                                  </Table.Tr>
+                              )))}
+                              {syncDiff.removed?.map(c => (
+                                  <Table.Tr key={`removed-${c.id}`}>
+                                      <Table.Td>{c.id}</Table.Td>
+                                      <Table.Td colSpan={3}><Text size="sm" c="red">Not in the sheet</Text></Table.Td>
+                                  </Table.Tr>
+                              ))}
+                          </Table.Tbody>
+                      </Table>
+                  </ScrollArea.Autosize>
+
+                  {syncDiff.removed?.length > 0 && (
+                      <Switch
+                          label={`Remove the ${syncDiff.removed.length} cards missing from the sheet`}
+                          checked={removeMissing}
+                          onChange={(e) => setRemoveMissing(e.currentTarget.checked)}
+                      />
+                  )}
+
+                  <Group justify="flex-end">
+                      <Button variant="default" onClick={() => setSyncDiff(null)}>Cancel</Button>
+                      <Button onClick={applySync}>Apply Changes</Button>
+                  </Group>
+              </Stack>
+          )}
+      </Modal>
+
     </Container>
   );
 }
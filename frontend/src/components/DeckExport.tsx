@@ -12,7 +12,7 @@ interface DeckExportProps {
 export function DeckExport({ deck }: DeckExportProps) {
   const handleExportXLSX = async () => {
     try {
-      await ExportXLSX(deck.cards as any, deck.fields);
+      await ExportXLSX(deck as any);
       notifications.show({ title: 'Success', message: 'Deck exported to Excel' });
     } catch (err) {
       notifications.show({ title: 'Error', message: String(err), color: 'red' });
@@ -1,7 +1,7 @@
 import { AppShell, Burger, Group, NavLink, Text, Button, TextInput, ActionIcon, Menu, Tabs, Drawer } from '@mantine/core';
 import { useDisclosure } from '@mantine/hooks';
-import { useState, useEffect } from 'react';
-import { IconPlus, IconDeviceFloppy, IconFolderOpen, IconTrash, IconCards, IconHelp, IconLayoutSidebarLeftCollapse, IconLayoutSidebarLeftExpand, IconChartBar, IconChevronDown, IconFileTypePdf, IconPhoto, IconTable, IconFilePlus } from '@tabler/icons-react';
+import { useState, useEffect, useRef } from 'react';
+import { IconPlus, IconDeviceFloppy, IconFolderOpen, IconTrash, IconCards, IconHelp, IconLayoutSidebarLeftCollapse, IconLayoutSidebarLeftExpand, IconChartBar, IconChevronDown, IconFileTypePdf, IconPhoto, IconTable, IconFilePlus, IconPackage, IconPackageExport, IconPackageImport, IconHistory } from '@tabler/icons-react';
 import { Game, Deck, DEFAULT_DECK } from '../types';
 import { DeckDetails } from './DeckDetails';
 import { StyleEditor } from './StyleEditor';
@@ -11,7 +11,9 @@ import { Help } from './Help';
 import { AssetGallery } from './AssetGallery';
 import { DeckExport } from './DeckExport';
 import { KeyStatsModal } from './KeyStatsModal';
-import { SaveGame, LoadGame, NewGame, SaveImages, ExportGameXLSX } from '../../wailsjs/go/main/App';
+import { SnapshotHistory } from './SnapshotHistory';
+import { SaveGame, SaveGameAs, LoadGame, NewGame, SaveImages, ExportGameXLSX, ExportGameODS, SaveBundle, OpenBundle, ExtractBundle, GetRecentProjects, OpenRecentProject, ClearRecentProjects, UpdateAutosave, CheckRecovery } from '../../wailsjs/go/main/App';
+import { project } from '../../wailsjs/go/models';
 import { notifications } from '@mantine/notifications';
 import { CardRender } from './CardRender';
 
@@ -224,9 +226,9 @@ export function GameView() {
         }
     };
 
-    const handleExportAllDecksXLSX = async () => {
+    const handleExportAllDecksXLSX = async (thumbnails = false) => {
         try {
-            await ExportGameXLSX(game as any);
+            await ExportGameXLSX(game as any, thumbnails);
             notifications.show({
                 title: 'Success',
                 message: 'Game exported to Excel with multiple sheets',
@@ -241,6 +243,23 @@ export function GameView() {
         }
     };
 
+    const handleExportAllDecksODS = async () => {
+        try {
+            await ExportGameODS(game as any);
+            notifications.show({
+                title: 'Success',
+                message: 'Game exported to ODS with multiple sheets',
+                color: 'green'
+            });
+        } catch (err) {
+            notifications.show({
+                title: 'Error',
+                message: String(err),
+                color: 'red'
+            });
+        }
+    };
+
     const handleExportAllDecksPDF = async () => {
          // Placeholder for Excel export integration if needed here
          notifications.show({ title: 'Info', message: 'Excel export available in Spreadsheet view.', color: 'blue' });
@@ -266,6 +285,7 @@ export function GameView() {
                  decks: [{ ...DEFAULT_DECK, id: newDeckId }]
              };
              setGame(newGame);
+             persistedGame.current = JSON.stringify(newGame);
              setActiveDeckId(newGame.decks[0].id);
              setActiveTab('details');
 
@@ -279,10 +299,14 @@ export function GameView() {
     const [sidebarCollapsed, { toggle: toggleSidebar }] = useDisclosure(false);
     const [helpOpened, { open: openHelp, close: closeHelp }] = useDisclosure(false);
     const [statsOpened, { open: openStats, close: closeStats }] = useDisclosure(false);
+    const [historyOpened, { open: openHistory, close: closeHistory }] = useDisclosure(false);
     const [game, setGame] = useState<Game>({
         name: 'New Game',
         decks: [{ ...DEFAULT_DECK, id: `deck-${Date.now()}` }]
     });
+    // The game as last loaded or saved; other versions are handed to the autosave
+    const persistedGame = useRef(JSON.stringify(game));
+    const checkedRecovery = useRef(false);
     const [activeDeckId, setActiveDeckId] = useState<string>(game.decks[0].id);
     const [activeTab, setActiveTab] = useState<string | null>('details');
     const [helpSection, setHelpSection] = useState<string | undefined>();
@@ -314,6 +338,30 @@ export function GameView() {
         styleEl.textContent = css;
       }, [activeDeck.customFonts]);
 
+    useEffect(() => {
+        // Hand unsaved changes to the backend, which writes them to a recovery file
+        const timer = setTimeout(() => {
+            if (JSON.stringify(game) !== persistedGame.current) {
+                UpdateAutosave(game as any).catch(() => {});
+            }
+        }, 2000);
+        return () => clearTimeout(timer);
+    }, [game]);
+
+    useEffect(() => {
+        // Offer to restore changes that were autosaved but never saved
+        if (checkedRecovery.current) return;
+        checkedRecovery.current = true;
+        CheckRecovery().then(recovered => {
+            if (recovered) {
+                openLoadedGame(recovered, true);
+                notifications.show({ title: 'Changes Restored', message: 'Save the game to keep the restored changes.' });
+            }
+        }).catch(err => {
+            notifications.show({ title: 'Error', message: String(err), color: 'red' });
+        });
+    }, []);
+
     const handleAddDeck = () => {
         const newDeck = { ...DEFAULT_DECK, id: `deck-${Date.now()}`, name: `New Deck ${game.decks.length + 1}` };
         setGame({ ...game, decks: [...game.decks, newDeck] });
@@ -337,26 +385,68 @@ export function GameView() {
         setGame({ ...game, decks: newDecks });
     };
 
-    const handleSaveGame = async () => {
+    const handleSaveGame = async (saveAs = false) => {
         try {
-            await SaveGame(game as any);
-            notifications.show({ title: 'Success', message: 'Game saved' });
+            const path = saveAs ? await SaveGameAs(game as any) : await SaveGame(game as any);
+            if (path) {
+                persistedGame.current = JSON.stringify(game);
+                notifications.show({ title: 'Success', message: `Game saved to ${path}` });
+            }
         } catch (err) {
             notifications.show({ title: 'Error', message: String(err), color: 'red' });
         }
     };
 
+    const [recentProjects, setRecentProjects] = useState<project.RecentProject[]>([]);
+
+    const loadRecentProjects = async () => {
+        try {
+            setRecentProjects((await GetRecentProjects()) || []);
+        } catch (err) {
+            setRecentProjects([]);
+        }
+    };
+
+    const handleOpenRecent = async (path: string) => {
+        try {
+            const loadedGame = await OpenRecentProject(path);
+            if (loadedGame) {
+                openLoadedGame(loadedGame);
+                notifications.show({ title: 'Success', message: 'Game loaded' });
+            }
+        } catch (err) {
+            notifications.show({ title: 'Error', message: String(err), color: 'red' });
+        }
+    };
+
+    const handleClearRecent = async () => {
+        try {
+            await ClearRecentProjects();
+            setRecentProjects([]);
+        } catch (err) {
+            notifications.show({ title: 'Error', message: String(err), color: 'red' });
+        }
+    };
+
+    const openLoadedGame = (loadedGame: any, unsaved = false) => {
+        // Ensure IDs exist (migration)
+        const decks = (loadedGame.decks || []).map((d: any, i: number) => ({
+            ...d,
+            id: d.id || `deck-${Date.now()}-${i}`
+        }));
+        const opened = { ...loadedGame, decks } as Game;
+        setGame(opened);
+        if (!unsaved) {
+            persistedGame.current = JSON.stringify(opened);
+        }
+        setActiveDeckId(decks[0].id);
+    };
+
     const handleLoadGame = async () => {
         try {
             const loadedGame = await LoadGame();
             if (loadedGame) {
-                // Ensure IDs exist (migration)
-                const decks = (loadedGame.decks || []).map((d: any, i: number) => ({
-                    ...d,
-                    id: d.id || `deck-${Date.now()}-${i}`
-                }));
-                setGame({ ...loadedGame, decks } as Game);
-                setActiveDeckId(decks[0].id);
+                openLoadedGame(loadedGame);
                 notifications.show({ title: 'Success', message: 'Game loaded' });
             }
         } catch (err) {
@@ -364,6 +454,39 @@ export function GameView() {
         }
     };
 
+    const handleSaveBundle = async () => {
+        try {
+            const report = await SaveBundle(game as any);
+            if (!report) return; // Cancelled
+            const problems: string[] = [];
+            if (report.missing?.length) {
+                problems.push(`Not found, left out: ${report.missing.join(', ')}`);
+            }
+            if (report.extra?.length) {
+                problems.push(`Unused images, left out: ${report.extra.join(', ')}`);
+            }
+            if (problems.length) {
+                notifications.show({ title: 'Bundle saved with warnings', message: problems.join('. '), color: 'yellow', autoClose: false });
+            } else {
+                notifications.show({ title: 'Success', message: 'Bundle saved' });
+            }
+        } catch (err) {
+            notifications.show({ title: 'Error', message: String(err), color: 'red' });
+        }
+    };
+
+    const handleOpenBundle = async (extract: boolean) => {
+        try {
+            const loadedGame = extract ? await ExtractBundle() : await OpenBundle();
+            if (loadedGame) {
+                openLoadedGame(loadedGame);
+                notifications.show({ title: 'Success', message: extract ? 'Bundle extracted' : 'Bundle opened' });
+            }
+        } catch (err) {
+            notifications.show({ title: 'Error', message: String(err), color: 'red' });
+        }
+    };
+
     return (
         <AppShell
             header={{ height: 60 }}
@@ -392,8 +515,52 @@ export function GameView() {
                     </Group>
                     <Group>
                         <Button variant="default" leftSection={<IconFilePlus size={16} />} onClick={handleNewGame}>New Game</Button>
-                        <Button variant="default" leftSection={<IconFolderOpen size={16} />} onClick={handleLoadGame}>Load Game</Button>
-                        <Menu shadow="md" width={200}>
+                        <Button.Group>
+                            <Button variant="default" leftSection={<IconFolderOpen size={16} />} onClick={handleLoadGame}>Load Game</Button>
+                            <Menu shadow="md" width={320} position="bottom-end" onOpen={loadRecentProjects}>
+                                <Menu.Target>
+                                    <Button variant="default" px={6} title="Recent Projects">
+                                        <IconChevronDown size={14} />
+                                    </Button>
+                                </Menu.Target>
+                                <Menu.Dropdown>
+                                    <Menu.Label>Recent Projects</Menu.Label>
+                                    {recentProjects.length === 0 && <Menu.Item disabled>No recent projects</Menu.Item>}
+                                    {recentProjects.map(p => (
+                                        <Menu.Item key={p.path} leftSection={<IconHistory size={14} />} onClick={() => handleOpenRecent(p.path)} title={p.path}>
+                                            <Text size="sm" truncate>{p.name || p.path.split(/[\\/]/).pop()}</Text>
+                                            <Text size="xs" c="dimmed" truncate>{p.path}</Text>
+                                        </Menu.Item>
+                                    ))}
+                                    {recentProjects.length > 0 && (
+                                        <>
+                                            <Menu.Divider />
+                                            <Menu.Item color="red" leftSection={<IconTrash size={14} />} onClick={handleClearRecent}>
+                                                Clear Recent Projects
+                                            </Menu.Item>
+                                        </>
+                                    )}
+                                </Menu.Dropdown>
+                            </Menu>
+                        </Button.Group>
+                        <Menu shadow="md" width={240}>
+                            <Menu.Target>
+                                <Button variant="default" leftSection={<IconPackage size={16} />} rightSection={<IconChevronDown size={14} />}>Bundle</Button>
+                            </Menu.Target>
+                            <Menu.Dropdown>
+                                <Menu.Label>Single-File Project (.cwz)</Menu.Label>
+                                <Menu.Item leftSection={<IconPackageImport size={14} />} onClick={() => handleOpenBundle(false)}>
+                                    Open Bundle
+                                </Menu.Item>
+                                <Menu.Item leftSection={<IconPackageExport size={14} />} onClick={handleSaveBundle}>
+                                    Save as Bundle
+                                </Menu.Item>
+                                <Menu.Item leftSection={<IconFolderOpen size={14} />} onClick={() => handleOpenBundle(true)}>
+                                    Extract Bundle to Folder
+                                </Menu.Item>
+                            </Menu.Dropdown>
+                        </Menu>
+                        <Menu shadow="md" width={240}>
                             <Menu.Target>
                                 <Button variant="light" rightSection={<IconChevronDown size={14} />}>Export Game</Button>
                             </Menu.Target>
@@ -408,12 +575,35 @@ export function GameView() {
                                 <Menu.Item leftSection={<IconTable size={14} />} onClick={() => handleExportAllDecksXLSX()}>
                                     Export to Excel
                                 </Menu.Item>
+                                <Menu.Item leftSection={<IconTable size={14} />} onClick={() => handleExportAllDecksXLSX(true)}>
+                                    Export to Excel with Thumbnails
+                                </Menu.Item>
+                                <Menu.Item leftSection={<IconTable size={14} />} onClick={() => handleExportAllDecksODS()}>
+                                    Export to ODS
+                                </Menu.Item>
                             </Menu.Dropdown>
                         </Menu>
-                        <Button leftSection={<IconDeviceFloppy size={16} />} onClick={handleSaveGame}>Save Game</Button>
+                        <Button.Group>
+                            <Button leftSection={<IconDeviceFloppy size={16} />} onClick={() => handleSaveGame()}>Save Game</Button>
+                            <Menu shadow="md" width={200} position="bottom-end">
+                                <Menu.Target>
+                                    <Button px={6} title="More Save Options">
+                                        <IconChevronDown size={14} />
+                                    </Button>
+                                </Menu.Target>
+                                <Menu.Dropdown>
+                                    <Menu.Item leftSection={<IconDeviceFloppy size={14} />} onClick={() => handleSaveGame(true)}>
+                                        Save As...
+                                    </Menu.Item>
+                                </Menu.Dropdown>
+                            </Menu>
+                        </Button.Group>
                         <ActionIcon variant="subtle" size="lg" onClick={openStats} title="Game Statistics">
                             <IconChartBar size={24} />
                         </ActionIcon>
+                        <ActionIcon variant="subtle" size="lg" onClick={openHistory} title="Project History">
+                            <IconHistory size={24} />
+                        </ActionIcon>
                         <ActionIcon variant="subtle" size="lg" onClick={openHelp} title="Help">
                             <IconHelp size={24} />
                         </ActionIcon>
@@ -522,6 +712,16 @@ export function GameView() {
                 opened={statsOpened}
                 onClose={closeStats}
             />
+
+            <SnapshotHistory
+                game={game}
+                opened={historyOpened}
+                onClose={closeHistory}
+                onRestore={(restored) => {
+                    openLoadedGame(restored, true);
+                    notifications.show({ title: 'Snapshot Restored', message: 'Save the game to keep the restored version.' });
+                }}
+            />
         </AppShell>
     );
 }
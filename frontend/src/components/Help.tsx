@@ -46,10 +46,28 @@ export function Help({ section }: HelpProps) {
               </Text>
               <List>
                 <List.Item>
-                  <strong>Import XLSX:</strong> Import card data from an Excel spreadsheet. The first row should contain column headers, and each subsequent row represents a card.
+                  <strong>Import XLSX / ODS / CSV:</strong> Import card data from an Excel or OpenDocument (LibreOffice) spreadsheet, or a CSV/TSV file. The first row should contain column headers, and each subsequent row represents a card. The delimiter and text encoding of CSV files are detected automatically.
                 </List.Item>
                 <List.Item>
-                  <strong>Export XLSX:</strong> Export your current deck to an Excel file for editing or backup.
+                  <strong>Field types:</strong> Besides Text and Image, columns can be Rich Text (multi-line), Number, Whole Number, Yes / No, Choice (one of a list of options) or Icon. Imported cells are converted to the column's type (e.g. "yes", "TRUE" or "1" for Yes / No), values that don't fit are listed in the import report, and saving is refused until they are fixed. Spreadsheet exports write numbers and Yes / No values as real number and boolean cells.
+                </List.Item>
+                <List.Item>
+                  <strong>Import report:</strong> Imports are checked for counts that are not whole numbers or are negative, duplicate card IDs, unknown styles, missing image files and empty required fields (mark a field required with the <strong>*</strong> button in its column header). Problems are listed by row and column. With <strong>Strict validation</strong> on, nothing is imported if any row has an error; otherwise cards are imported with a fallback (a count of 1, or a numbered ID) and the problems are listed for you to fix.
+                </List.Item>
+                <List.Item>
+                  <strong>Sync with Deck:</strong> After mapping columns, choose <strong>Sync with Deck</strong> instead of Import to update the existing cards from an edited sheet. Rows are matched to cards by the <strong>Match Existing Cards By</strong> column (or the generated ID), and you can review the new, changed and missing cards before applying. Card IDs, style assignments and fields the sheet has no column for are kept.
+                </List.Item>
+                <List.Item>
+                  <strong>Watch the file:</strong> Turn on <strong>Watch the file and reload cards when it is saved</strong> when mapping columns to keep the deck in step with the spreadsheet. Each time you save it in Excel or LibreOffice the cards are synced with the same mapping: they keep the styles, counts and data the sheet has no columns for, and cards missing from the sheet are only removed if you chose to remove them when syncing. Use <strong>Stop Watching</strong> in the Data menu to end it.
+                </List.Item>
+                <List.Item>
+                  <strong>Export XLSX:</strong> Export your current deck to an Excel file for editing or backup. Styles are written by name, and a hidden sheet records the deck's fields, their types and its styles, so importing the file again needs no column mapping: into an empty deck the cards are imported directly, and into a deck with cards you review the changes as a sync.
+                </List.Item>
+                <List.Item>
+                  <strong>Export ODS:</strong> Export your current deck to an OpenDocument spreadsheet for LibreOffice or other editors.
+                </List.Item>
+                <List.Item>
+                  <strong>Embedded Images:</strong> Pictures pasted into the cells of an Excel sheet are copied into the game's <code>images</code> folder when you import it, and the cell gets the image's path. Columns that are not fields yet become image fields. Save the game first so it has an images folder. <strong>Export Game → Export to Excel with Thumbnails</strong> embeds a small copy of each card image next to its path.
                 </List.Item>
                 <List.Item>
                   Exporting your current deck to XLSX will <strong>only export the non-default columns. JSON export will export all data.</strong>
@@ -61,10 +79,22 @@ export function Help({ section }: HelpProps) {
               <Title order={4} size="h5">Save/Load Game</Title>
               <List>
                 <List.Item>
-                  <strong>Save Game:</strong> Save your entire game (all decks, settings, styles, and fonts) to a JSON file.
+                  <strong>Save Game:</strong> Save your entire game (all decks, settings, styles, and fonts) to a JSON file. Once the game has a file, Save Game writes straight to it; use the arrow next to it and <strong>Save As...</strong> to save a copy elsewhere. Files are replaced in one step, so a crash while saving never leaves a half-written project.
+                </List.Item>
+                <List.Item>
+                  <strong>Load Game:</strong> Load a previously saved game file. The arrow next to it lists your recent projects, including bundles.
                 </List.Item>
                 <List.Item>
-                  <strong>Load Game:</strong> Load a previously saved game file.
+                  <strong>Autosave:</strong> Changes you have not saved are written to a recovery file every 30 seconds: a hidden file ending in <code>.autosave</code> next to the game file, or in Card Wizard's cache folder for a game that was never saved. If Card Wizard closes before you save, it offers to restore those changes the next time it starts or when you open that game. Saving deletes the recovery file.
+                </List.Item>
+                <List.Item>
+                  <strong>Project History:</strong> Each save also keeps a snapshot of the game in a hidden <code>.snapshots</code> folder next to the game file or bundle; the last 50 are kept. The history button in the header compares two snapshots, or a snapshot with the game you are editing, deck by deck: cards added, removed or with changed values, and styles whose elements changed or moved. <strong>Restore</strong> opens the chosen snapshot; save the game to keep it.
+                </List.Item>
+                <List.Item>
+                  <strong>Bundle → Save as Bundle:</strong> Save the game as a single <code>.cwz</code> file holding its images and fonts, ready to share. You are told about images or fonts that could not be found, and about files in the <code>images</code> folder no card uses, which are left out.
+                </List.Item>
+                <List.Item>
+                  <strong>Bundle → Open Bundle:</strong> Open a <code>.cwz</code> file. It is unpacked to a working folder, and <strong>Save Game</strong> packs your changes back into the bundle. Use <strong>Extract Bundle to Folder</strong> instead to turn it back into a regular game folder.
                 </List.Item>
               </List>
             </div>
@@ -129,6 +159,12 @@ export function Help({ section }: HelpProps) {
                 <List.Item>
                   <strong>Paper Size:</strong> Choose Letter or A4 for PDF generation.
                 </List.Item>
+                <List.Item>
+                  <strong>Imposition:</strong> Override the automatic page layout with fixed margins, a gutter between cards, a set number of rows and columns, or grid alignment. Set the printer margin to keep cards out of your printer's non-printable edge.
+                </List.Item>
+                <List.Item>
+                  <strong>Duplex Calibration:</strong> If backs don't line up with fronts, print the calibration sheet double-sided, hold it up to a light and enter the values under the back crosshairs as the back X/Y offsets and rotation.
+                </List.Item>
                 <List.Item>
                   <strong>Custom Fonts:</strong> Add TTF or OTF font files to use in your card designs.
                 </List.Item>
@@ -307,7 +343,13 @@ export function Help({ section }: HelpProps) {
               <Title order={4} size="h5">Print Options</Title>
               <List>
                 <List.Item>
-                  <strong>Draw Cut Guides:</strong> Add lines showing where to cut each card.
+                  <strong>Cut Guides:</strong> Draw a dashed outline on each card, or crop marks in the page margins that extend the trim lines for cutting with a paper trimmer.
+                </List.Item>
+                <List.Item>
+                  <strong>Registration Marks:</strong> Add alignment targets in the margins to check that fronts and backs line up.
+                </List.Item>
+                <List.Item>
+                  <strong>Duplex:</strong> Long-edge and short-edge interleave front and back pages to match how your printer flips the sheet. Fronts only and backs only print a single side. All fronts, then all backs suits manual duplexing. Gutter-fold puts each front next to its back with a fold line down the middle.
                 </List.Item>
                 <List.Item>
                   <strong>Generate PDF:</strong> Create a PDF with all cards laid out on pages according to your paper size.
@@ -1,6 +1,6 @@
 import { useState, useEffect, useRef } from 'react';
-import { Paper, Title, Text, Group, Box, LoadingOverlay, Button, Stack, Checkbox, SegmentedControl, ActionIcon } from '@mantine/core';
-import { Deck, PDFLayout } from '../types';
+import { Paper, Title, Text, Group, Box, LoadingOverlay, Button, Stack, Checkbox, SegmentedControl, ActionIcon, Select } from '@mantine/core';
+import { Deck, DuplexMode, PDFLayout } from '../types';
 import { GetPDFLayout, GeneratePDF } from '../../wailsjs/go/main/App';
 import { notifications } from '@mantine/notifications';
 import { CardRender } from './CardRender';
@@ -13,6 +13,7 @@ interface PrintPreviewProps {
 }
 
 interface RenderedCardImage {
+  cardId: string;
   styleId: string;
   side: 'front' | 'back';
   image: string;
@@ -23,7 +24,9 @@ export function PrintPreview({ deck, onNavigateToHelp }: PrintPreviewProps) {
   const [loading, setLoading] = useState(false);
   const [generating, setGenerating] = useState(false);
   const [previewGenerated, setPreviewGenerated] = useState(false);
-  const [showCutGuides, setShowCutGuides] = useState(false);
+  const [cutGuideStyle, setCutGuideStyle] = useState<'none' | 'outline' | 'crop-marks'>(deck.cutGuideStyle || (deck.drawCutGuides ? 'outline' : 'none'));
+  const [registrationMarks, setRegistrationMarks] = useState(!!deck.registrationMarks);
+  const [duplexMode, setDuplexMode] = useState<DuplexMode>(deck.duplexMode || 'long-edge');
   const [previewMode, setPreviewMode] = useState<'front' | 'back'>('front');
   const [renderedImages, setRenderedImages] = useState<RenderedCardImage[]>([]);
   const cardRefs = useRef<Map<string, HTMLDivElement>>(new Map());
@@ -33,17 +36,18 @@ export function PrintPreview({ deck, onNavigateToHelp }: PrintPreviewProps) {
     const fetchLayout = async () => {
       setLoading(true);
       try {
-        const result = await GetPDFLayout(deck as any);
-        setLayout(result);
+        const result = await GetPDFLayout({ ...deck, duplexMode } as any);
+        setLayout(result as PDFLayout);
       } catch (error) {
         console.error("Failed to get PDF layout:", error);
+        notifications.show({ title: 'Layout Error', message: String(error), color: 'red' });
       } finally {
         setLoading(false);
       }
     };
 
     fetchLayout();
-  }, [deck]);
+  }, [deck, duplexMode]);
 
   // Reset preview when deck changes
   useEffect(() => {
@@ -56,33 +60,17 @@ export function PrintPreview({ deck, onNavigateToHelp }: PrintPreviewProps) {
     try {
       const images: RenderedCardImage[] = [];
 
-      // Collect unique styles
-      const frontStyles = new Set(deck.cards.map(c => c.frontStyleId || 'default-front'));
-      const backStyles = new Set(deck.cards.map(c => c.backStyleId || 'default-back'));
+      // Render every card so cards sharing a style still print their own data
+      for (const card of deck.cards) {
+        for (const side of ['front', 'back'] as const) {
+          const element = cardRefs.current.get(`${side}-${card.id}`);
 
-      // Render front cards
-      for (const styleId of frontStyles) {
-       const sampleCard = deck.cards.find(c => (c.frontStyleId || 'default-front') === styleId) || deck.cards[0];
-        const refKey = `front-${styleId}`;
-        const element = cardRefs.current.get(refKey);
-
-        if (element) {
-          const MM_TO_PX = 3.7795275591;
-          const image = await renderCardToImage(element, deck.width * MM_TO_PX, deck.height * MM_TO_PX);
-          images.push({ styleId, side: 'front', image });
-        }
-      }
-
-      // Render back cards
-      for (const styleId of backStyles) {
-        const sampleCard = deck.cards.find(c => (c.backStyleId || 'default-back') === styleId) || deck.cards[0];
-        const refKey = `back-${styleId}`;
-        const element = cardRefs.current.get(refKey);
-
-        if (element) {
-          const MM_TO_PX = 3.7795275591;
-          const image = await renderCardToImage(element, deck.width * MM_TO_PX, deck.height * MM_TO_PX);
-          images.push({ styleId, side: 'back', image });
+          if (element) {
+            const MM_TO_PX = 3.7795275591;
+            const styleId = side === 'front' ? (card.frontStyleId || 'default-front') : (card.backStyleId || 'default-back');
+            const image = await renderCardToImage(element, deck.width * MM_TO_PX, deck.height * MM_TO_PX);
+            images.push({ cardId: card.id, styleId, side, image });
+          }
         }
       }
 
@@ -107,7 +95,10 @@ export function PrintPreview({ deck, onNavigateToHelp }: PrintPreviewProps) {
       const deckWithImages = {
         ...deck,
         renderedCards: renderedImages,
-        drawCutGuides: showCutGuides,
+        drawCutGuides: cutGuideStyle === 'outline',
+        cutGuideStyle,
+        registrationMarks,
+        duplexMode,
       };
 
       await GeneratePDF(deckWithImages as any);
@@ -125,8 +116,20 @@ export function PrintPreview({ deck, onNavigateToHelp }: PrintPreviewProps) {
   const MM_TO_PX = 3.7795275591;
   const previewScale = 0.8;
   const totalCards = deck.cards.reduce((sum, card) => sum + (card.count || 1), 0);
-  const cardsPerPage = layout.cardsPerRow * layout.cardsPerCol;
-  const totalPages = Math.ceil(totalCards / cardsPerPage) * 2;
+  const gutterFold = layout.duplexMode === 'gutter-fold';
+  // Gutter-fold pages hold fronts in the left half and their backs in the right half
+  const cardsPerPage = gutterFold ? Math.floor(layout.cardsPerRow / 2) * layout.cardsPerCol : layout.cardsPerRow * layout.cardsPerCol;
+  const sheets = Math.ceil(totalCards / cardsPerPage);
+  const singleSided = gutterFold || duplexMode === 'fronts-only' || duplexMode === 'backs-only';
+  const totalPages = singleSided ? sheets : sheets * 2;
+  const duplexLabels: Record<DuplexMode, string> = {
+    'long-edge': 'Long-edge',
+    'short-edge': 'Short-edge',
+    'fronts-only': 'Fronts only',
+    'backs-only': 'Backs only',
+    'fronts-then-backs': 'All fronts, then all backs',
+    'gutter-fold': 'Gutter-fold',
+  };
 
   // Generate cards for the first page
   const pageCards: any[] = [];
@@ -184,29 +187,49 @@ export function PrintPreview({ deck, onNavigateToHelp }: PrintPreviewProps) {
           <Group justify="space-between" mb="md">
             <Group>
               <Title order={3}>Layout Preview</Title>
+              <Select
+                size="xs"
+                label="Cut guides"
+                value={cutGuideStyle}
+                onChange={(val) => setCutGuideStyle((val as 'none' | 'outline' | 'crop-marks') || 'none')}
+                data={[
+                  { value: 'none', label: 'None' },
+                  { value: 'outline', label: 'Outline on cards' },
+                  { value: 'crop-marks', label: 'Crop marks in margins' },
+                ]}
+                allowDeselect={false}
+              />
+              <Select
+                size="xs"
+                label="Duplex"
+                value={duplexMode}
+                onChange={(val) => setDuplexMode((val as DuplexMode) || 'long-edge')}
+                data={Object.entries(duplexLabels).map(([value, label]) => ({ value, label }))}
+                allowDeselect={false}
+              />
               <Checkbox
-                label="Show cut guides"
-                checked={showCutGuides}
-                onChange={(e) => setShowCutGuides(e.currentTarget.checked)}
+                label="Registration marks"
+                checked={registrationMarks}
+                onChange={(e) => setRegistrationMarks(e.currentTarget.checked)}
               />
             </Group>
-            <SegmentedControl
+            {!gutterFold && <SegmentedControl
               value={previewMode}
               onChange={(value) => setPreviewMode(value as 'front' | 'back')}
               data={[
                 { label: 'Front Page', value: 'front' },
                 { label: 'Back Page', value: 'back' },
               ]}
-            />
+            />}
           </Group>
 
           <Group mb="lg">
-            <Text size="sm">Paper: {deck.paperSize === 'a4' ? 'A4' : 'Letter'}</Text>
+            <Text size="sm">Paper: {layout.pageWidth.toFixed(1)}mm × {layout.pageHeight.toFixed(1)}mm ({layout.orientation})</Text>
             <Text size="sm">Cards per page: {cardsPerPage} ({layout.cardsPerRow} × {layout.cardsPerCol})</Text>
             <Text size="sm">Card Size: {deck.width}mm × {deck.height}mm</Text>
             <Text size="sm">Margins: {layout.marginLeft.toFixed(1)}mm x {layout.marginTop.toFixed(1)}mm</Text>
             <Text size="sm">Spacing: {layout.spacing}mm</Text>
-            <Text size="sm" c="blue">Duplex: Long-edge</Text>
+            <Text size="sm" c="blue">Duplex: {duplexLabels[duplexMode]}</Text>
           </Group>
 
           <Box
@@ -241,19 +264,48 @@ export function PrintPreview({ deck, onNavigateToHelp }: PrintPreviewProps) {
                 }}
               />
 
-              {pageCards.map((card, index) => {
-                const row = Math.floor(index / layout.cardsPerRow);
-                const col = index % layout.cardsPerRow;
+              {gutterFold && (
+                <div
+                  style={{
+                    position: 'absolute',
+                    left: (layout.marginLeft + (layout.cardsPerRow * layout.cardWidth + (layout.cardsPerRow - 1) * layout.spacing) / 2) * MM_TO_PX * previewScale,
+                    top: 0,
+                    bottom: 0,
+                    borderLeft: '1px dashed #adb5bd',
+                    pointerEvents: 'none',
+                  }}
+                />
+              )}
 
-                // Mirror columns for back page (Standard Duplex)
-                const displayCol = previewMode === 'back' ? (layout.cardsPerRow - 1 - col) : col;
+              {pageCards.flatMap((card, index) => {
+                const perRow = gutterFold ? Math.floor(layout.cardsPerRow / 2) : layout.cardsPerRow;
+                const row = Math.floor(index / perRow);
+                const col = index % perRow;
 
-                const x = (layout.marginLeft + displayCol * (layout.cardWidth + layout.spacing)) * MM_TO_PX * previewScale;
+                if (gutterFold) {
+                  // Each back sits opposite its front across the fold
+                  return [
+                    { card, side: 'front' as const, col, row },
+                    { card, side: 'back' as const, col: layout.cardsPerRow - 1 - col, row },
+                  ];
+                }
+
+                // Mirror columns for long-edge backs, rows for short-edge backs; a landscape sheet swaps them
+                const side = duplexMode === 'fronts-only' ? 'front' : duplexMode === 'backs-only' ? 'back' : previewMode;
+                const mirrored = side === 'back';
+                const mirrorRows = (duplexMode === 'short-edge') !== (layout.orientation === 'landscape');
+                return [{
+                  card,
+                  side,
+                  col: mirrored && !mirrorRows ? layout.cardsPerRow - 1 - col : col,
+                  row: mirrored && mirrorRows ? layout.cardsPerCol - 1 - row : row,
+                }];
+              }).map(({ card, side, col, row }, index) => {
+                const x = (layout.marginLeft + col * (layout.cardWidth + layout.spacing)) * MM_TO_PX * previewScale;
                 const y = (layout.marginTop + row * (layout.cardHeight + layout.spacing)) * MM_TO_PX * previewScale;
 
                 // Find rendered image
-                const styleId = previewMode === 'front' ? (card.frontStyleId || 'default-front') : (card.backStyleId || 'default-back');
-                const renderedImage = renderedImages.find(img => img.styleId === styleId && img.side === previewMode);
+                const renderedImage = renderedImages.find(img => img.cardId === card.id && img.side === side);
 
                 return (
                   <div
@@ -264,7 +316,7 @@ export function PrintPreview({ deck, onNavigateToHelp }: PrintPreviewProps) {
                       top: y,
                       width: layout.cardWidth * MM_TO_PX * previewScale,
                       height: layout.cardHeight * MM_TO_PX * previewScale,
-                      border: showCutGuides ? '1px dashed #999' : '1px solid #eee',
+                      border: cutGuideStyle === 'outline' ? '1px dashed #999' : '1px solid #eee',
                       backgroundColor: 'white',
                       display: 'flex',
                       alignItems: 'center',
@@ -291,44 +343,22 @@ export function PrintPreview({ deck, onNavigateToHelp }: PrintPreviewProps) {
 
       {/* Hidden card renderers for PDF generation */}
       <div style={{ position: 'absolute', left: '-99999px', top: '-99999px' }}>
-        {Array.from(new Set(deck.cards.map(c => c.frontStyleId || 'default-front'))).map(styleId => {
-          const sampleCard = deck.cards.find(c => (c.frontStyleId || 'default-front') === styleId) || deck.cards[0];
-          return (
-            <div
-              key={`front-${styleId}`}
-              ref={(el) => {
-                if (el) cardRefs.current.set(`front-${styleId}`, el);
-              }}
-            >
-              <CardRender
-                card={sampleCard}
-                deck={deck}
-                mode="front"
-                scale={1}
-                border={false}
-              />
-            </div>
-          );
-        })}
-        {Array.from(new Set(deck.cards.map(c => c.backStyleId || 'default-back'))).map(styleId => {
-          const sampleCard = deck.cards.find(c => (c.backStyleId || 'default-back') === styleId) || deck.cards[0];
-          return (
-            <div
-              key={`back-${styleId}`}
-              ref={(el) => {
-                if (el) cardRefs.current.set(`back-${styleId}`, el);
-              }}
-            >
-              <CardRender
-                card={sampleCard}
-                deck={deck}
-                mode="back"
-                scale={1}
-                border={false}
-              />
-            </div>
-          );
-        })}
+        {deck.cards.map(card => (['front', 'back'] as const).map(side => (
+          <div
+            key={`${side}-${card.id}`}
+            ref={(el) => {
+              if (el) cardRefs.current.set(`${side}-${card.id}`, el);
+            }}
+          >
+            <CardRender
+              card={card}
+              deck={deck}
+              mode={side}
+              scale={1}
+              border={false}
+            />
+          </div>
+        )))}
       </div>
     </Stack>
   );
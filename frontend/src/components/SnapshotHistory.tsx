@@ -0,0 +1,179 @@
+import { Modal, Group, Stack, Text, Paper, Select, Button, Badge, ScrollArea, Loader, Center } from '@mantine/core';
+import { useState, useEffect } from 'react';
+import { IconRestore } from '@tabler/icons-react';
+import { Game } from '../types';
+import { ListSnapshots, DiffSnapshots, DiffWithSnapshot, RestoreSnapshot } from '../../wailsjs/go/main/App';
+import { game as models, project } from '../../wailsjs/go/models';
+import { notifications } from '@mantine/notifications';
+
+const CURRENT = 'current';
+
+const changeColors: Record<string, string> = {
+    added: 'green',
+    removed: 'red',
+    changed: 'blue',
+};
+
+interface SnapshotHistoryProps {
+    game: Game;
+    opened: boolean;
+    onClose: () => void;
+    onRestore: (restored: any) => void;
+}
+
+const formatValue = (value: any) => {
+    if (value === undefined || value === null) return '(none)';
+    if (typeof value === 'object') return JSON.stringify(value);
+    return String(value);
+};
+
+const ChangeBadge = ({ change }: { change: string }) => (
+    <Badge size="xs" variant="light" color={changeColors[change] || 'gray'}>{change}</Badge>
+);
+
+const ValueChanges = ({ changes }: { changes?: models.ValueChange[] }) => (
+    <>
+        {(changes || []).map(c => (
+            <Text key={c.key} size="xs" c="dimmed" style={{ wordBreak: 'break-all' }}>
+                <strong>{c.key}:</strong> {formatValue(c.old)} → {formatValue(c.new)}
+            </Text>
+        ))}
+    </>
+);
+
+const ItemChanges = ({ label, items }: { label: string, items?: models.ItemDiff[] }) => (
+    <>
+        {(items || []).map((item, i) => (
+            <Stack key={`${item.id}-${i}`} gap={2} pl="md">
+                <Group gap="xs">
+                    <Text size="sm">{label} {item.name || item.id}</Text>
+                    <ChangeBadge change={item.change} />
+                    {item.moved && <Badge size="xs" variant="outline" color="grape">moved</Badge>}
+                </Group>
+                <Stack gap={0} pl="md">
+                    <ValueChanges changes={item.changes} />
+                </Stack>
+            </Stack>
+        ))}
+    </>
+);
+
+export function SnapshotHistory({ game, opened, onClose, onRestore }: SnapshotHistoryProps) {
+    const [snapshots, setSnapshots] = useState<project.Snapshot[]>([]);
+    const [fromId, setFromId] = useState<string | null>(null);
+    const [toId, setToId] = useState<string | null>(CURRENT);
+    const [diff, setDiff] = useState<models.Diff | null>(null);
+    const [loading, setLoading] = useState(false);
+
+    useEffect(() => {
+        if (!opened) return;
+        ListSnapshots().then(list => {
+            setSnapshots(list || []);
+            setFromId(list && list.length > 0 ? list[0].id : null);
+            setToId(CURRENT);
+        }).catch(err => {
+            setSnapshots([]);
+            setFromId(null);
+            notifications.show({ title: 'Error', message: String(err), color: 'red' });
+        });
+    }, [opened]);
+
+    useEffect(() => {
+        if (!opened || !fromId || !toId) {
+            setDiff(null);
+            return;
+        }
+        setLoading(true);
+        const compare = toId === CURRENT ? DiffWithSnapshot(fromId, game as any) : DiffSnapshots(fromId, toId);
+        compare.then(setDiff).catch(err => {
+            setDiff(null);
+            notifications.show({ title: 'Error', message: String(err), color: 'red' });
+        }).finally(() => setLoading(false));
+    }, [opened, fromId, toId]);
+
+    const handleRestore = async () => {
+        if (!fromId) return;
+        if (!window.confirm('Restore this snapshot? Changes you have not saved will be replaced.')) return;
+        try {
+            const restored = await RestoreSnapshot(fromId);
+            onRestore(restored);
+            onClose();
+        } catch (err) {
+            notifications.show({ title: 'Error', message: String(err), color: 'red' });
+        }
+    };
+
+    const options = snapshots.map(s => ({ value: s.id, label: new Date(s.savedAt).toLocaleString() }));
+    const noChanges = diff && !(diff.changes || []).length && !(diff.decks || []).length;
+
+    return (
+        <Modal opened={opened} onClose={onClose} title="Project History" size="lg">
+            <Stack gap="md">
+                {snapshots.length === 0 ? (
+                    <Text c="dimmed" size="sm">No snapshots yet. A snapshot is kept each time the game is saved.</Text>
+                ) : (
+                    <Group grow align="flex-end">
+                        <Select label="From" data={options} value={fromId} onChange={setFromId} allowDeselect={false} />
+                        <Select
+                            label="To"
+                            data={[{ value: CURRENT, label: 'Current game' }, ...options]}
+                            value={toId}
+                            onChange={setToId}
+                            allowDeselect={false}
+                        />
+                    </Group>
+                )}
+
+                {loading && <Center><Loader size="sm" /></Center>}
+                {!loading && noChanges && <Text c="dimmed" size="sm">No changes.</Text>}
+                {!loading && diff && !noChanges && (
+                    <ScrollArea.Autosize mah={400}>
+                        <Stack gap="sm">
+                            {(diff.changes || []).length > 0 && (
+                                <Paper withBorder p="sm">
+                                    <Text fw={500} size="sm">Game</Text>
+                                    <ValueChanges changes={diff.changes} />
+                                </Paper>
+                            )}
+                            {(diff.decks || []).map(d => (
+                                <Paper key={d.id} withBorder p="sm">
+                                    <Stack gap={4}>
+                                        <Group gap="xs">
+                                            <Text fw={500} size="sm">{d.name || d.id}</Text>
+                                            <ChangeBadge change={d.change} />
+                                        </Group>
+                                        <ValueChanges changes={d.changes} />
+                                        <ItemChanges label="Card" items={d.cards} />
+                                        {(d.styles || []).map(s => (
+                                            <Stack key={`${s.side}-${s.id}`} gap={2} pl="md">
+                                                <Group gap="xs">
+                                                    <Text size="sm">{s.side === 'back' ? 'Back' : 'Front'} style {s.name || s.id}</Text>
+                                                    <ChangeBadge change={s.change} />
+                                                </Group>
+                                                <Stack gap={0} pl="md">
+                                                    <ValueChanges changes={s.changes} />
+                                                </Stack>
+                                                <ItemChanges label="Element" items={s.elements} />
+                                            </Stack>
+                                        ))}
+                                    </Stack>
+                                </Paper>
+                            ))}
+                        </Stack>
+                    </ScrollArea.Autosize>
+                )}
+
+                <Group justify="flex-end">
+                    <Button
+                        leftSection={<IconRestore size={16} />}
+                        variant="light"
+                        disabled={!fromId}
+                        onClick={handleRestore}
+                    >
+                        Restore "From" Snapshot
+                    </Button>
+                </Group>
+            </Stack>
+        </Modal>
+    );
+}
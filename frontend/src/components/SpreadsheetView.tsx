@@ -1,6 +1,6 @@
-import { Table, TextInput, Button, Group, ActionIcon, Select, FileInput, Image, Stack, Text, Modal, NumberInput } from '@mantine/core';
-import { IconTrash, IconPlus, IconPhoto, IconFolder } from '@tabler/icons-react';
-import { Deck, Card, FieldDefinition } from '../types';
+import { Table, TextInput, Button, Group, ActionIcon, Select, FileInput, Image, Stack, Text, Modal, NumberInput, Switch, Checkbox, Textarea, TagsInput } from '@mantine/core';
+import { IconTrash, IconPlus, IconPhoto, IconFolder, IconAsterisk } from '@tabler/icons-react';
+import { Deck, Card, FieldDefinition, FieldType } from '../types';
 import { useState, useRef, useEffect } from 'react';
 import { notifications } from '@mantine/notifications';
 import { SelectImageFile, AddProjectImage } from '../../wailsjs/go/main/App';
@@ -20,6 +20,8 @@ interface ColumnWidths {
 export function SpreadsheetView({ deck, setDeck, compact = false, showRawValues = false }: SpreadsheetViewProps) {
   const [newFieldName, setNewFieldName] = useState('');
   const [newFieldType, setNewFieldType] = useState<string | null>('text');
+  const [newFieldRequired, setNewFieldRequired] = useState(false);
+  const [newFieldOptions, setNewFieldOptions] = useState<string[]>([]);
   const [isAddingField, setIsAddingField] = useState(false);
   const [columnWidths, setColumnWidths] = useState<ColumnWidths>({
     id: 100,
@@ -69,17 +71,32 @@ export function SpreadsheetView({ deck, setDeck, compact = false, showRawValues
 
     const newField: FieldDefinition = {
       name: newFieldName,
-      type: (newFieldType as 'text' | 'image') || 'text',
+      type: (newFieldType as FieldType) || 'text',
+      options: newFieldType === 'enum' ? newFieldOptions : undefined,
+      required: newFieldRequired || undefined,
     };
+    if (newField.type === 'enum' && newFieldOptions.length === 0) {
+      notifications.show({ title: 'Error', message: 'Add at least one option for a choice field', color: 'red' });
+      return;
+    }
 
     setDeck({
       ...deck,
       fields: [...deck.fields, newField],
     });
     setNewFieldName('');
+    setNewFieldRequired(false);
+    setNewFieldOptions([]);
     setIsAddingField(false);
   };
 
+  const toggleRequired = (fieldName: string) => {
+    setDeck({
+      ...deck,
+      fields: deck.fields.map(f => f.name === fieldName ? { ...f, required: !f.required || undefined } : f),
+    });
+  };
+
   const removeField = (fieldName: string) => {
     if (confirm(`Are you sure you want to delete the field "${fieldName}"? Data will be lost.`)) {
       const updatedCards = deck.cards.map(card => {
@@ -302,10 +319,21 @@ export function SpreadsheetView({ deck, setDeck, compact = false, showRawValues
                 return (
                   <Table.Th key={field.name} style={{ width: columnWidths[fieldKey] || 150, position: 'relative' }}>
                     <Group justify="space-between" wrap="nowrap" style={{ position: 'relative' }}>
-                      <Text size="sm" fw={500}>{field.name}</Text>
-                      <ActionIcon size="xs" color="red" variant="subtle" onClick={() => removeField(field.name)}>
-                        <IconTrash size={12} />
-                      </ActionIcon>
+                      <Text size="sm" fw={500} title={`Type: ${field.type}`}>{field.name}</Text>
+                      <Group gap={2} wrap="nowrap">
+                        <ActionIcon
+                          size="xs"
+                          variant={field.required ? 'light' : 'subtle'}
+                          color={field.required ? 'blue' : 'gray'}
+                          onClick={() => toggleRequired(field.name)}
+                          title={field.required ? 'Required: imports report empty cells' : 'Optional: click to make required'}
+                        >
+                          <IconAsterisk size={12} />
+                        </ActionIcon>
+                        <ActionIcon size="xs" color="red" variant="subtle" onClick={() => removeField(field.name)}>
+                          <IconTrash size={12} />
+                        </ActionIcon>
+                      </Group>
                       <div
                         onMouseDown={(e) => handleResizeStart(fieldKey, e)}
                         style={{
@@ -409,15 +437,7 @@ export function SpreadsheetView({ deck, setDeck, compact = false, showRawValues
                 </Table.Td>
                 {deck.fields.map(field => (
                   <Table.Td key={`${index}-${field.name}`}>
-                    {field.type === 'text' ? (
-                      <TextInput
-                        value={card.data[field.name] || ''}
-                        onChange={(e) => updateCardData(index, field.name, e.currentTarget.value)}
-                        variant="unstyled"
-                        size={inputSize}
-                        styles={{ input: { paddingLeft: compact ? 4 : undefined, paddingRight: compact ? 4 : undefined } }}
-                      />
-                    ) : (
+                    {field.type === 'image' || field.type === 'icon' ? (
                       <Group wrap="nowrap" gap="xs">
                          {card.data[field.name] && (
                             <ImageLoader
@@ -426,12 +446,12 @@ export function SpreadsheetView({ deck, setDeck, compact = false, showRawValues
                                     width: compact ? 24 : 30,
                                     height: compact ? 24 : 30,
                                     borderRadius: 4,
-                                    objectFit: 'cover'
+                                    objectFit: field.type === 'icon' ? 'contain' : 'cover'
                                 }}
                             />
                          )}
                          <TextInput
-                            placeholder="Image URL/Path"
+                            placeholder={field.type === 'icon' ? 'Icon Path' : 'Image URL/Path'}
                             value={card.data[field.name] || ''}
                             onChange={(e) => updateCardData(index, field.name, e.currentTarget.value)}
                             variant="unstyled"
@@ -443,6 +463,51 @@ export function SpreadsheetView({ deck, setDeck, compact = false, showRawValues
                             <IconFolder size={compact ? 12 : 14} />
                          </ActionIcon>
                       </Group>
+                    ) : field.type === 'number' || field.type === 'integer' ? (
+                      <NumberInput
+                        value={card.data[field.name] ?? ''}
+                        onChange={(val) => updateCardData(index, field.name, val === '' ? '' : Number(val))}
+                        allowDecimal={field.type === 'number'}
+                        variant="unstyled"
+                        size={inputSize}
+                        hideControls
+                        styles={{ input: { paddingLeft: compact ? 4 : undefined, paddingRight: compact ? 4 : undefined } }}
+                      />
+                    ) : field.type === 'boolean' ? (
+                      <Checkbox
+                        checked={card.data[field.name] === true}
+                        onChange={(e) => updateCardData(index, field.name, e.currentTarget.checked)}
+                        size={compact ? 'xs' : 'sm'}
+                      />
+                    ) : field.type === 'enum' ? (
+                      <Select
+                        data={field.options || []}
+                        value={card.data[field.name] || null}
+                        onChange={(val) => updateCardData(index, field.name, val || '')}
+                        variant="unstyled"
+                        size={inputSize}
+                        clearable
+                        styles={{ input: { paddingLeft: compact ? 4 : undefined, paddingRight: compact ? 4 : undefined } }}
+                      />
+                    ) : field.type === 'richtext' ? (
+                      <Textarea
+                        value={card.data[field.name] || ''}
+                        onChange={(e) => updateCardData(index, field.name, e.currentTarget.value)}
+                        variant="unstyled"
+                        size={inputSize}
+                        autosize
+                        minRows={1}
+                        maxRows={6}
+                        styles={{ input: { paddingLeft: compact ? 4 : undefined, paddingRight: compact ? 4 : undefined } }}
+                      />
+                    ) : (
+                      <TextInput
+                        value={card.data[field.name] ?? ''}
+                        onChange={(e) => updateCardData(index, field.name, e.currentTarget.value)}
+                        variant="unstyled"
+                        size={inputSize}
+                        styles={{ input: { paddingLeft: compact ? 4 : undefined, paddingRight: compact ? 4 : undefined } }}
+                      />
                     )}
                   </Table.Td>
                 ))}
@@ -470,11 +535,31 @@ export function SpreadsheetView({ deck, setDeck, compact = false, showRawValues
             label="Field Type"
             data={[
               { value: 'text', label: 'Text' },
+              { value: 'richtext', label: 'Rich Text (multi-line)' },
+              { value: 'number', label: 'Number' },
+              { value: 'integer', label: 'Whole Number' },
+              { value: 'boolean', label: 'Yes / No' },
+              { value: 'enum', label: 'Choice' },
               { value: 'image', label: 'Image' },
+              { value: 'icon', label: 'Icon' },
             ]}
             value={newFieldType}
             onChange={setNewFieldType}
           />
+          {newFieldType === 'enum' && (
+            <TagsInput
+              label="Options"
+              description="Press Enter after each allowed value"
+              value={newFieldOptions}
+              onChange={setNewFieldOptions}
+            />
+          )}
+          <Switch
+            label="Required"
+            description="Imports report cards that leave this field empty"
+            checked={newFieldRequired}
+            onChange={(e) => setNewFieldRequired(e.currentTarget.checked)}
+          />
           <Group justify="flex-end">
             <Button variant="default" onClick={() => setIsAddingField(false)}>Cancel</Button>
             <Button onClick={addField}>Add Field</Button>
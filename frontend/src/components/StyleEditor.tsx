@@ -238,7 +238,7 @@ export function StyleEditor({ deck: externalDeck, setDeck: setExternalDeck }: St
     // Auto-detect field for images
     let defaultField = undefined;
     if (type === 'image') {
-        const imageField = deck.fields.find(f => f.type === 'image');
+        const imageField = deck.fields.find(f => f.type === 'image' || f.type === 'icon');
         if (imageField) defaultField = imageField.name;
     }
 
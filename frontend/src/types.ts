@@ -1,6 +1,10 @@
+export type FieldType = 'text' | 'image' | 'number' | 'integer' | 'boolean' | 'enum' | 'richtext' | 'icon';
+
 export interface FieldDefinition {
     name: string;
-    type: 'text' | 'image';
+    type: FieldType;
+    options?: string[]; // Allowed values of an enum field
+    required?: boolean;
 }
 
 export interface CardBack {
@@ -56,6 +60,7 @@ export interface CustomFont {
 }
 
 export interface RenderedCard {
+    cardId?: string;
     styleId: string;
     side: 'front' | 'back';
     image: string; // base64 encoded PNG
@@ -73,18 +78,51 @@ export interface Deck {
     defaultFrontStyleId: string;
     defaultBackStyleId: string;
     customFonts: CustomFont[];
-    paperSize: 'letter' | 'a4';
+    bleed?: number; // mm added on each side of the trim size
+    keepBleed?: boolean; // Print the bleed area instead of clipping to trim
+    paperSize: string; // Paper size preset ID, or 'custom'
+    paperWidth?: number; // Custom paper width in mm
+    paperHeight?: number; // Custom paper height in mm
+    orientation?: 'auto' | 'portrait' | 'landscape';
     drawCutGuides?: boolean;
+    cutGuideStyle?: 'none' | 'outline' | 'crop-marks';
+    registrationMarks?: boolean;
+    imposition?: Imposition; // Overrides for the automatic page layout
+    duplexMode?: DuplexMode;
+    backOffsetX?: number; // mm to shift back pages right
+    backOffsetY?: number; // mm to shift back pages down
+    backRotation?: number; // Degrees to rotate back pages clockwise
     renderedCards?: RenderedCard[]; // Optional for PDF generation
 }
 
+export type DuplexMode = 'long-edge' | 'short-edge' | 'fronts-only' | 'backs-only' | 'fronts-then-backs' | 'gutter-fold';
+
+// Unset values fall back to the automatic layout. Sizes are in mm.
+export interface Imposition {
+    marginTop?: number;
+    marginRight?: number;
+    marginBottom?: number;
+    marginLeft?: number;
+    gutter?: number; // Space between adjacent cards
+    rows?: number; // 0 for auto
+    cols?: number; // 0 for auto
+    horizontalAlign?: 'left' | 'center' | 'right';
+    verticalAlign?: 'top' | 'middle' | 'bottom';
+    printerMargin?: number; // Non-printable edge of the printer
+}
+
 export interface PDFLayout {
     pageWidth: number;
     pageHeight: number;
+    orientation: 'portrait' | 'landscape';
+    duplexMode: DuplexMode; // gutter-fold splits the grid into front and back halves
     cardsPerRow: number;
     cardsPerCol: number;
     cardWidth: number;
     cardHeight: number;
+    trimWidth: number;
+    trimHeight: number;
+    bleed: number;
     spacing: number;
     marginLeft: number;
     marginTop: number;
@@ -96,6 +134,7 @@ export const DEFAULT_LAYOUT: CardLayout = {
 };
 
 export interface Game {
+    formatVersion?: number; // Set by the backend when saving
     name: string;
     decks: Deck[];
 }
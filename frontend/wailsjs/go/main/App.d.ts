@@ -1,45 +1,88 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
-import {game} from '../models';
 import {deck} from '../models';
-import {cards} from '../models';
+import {tabular} from '../models';
+import {game} from '../models';
+import {pdf} from '../models';
+import {project} from '../models';
 import {main} from '../models';
+import {cards} from '../models';
 
 export function AddProjectImage(arg1:string):Promise<string>;
 
 export function AddProjectImages(arg1:Array<string>):Promise<Array<string>>;
 
+export function ApplyCardSync(arg1:Array<deck.Card>,arg2:tabular.SyncDiff,arg3:boolean):Promise<Array<deck.Card>>;
+
+export function CheckRecovery():Promise<game.Game>;
+
+export function ClearRecentProjects():Promise<void>;
+
 export function DeleteProjectImage(arg1:string):Promise<void>;
 
-export function ExportGameXLSX(arg1:game.Game):Promise<void>;
+export function DiffSnapshots(arg1:string,arg2:string):Promise<game.Diff>;
+
+export function DiffWithSnapshot(arg1:string,arg2:game.Game):Promise<game.Diff>;
 
-export function ExportXLSX(arg1:Array<deck.Card>,arg2:Array<deck.FieldDefinition>):Promise<void>;
+export function ExportGameODS(arg1:game.Game):Promise<void>;
+
+export function ExportGameXLSX(arg1:game.Game,arg2:boolean):Promise<void>;
+
+export function ExportODS(arg1:deck.Deck):Promise<void>;
+
+export function ExportXLSX(arg1:deck.Deck):Promise<void>;
+
+export function ExtractBundle():Promise<game.Game>;
+
+export function GenerateCalibrationPDF(arg1:deck.Deck):Promise<void>;
 
 export function GeneratePDF(arg1:deck.Deck):Promise<void>;
 
 export function GetExcelHeaders(arg1:string,arg2:string):Promise<Array<string>>;
 
+export function GetImportMapping(arg1:string,arg2:string):Promise<Record<string, string>>;
+
 export function GetPDFLayout(arg1:deck.Deck):Promise<deck.PDFLayout>;
 
+export function GetPaperSizes():Promise<Array<pdf.PaperSize>>;
+
+export function GetRecentProjects():Promise<Array<project.RecentProject>>;
+
+export function GetWatchedSpreadsheet():Promise<main.SpreadsheetUpdate>;
+
 export function Greet(arg1:string):Promise<string>;
 
-export function ImportCardsWithMapping(arg1:string,arg2:string,arg3:Record<string, string>):Promise<Array<deck.Card>>;
+export function ImportCardsWithMapping(arg1:string,arg2:string,arg3:Record<string, string>,arg4:deck.Deck,arg5:boolean):Promise<tabular.ImportResult>;
 
 export function ListProjectImages():Promise<Array<string>>;
 
+export function ListSnapshots():Promise<Array<project.Snapshot>>;
+
 export function LoadGame():Promise<game.Game>;
 
 export function LoadImageAsDataURL(arg1:string):Promise<string>;
 
 export function NewGame():Promise<void>;
 
+export function OpenBundle():Promise<game.Game>;
+
+export function OpenRecentProject(arg1:string):Promise<game.Game>;
+
+export function RemoveRecentProject(arg1:string):Promise<void>;
+
 export function ReplaceProjectImage(arg1:string,arg2:string):Promise<void>;
 
 export function ResolveImagePath(arg1:string):Promise<string>;
 
+export function RestoreSnapshot(arg1:string):Promise<game.Game>;
+
 export function SampleDeck():Promise<Array<cards.Card>>;
 
-export function SaveGame(arg1:game.Game):Promise<void>;
+export function SaveBundle(arg1:game.Game):Promise<project.BundleReport>;
+
+export function SaveGame(arg1:game.Game):Promise<string>;
+
+export function SaveGameAs(arg1:game.Game):Promise<string>;
 
 export function SaveImages(arg1:Record<string, string>):Promise<void>;
 
@@ -50,3 +93,13 @@ export function SelectFontFile():Promise<string>;
 export function SelectImageFile():Promise<string>;
 
 export function SelectImageFiles():Promise<Array<string>>;
+
+export function SetWatchedDeck(arg1:deck.Deck):Promise<void>;
+
+export function StopWatchingSpreadsheet():Promise<void>;
+
+export function SyncCardsWithMapping(arg1:string,arg2:string,arg3:Record<string, string>,arg4:deck.Deck):Promise<tabular.SyncDiff>;
+
+export function UpdateAutosave(arg1:game.Game):Promise<void>;
+
+export function WatchSpreadsheet(arg1:deck.Deck,arg2:string,arg3:string,arg4:Record<string, string>,arg5:boolean):Promise<void>;
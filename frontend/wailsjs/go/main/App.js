@@ -10,16 +10,52 @@ export function AddProjectImages(arg1) {
   return window['go']['main']['App']['AddProjectImages'](arg1);
 }
 
+export function ApplyCardSync(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ApplyCardSync'](arg1, arg2, arg3);
+}
+
+export function CheckRecovery() {
+  return window['go']['main']['App']['CheckRecovery']();
+}
+
+export function ClearRecentProjects() {
+  return window['go']['main']['App']['ClearRecentProjects']();
+}
+
 export function DeleteProjectImage(arg1) {
   return window['go']['main']['App']['DeleteProjectImage'](arg1);
 }
 
-export function ExportGameXLSX(arg1) {
-  return window['go']['main']['App']['ExportGameXLSX'](arg1);
+export function DiffSnapshots(arg1, arg2) {
+  return window['go']['main']['App']['DiffSnapshots'](arg1, arg2);
 }
 
-export function ExportXLSX(arg1, arg2) {
-  return window['go']['main']['App']['ExportXLSX'](arg1, arg2);
+export function DiffWithSnapshot(arg1, arg2) {
+  return window['go']['main']['App']['DiffWithSnapshot'](arg1, arg2);
+}
+
+export function ExportGameODS(arg1) {
+  return window['go']['main']['App']['ExportGameODS'](arg1);
+}
+
+export function ExportGameXLSX(arg1, arg2) {
+  return window['go']['main']['App']['ExportGameXLSX'](arg1, arg2);
+}
+
+export function ExportODS(arg1) {
+  return window['go']['main']['App']['ExportODS'](arg1);
+}
+
+export function ExportXLSX(arg1) {
+  return window['go']['main']['App']['ExportXLSX'](arg1);
+}
+
+export function ExtractBundle() {
+  return window['go']['main']['App']['ExtractBundle']();
+}
+
+export function GenerateCalibrationPDF(arg1) {
+  return window['go']['main']['App']['GenerateCalibrationPDF'](arg1);
 }
 
 export function GeneratePDF(arg1) {
@@ -30,22 +66,42 @@ export function GetExcelHeaders(arg1, arg2) {
   return window['go']['main']['App']['GetExcelHeaders'](arg1, arg2);
 }
 
+export function GetImportMapping(arg1, arg2) {
+  return window['go']['main']['App']['GetImportMapping'](arg1, arg2);
+}
+
 export function GetPDFLayout(arg1) {
   return window['go']['main']['App']['GetPDFLayout'](arg1);
 }
 
+export function GetPaperSizes() {
+  return window['go']['main']['App']['GetPaperSizes']();
+}
+
+export function GetRecentProjects() {
+  return window['go']['main']['App']['GetRecentProjects']();
+}
+
+export function GetWatchedSpreadsheet() {
+  return window['go']['main']['App']['GetWatchedSpreadsheet']();
+}
+
 export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
-export function ImportCardsWithMapping(arg1, arg2, arg3) {
-  return window['go']['main']['App']['ImportCardsWithMapping'](arg1, arg2, arg3);
+export function ImportCardsWithMapping(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['ImportCardsWithMapping'](arg1, arg2, arg3, arg4, arg5);
 }
 
 export function ListProjectImages() {
   return window['go']['main']['App']['ListProjectImages']();
 }
 
+export function ListSnapshots() {
+  return window['go']['main']['App']['ListSnapshots']();
+}
+
 export function LoadGame() {
   return window['go']['main']['App']['LoadGame']();
 }
@@ -58,6 +114,18 @@ export function NewGame() {
   return window['go']['main']['App']['NewGame']();
 }
 
+export function OpenBundle() {
+  return window['go']['main']['App']['OpenBundle']();
+}
+
+export function OpenRecentProject(arg1) {
+  return window['go']['main']['App']['OpenRecentProject'](arg1);
+}
+
+export function RemoveRecentProject(arg1) {
+  return window['go']['main']['App']['RemoveRecentProject'](arg1);
+}
+
 export function ReplaceProjectImage(arg1, arg2) {
   return window['go']['main']['App']['ReplaceProjectImage'](arg1, arg2);
 }
@@ -66,14 +134,26 @@ export function ResolveImagePath(arg1) {
   return window['go']['main']['App']['ResolveImagePath'](arg1);
 }
 
+export function RestoreSnapshot(arg1) {
+  return window['go']['main']['App']['RestoreSnapshot'](arg1);
+}
+
 export function SampleDeck() {
   return window['go']['main']['App']['SampleDeck']();
 }
 
+export function SaveBundle(arg1) {
+  return window['go']['main']['App']['SaveBundle'](arg1);
+}
+
 export function SaveGame(arg1) {
   return window['go']['main']['App']['SaveGame'](arg1);
 }
 
+export function SaveGameAs(arg1) {
+  return window['go']['main']['App']['SaveGameAs'](arg1);
+}
+
 export function SaveImages(arg1) {
   return window['go']['main']['App']['SaveImages'](arg1);
 }
@@ -93,3 +173,23 @@ export function SelectImageFile() {
 export function SelectImageFiles() {
   return window['go']['main']['App']['SelectImageFiles']();
 }
+
+export function SetWatchedDeck(arg1) {
+  return window['go']['main']['App']['SetWatchedDeck'](arg1);
+}
+
+export function StopWatchingSpreadsheet() {
+  return window['go']['main']['App']['StopWatchingSpreadsheet']();
+}
+
+export function SyncCardsWithMapping(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SyncCardsWithMapping'](arg1, arg2, arg3, arg4);
+}
+
+export function UpdateAutosave(arg1) {
+  return window['go']['main']['App']['UpdateAutosave'](arg1);
+}
+
+export function WatchSpreadsheet(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['WatchSpreadsheet'](arg1, arg2, arg3, arg4, arg5);
+}
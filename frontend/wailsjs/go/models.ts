@@ -43,6 +43,20 @@ export namespace deck {
 	        this.backStyleId = source["backStyleId"];
 	    }
 	}
+	export class Point {
+	    x: number;
+	    y: number;
+
+	    static createFrom(source: any = {}) {
+	        return new Point(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.x = source["x"];
+	        this.y = source["y"];
+	    }
+	}
 	export class LayoutElement {
 	    id: string;
 	    name?: string;
@@ -62,6 +76,10 @@ export namespace deck {
 	    fontWeight?: string;
 	    fontStyle?: string;
 	    textDecoration?: string;
+	    points?: Point[];
+	    fillColor?: string;
+	    strokeColor?: string;
+	    strokeWidth?: number;
 
 	    static createFrom(source: any = {}) {
 	        return new LayoutElement(source);
@@ -87,7 +105,29 @@ export namespace deck {
 	        this.fontWeight = source["fontWeight"];
 	        this.fontStyle = source["fontStyle"];
 	        this.textDecoration = source["textDecoration"];
+	        this.points = this.convertValues(source["points"], Point);
+	        this.fillColor = source["fillColor"];
+	        this.strokeColor = source["strokeColor"];
+	        this.strokeWidth = source["strokeWidth"];
 	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class CardLayout {
 	    name: string;
@@ -121,7 +161,24 @@ export namespace deck {
 		    return a;
 		}
 	}
+	export class CustomFont {
+	    name: string;
+	    path: string;
+	    family: string;
+
+	    static createFrom(source: any = {}) {
+	        return new CustomFont(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.family = source["family"];
+	    }
+	}
 	export class RenderedCard {
+	    cardId?: string;
 	    styleId: string;
 	    side: string;
 	    image: string;
@@ -132,14 +189,47 @@ export namespace deck {
 
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.cardId = source["cardId"];
 	        this.styleId = source["styleId"];
 	        this.side = source["side"];
 	        this.image = source["image"];
 	    }
 	}
+	export class Imposition {
+	    marginTop?: number;
+	    marginRight?: number;
+	    marginBottom?: number;
+	    marginLeft?: number;
+	    gutter?: number;
+	    rows?: number;
+	    cols?: number;
+	    horizontalAlign?: string;
+	    verticalAlign?: string;
+	    printerMargin?: number;
+
+	    static createFrom(source: any = {}) {
+	        return new Imposition(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.marginTop = source["marginTop"];
+	        this.marginRight = source["marginRight"];
+	        this.marginBottom = source["marginBottom"];
+	        this.marginLeft = source["marginLeft"];
+	        this.gutter = source["gutter"];
+	        this.rows = source["rows"];
+	        this.cols = source["cols"];
+	        this.horizontalAlign = source["horizontalAlign"];
+	        this.verticalAlign = source["verticalAlign"];
+	        this.printerMargin = source["printerMargin"];
+	    }
+	}
 	export class FieldDefinition {
 	    name: string;
 	    type: string;
+	    options?: string[];
+	    required?: boolean;
 
 	    static createFrom(source: any = {}) {
 	        return new FieldDefinition(source);
@@ -149,6 +239,8 @@ export namespace deck {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
 	        this.type = source["type"];
+	        this.options = source["options"];
+	        this.required = source["required"];
 	    }
 	}
 	export class Deck {
@@ -162,8 +254,21 @@ export namespace deck {
 	    backStyles: Record<string, CardLayout>;
 	    defaultFrontStyleId: string;
 	    defaultBackStyleId: string;
+	    customFonts?: CustomFont[];
+	    bleed?: number;
+	    keepBleed?: boolean;
 	    paperSize: string;
+	    paperWidth?: number;
+	    paperHeight?: number;
+	    orientation?: string;
 	    drawCutGuides: boolean;
+	    cutGuideStyle?: string;
+	    registrationMarks?: boolean;
+	    imposition?: Imposition;
+	    duplexMode?: string;
+	    backOffsetX?: number;
+	    backOffsetY?: number;
+	    backRotation?: number;
 	    renderedCards: RenderedCard[];
 
 	    static createFrom(source: any = {}) {
@@ -182,8 +287,21 @@ export namespace deck {
 	        this.backStyles = this.convertValues(source["backStyles"], CardLayout, true);
 	        this.defaultFrontStyleId = source["defaultFrontStyleId"];
 	        this.defaultBackStyleId = source["defaultBackStyleId"];
+	        this.customFonts = this.convertValues(source["customFonts"], CustomFont);
+	        this.bleed = source["bleed"];
+	        this.keepBleed = source["keepBleed"];
 	        this.paperSize = source["paperSize"];
+	        this.paperWidth = source["paperWidth"];
+	        this.paperHeight = source["paperHeight"];
+	        this.orientation = source["orientation"];
 	        this.drawCutGuides = source["drawCutGuides"];
+	        this.cutGuideStyle = source["cutGuideStyle"];
+	        this.registrationMarks = source["registrationMarks"];
+	        this.imposition = this.convertValues(source["imposition"], Imposition);
+	        this.duplexMode = source["duplexMode"];
+	        this.backOffsetX = source["backOffsetX"];
+	        this.backOffsetY = source["backOffsetY"];
+	        this.backRotation = source["backRotation"];
 	        this.renderedCards = this.convertValues(source["renderedCards"], RenderedCard);
 	    }
 
@@ -207,13 +325,19 @@ export namespace deck {
 	}
 
 
+
 	export class PDFLayout {
 	    pageWidth: number;
 	    pageHeight: number;
+	    orientation: string;
+	    duplexMode: string;
 	    cardsPerRow: number;
 	    cardsPerCol: number;
 	    cardWidth: number;
 	    cardHeight: number;
+	    trimWidth: number;
+	    trimHeight: number;
+	    bleed: number;
 	    spacing: number;
 	    marginLeft: number;
 	    marginTop: number;
@@ -226,21 +350,194 @@ export namespace deck {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.pageWidth = source["pageWidth"];
 	        this.pageHeight = source["pageHeight"];
+	        this.orientation = source["orientation"];
+	        this.duplexMode = source["duplexMode"];
 	        this.cardsPerRow = source["cardsPerRow"];
 	        this.cardsPerCol = source["cardsPerCol"];
 	        this.cardWidth = source["cardWidth"];
 	        this.cardHeight = source["cardHeight"];
+	        this.trimWidth = source["trimWidth"];
+	        this.trimHeight = source["trimHeight"];
+	        this.bleed = source["bleed"];
 	        this.spacing = source["spacing"];
 	        this.marginLeft = source["marginLeft"];
 	        this.marginTop = source["marginTop"];
 	    }
 	}
 
+
 }
 
 export namespace game {
 
+	export class StyleDiff {
+	    id: string;
+	    side: string;
+	    name: string;
+	    change: string;
+	    changes?: ValueChange[];
+	    elements?: ItemDiff[];
+
+	    static createFrom(source: any = {}) {
+	        return new StyleDiff(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.side = source["side"];
+	        this.name = source["name"];
+	        this.change = source["change"];
+	        this.changes = this.convertValues(source["changes"], ValueChange);
+	        this.elements = this.convertValues(source["elements"], ItemDiff);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ItemDiff {
+	    id: string;
+	    name?: string;
+	    change: string;
+	    changes?: ValueChange[];
+	    moved?: boolean;
+
+	    static createFrom(source: any = {}) {
+	        return new ItemDiff(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.change = source["change"];
+	        this.changes = this.convertValues(source["changes"], ValueChange);
+	        this.moved = source["moved"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ValueChange {
+	    key: string;
+	    old: any;
+	    new: any;
+
+	    static createFrom(source: any = {}) {
+	        return new ValueChange(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	    }
+	}
+	export class DeckDiff {
+	    id: string;
+	    name: string;
+	    change: string;
+	    changes?: ValueChange[];
+	    cards?: ItemDiff[];
+	    styles?: StyleDiff[];
+
+	    static createFrom(source: any = {}) {
+	        return new DeckDiff(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.change = source["change"];
+	        this.changes = this.convertValues(source["changes"], ValueChange);
+	        this.cards = this.convertValues(source["cards"], ItemDiff);
+	        this.styles = this.convertValues(source["styles"], StyleDiff);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Diff {
+	    changes?: ValueChange[];
+	    decks?: DeckDiff[];
+
+	    static createFrom(source: any = {}) {
+	        return new Diff(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.changes = this.convertValues(source["changes"], ValueChange);
+	        this.decks = this.convertValues(source["decks"], DeckDiff);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Game {
+	    formatVersion: number;
 	    name: string;
 	    decks: deck.Deck[];
 
@@ -250,6 +547,7 @@ export namespace game {
 
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.formatVersion = source["formatVersion"];
 	        this.name = source["name"];
 	        this.decks = this.convertValues(source["decks"], deck.Deck);
 	    }
@@ -273,6 +571,8 @@ export namespace game {
 		}
 	}
 
+
+
 }
 
 export namespace main {
@@ -291,5 +591,311 @@ export namespace main {
 	        this.sheets = source["sheets"];
 	    }
 	}
+	export class SpreadsheetUpdate {
+	    deckId: string;
+	    filePath: string;
+	    sheet: string;
+	    removeMissing: boolean;
+	    diff?: tabular.SyncDiff;
+	    fields?: deck.FieldDefinition[];
+	    issues?: tabular.Issue[];
+	    error?: string;
+
+	    static createFrom(source: any = {}) {
+	        return new SpreadsheetUpdate(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.deckId = source["deckId"];
+	        this.filePath = source["filePath"];
+	        this.sheet = source["sheet"];
+	        this.removeMissing = source["removeMissing"];
+	        this.diff = this.convertValues(source["diff"], tabular.SyncDiff);
+	        this.fields = this.convertValues(source["fields"], deck.FieldDefinition);
+	        this.issues = this.convertValues(source["issues"], tabular.Issue);
+	        this.error = source["error"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace pdf {
+
+	export class PaperSize {
+	    id: string;
+	    name: string;
+	    width: number;
+	    height: number;
+
+	    static createFrom(source: any = {}) {
+	        return new PaperSize(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	    }
+	}
+
+}
+
+export namespace project {
+
+	export class BundleReport {
+	    missing: string[];
+	    extra: string[];
+
+	    static createFrom(source: any = {}) {
+	        return new BundleReport(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.missing = source["missing"];
+	        this.extra = source["extra"];
+	    }
+	}
+	export class RecentProject {
+	    path: string;
+	    name: string;
+	    // Go type: time
+	    openedAt: any;
+
+	    static createFrom(source: any = {}) {
+	        return new RecentProject(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.openedAt = this.convertValues(source["openedAt"], null);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Snapshot {
+	    id: string;
+	    // Go type: time
+	    savedAt: any;
+	    size: number;
+
+	    static createFrom(source: any = {}) {
+	        return new Snapshot(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.savedAt = this.convertValues(source["savedAt"], null);
+	        this.size = source["size"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace tabular {
+
+	export class FieldChange {
+	    field: string;
+	    old: string;
+	    new: string;
+
+	    static createFrom(source: any = {}) {
+	        return new FieldChange(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	    }
+	}
+	export class CardChange {
+	    card: deck.Card;
+	    row: number;
+	    changes: FieldChange[];
+
+	    static createFrom(source: any = {}) {
+	        return new CardChange(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.card = this.convertValues(source["card"], deck.Card);
+	        this.row = source["row"];
+	        this.changes = this.convertValues(source["changes"], FieldChange);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+	export class Issue {
+	    row: number;
+	    column: string;
+	    severity: string;
+	    message: string;
+
+	    static createFrom(source: any = {}) {
+	        return new Issue(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.column = source["column"];
+	        this.severity = source["severity"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ImportResult {
+	    cards: deck.Card[];
+	    issues: Issue[];
+	    fields?: deck.FieldDefinition[];
+
+	    static createFrom(source: any = {}) {
+	        return new ImportResult(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.cards = this.convertValues(source["cards"], deck.Card);
+	        this.issues = this.convertValues(source["issues"], Issue);
+	        this.fields = this.convertValues(source["fields"], deck.FieldDefinition);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+	export class SyncDiff {
+	    added: deck.Card[];
+	    changed: CardChange[];
+	    removed: deck.Card[];
+	    unchanged: number;
+	    skipped: number[];
+	    issues: Issue[];
+
+	    static createFrom(source: any = {}) {
+	        return new SyncDiff(source);
+	    }
+
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.added = this.convertValues(source["added"], deck.Card);
+	        this.changed = this.convertValues(source["changed"], CardChange);
+	        this.removed = this.convertValues(source["removed"], deck.Card);
+	        this.unchanged = source["unchanged"];
+	        this.skipped = source["skipped"];
+	        this.issues = this.convertValues(source["issues"], Issue);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
+
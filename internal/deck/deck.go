@@ -1,8 +1,11 @@
 package deck
 
 type FieldDefinition struct {
-	Name string `json:"name"`
-	Type string `json:"type"` // "text" or "image"
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`              // "text", "image", "number", "integer", "boolean", "enum", "richtext" or "icon"
+	Options  []string `json:"options,omitempty"` // Allowed values of an enum field
+	Required bool     `json:"required,omitempty"`
+	Extra    Extras   `json:"-"`
 }
 
 type CardBack struct {
@@ -12,6 +15,13 @@ type CardBack struct {
 	Content string `json:"content"` // Hex color or image path
 }
 
+// Point is a shape vertex, normalized 0-1 relative to the element's width/height
+type Point struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Extra Extras  `json:"-"`
+}
+
 type LayoutElement struct {
 	ID             string  `json:"id"`
 	Name           string  `json:"name,omitempty"`
@@ -31,11 +41,18 @@ type LayoutElement struct {
 	FontWeight     string  `json:"fontWeight,omitempty"`     // "normal", "bold"
 	FontStyle      string  `json:"fontStyle,omitempty"`      // "normal", "italic"
 	TextDecoration string  `json:"textDecoration,omitempty"` // "none", "underline"
+	// Shape properties
+	Points      []Point `json:"points,omitempty"`
+	FillColor   string  `json:"fillColor,omitempty"`
+	StrokeColor string  `json:"strokeColor,omitempty"`
+	StrokeWidth float64 `json:"strokeWidth,omitempty"`
+	Extra       Extras  `json:"-"`
 }
 
 type CardLayout struct {
 	Name     string          `json:"name"`
 	Elements []LayoutElement `json:"elements"`
+	Extra    Extras          `json:"-"`
 }
 
 type Card struct {
@@ -44,6 +61,15 @@ type Card struct {
 	Count        int                    `json:"count"`
 	FrontStyleID string                 `json:"frontStyleId"`
 	BackStyleID  string                 `json:"backStyleId"`
+	Extra        Extras                 `json:"-"`
+}
+
+// CustomFont is a font file added to a deck for use in its layouts
+type CustomFont struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Family string `json:"family"` // CSS font-family used by layout elements
+	Extra  Extras `json:"-"`
 }
 
 type Deck struct {
@@ -57,24 +83,61 @@ type Deck struct {
 	BackStyles          map[string]CardLayout `json:"backStyles"`
 	DefaultFrontStyleID string                `json:"defaultFrontStyleId"`
 	DefaultBackStyleID  string                `json:"defaultBackStyleId"`
-	PaperSize           string                `json:"paperSize"`     // "letter" or "a4"
-	DrawCutGuides       bool                  `json:"drawCutGuides"` // Draw borders around cards
-	RenderedCards       []RenderedCard        `json:"renderedCards"` // Pre-rendered card images for PDF
+	CustomFonts         []CustomFont          `json:"customFonts,omitempty"`
+	Bleed               float64               `json:"bleed,omitempty"`             // Bleed in mm added on each side of the trim size
+	KeepBleed           bool                  `json:"keepBleed,omitempty"`         // Print the bleed area (print shops) instead of clipping to trim (home printing)
+	PaperSize           string                `json:"paperSize"`                   // Preset ID such as "letter", "a4", "sra3", or "custom"
+	PaperWidth          float64               `json:"paperWidth,omitempty"`        // Custom paper width in mm
+	PaperHeight         float64               `json:"paperHeight,omitempty"`       // Custom paper height in mm
+	Orientation         string                `json:"orientation,omitempty"`       // "portrait", "landscape", or "auto" (default)
+	DrawCutGuides       bool                  `json:"drawCutGuides"`               // Draw borders around cards
+	CutGuideStyle       string                `json:"cutGuideStyle,omitempty"`     // "none", "outline" or "crop-marks"; empty follows DrawCutGuides
+	RegistrationMarks   bool                  `json:"registrationMarks,omitempty"` // Draw registration targets for aligning duplex sheets
+	Imposition          *Imposition           `json:"imposition,omitempty"`        // Overrides for the automatic page layout
+	DuplexMode          string                `json:"duplexMode,omitempty"`        // "long-edge" (default), "short-edge", "fronts-only", "backs-only", "fronts-then-backs" or "gutter-fold"
+	BackOffsetX         float64               `json:"backOffsetX,omitempty"`       // mm to shift back pages right, cancelling printer drift
+	BackOffsetY         float64               `json:"backOffsetY,omitempty"`       // mm to shift back pages down
+	BackRotation        float64               `json:"backRotation,omitempty"`      // Degrees to rotate back pages clockwise about the page centre
+	RenderedCards       []RenderedCard        `json:"renderedCards"`               // Pre-rendered card images for PDF
+	Extra               Extras                `json:"-"`                           // Fields not known to this version
+}
+
+// Imposition overrides parts of the automatic card layout on the page.
+// Unset fields fall back to the auto-fit heuristic. Sizes are in mm.
+type Imposition struct {
+	MarginTop       *float64 `json:"marginTop,omitempty"`
+	MarginRight     *float64 `json:"marginRight,omitempty"`
+	MarginBottom    *float64 `json:"marginBottom,omitempty"`
+	MarginLeft      *float64 `json:"marginLeft,omitempty"`
+	Gutter          *float64 `json:"gutter,omitempty"`          // Space between adjacent cards
+	Rows            int      `json:"rows,omitempty"`            // Cards per column, 0 for auto
+	Cols            int      `json:"cols,omitempty"`            // Cards per row, 0 for auto
+	HorizontalAlign string   `json:"horizontalAlign,omitempty"` // "left", "center" (default), "right"
+	VerticalAlign   string   `json:"verticalAlign,omitempty"`   // "top", "middle" (default), "bottom"
+	PrinterMargin   float64  `json:"printerMargin,omitempty"`   // Non-printable edge of the printer
+	Extra           Extras   `json:"-"`
 }
 
 type RenderedCard struct {
+	CardID  string `json:"cardId,omitempty"` // Card this image was rendered for
 	StyleID string `json:"styleId"`
 	Side    string `json:"side"`  // "front" or "back"
 	Image   string `json:"image"` // base64 encoded PNG
+	Extra   Extras `json:"-"`
 }
 
 type PDFLayout struct {
 	PageWidth   float64 `json:"pageWidth"`
 	PageHeight  float64 `json:"pageHeight"`
+	Orientation string  `json:"orientation"` // "portrait" or "landscape"
+	DuplexMode  string  `json:"duplexMode"`  // Resolved duplex mode; gutter-fold splits the grid into front and back halves
 	CardsPerRow int     `json:"cardsPerRow"`
 	CardsPerCol int     `json:"cardsPerCol"`
-	CardWidth   float64 `json:"cardWidth"`
-	CardHeight  float64 `json:"cardHeight"`
+	CardWidth   float64 `json:"cardWidth"`  // Size of each placed card, including bleed
+	CardHeight  float64 `json:"cardHeight"` // Size of each placed card, including bleed
+	TrimWidth   float64 `json:"trimWidth"`  // Finished card size after cutting
+	TrimHeight  float64 `json:"trimHeight"` // Finished card size after cutting
+	Bleed       float64 `json:"bleed"`      // Bleed on each side of the trim box (0 when clipped to trim)
 	Spacing     float64 `json:"spacing"`
 	MarginLeft  float64 `json:"marginLeft"`
 	MarginTop   float64 `json:"marginTop"`
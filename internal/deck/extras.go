@@ -0,0 +1,203 @@
+package deck
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Extras holds JSON fields the model does not know about, such as those written by a
+// newer version of the app, so saving a file never drops them
+type Extras map[string]json.RawMessage
+
+// knownFields caches the JSON names of each struct type's fields
+var knownFields sync.Map // reflect.Type -> map[string]bool
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	if names, ok := knownFields.Load(t); ok {
+		return names.(map[string]bool)
+	}
+
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+
+	knownFields.Store(t, names)
+	return names
+}
+
+// UnmarshalWithExtras decodes data into v, a pointer to a struct without custom
+// JSON methods, and collects the fields v has no place for into extras
+func UnmarshalWithExtras(data []byte, v any, extras *Extras) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	// encoding/json matches keys case-insensitively, so do the same here
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+	*extras = nil
+	for key, value := range raw {
+		if known[key] || containsFold(known, key) {
+			continue
+		}
+		if *extras == nil {
+			*extras = make(Extras)
+		}
+		(*extras)[key] = value
+	}
+	return nil
+}
+
+// MarshalWithExtras encodes v, a struct without custom JSON methods, and appends
+// the extra fields in key order. Extras never override known fields.
+func MarshalWithExtras(v any, extras Extras) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extras) == 0 {
+		return data, err
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v))
+	keys := make([]string, 0, len(extras))
+	for key := range extras {
+		if !known[key] {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimSuffix(data, []byte("}")))
+	for i, key := range keys {
+		if i > 0 || len(data) > 2 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(extras[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func containsFold(names map[string]bool, key string) bool {
+	for name := range names {
+		if strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Each file-format type keeps unknown fields in its Extra map
+
+func (f *FieldDefinition) UnmarshalJSON(data []byte) error {
+	type plain FieldDefinition
+	return UnmarshalWithExtras(data, (*plain)(f), &f.Extra)
+}
+
+func (f FieldDefinition) MarshalJSON() ([]byte, error) {
+	type plain FieldDefinition
+	return MarshalWithExtras(plain(f), f.Extra)
+}
+
+func (e *LayoutElement) UnmarshalJSON(data []byte) error {
+	type plain LayoutElement
+	return UnmarshalWithExtras(data, (*plain)(e), &e.Extra)
+}
+
+func (e LayoutElement) MarshalJSON() ([]byte, error) {
+	type plain LayoutElement
+	return MarshalWithExtras(plain(e), e.Extra)
+}
+
+func (l *CardLayout) UnmarshalJSON(data []byte) error {
+	type plain CardLayout
+	return UnmarshalWithExtras(data, (*plain)(l), &l.Extra)
+}
+
+func (l CardLayout) MarshalJSON() ([]byte, error) {
+	type plain CardLayout
+	return MarshalWithExtras(plain(l), l.Extra)
+}
+
+func (c *Card) UnmarshalJSON(data []byte) error {
+	type plain Card
+	return UnmarshalWithExtras(data, (*plain)(c), &c.Extra)
+}
+
+func (c Card) MarshalJSON() ([]byte, error) {
+	type plain Card
+	return MarshalWithExtras(plain(c), c.Extra)
+}
+
+func (f *CustomFont) UnmarshalJSON(data []byte) error {
+	type plain CustomFont
+	return UnmarshalWithExtras(data, (*plain)(f), &f.Extra)
+}
+
+func (f CustomFont) MarshalJSON() ([]byte, error) {
+	type plain CustomFont
+	return MarshalWithExtras(plain(f), f.Extra)
+}
+
+func (d *Deck) UnmarshalJSON(data []byte) error {
+	type plain Deck
+	return UnmarshalWithExtras(data, (*plain)(d), &d.Extra)
+}
+
+func (d Deck) MarshalJSON() ([]byte, error) {
+	type plain Deck
+	return MarshalWithExtras(plain(d), d.Extra)
+}
+
+func (p *Point) UnmarshalJSON(data []byte) error {
+	type plain Point
+	return UnmarshalWithExtras(data, (*plain)(p), &p.Extra)
+}
+
+func (p Point) MarshalJSON() ([]byte, error) {
+	type plain Point
+	return MarshalWithExtras(plain(p), p.Extra)
+}
+
+func (i *Imposition) UnmarshalJSON(data []byte) error {
+	type plain Imposition
+	return UnmarshalWithExtras(data, (*plain)(i), &i.Extra)
+}
+
+func (i Imposition) MarshalJSON() ([]byte, error) {
+	type plain Imposition
+	return MarshalWithExtras(plain(i), i.Extra)
+}
+
+func (r *RenderedCard) UnmarshalJSON(data []byte) error {
+	type plain RenderedCard
+	return UnmarshalWithExtras(data, (*plain)(r), &r.Extra)
+}
+
+func (r RenderedCard) MarshalJSON() ([]byte, error) {
+	type plain RenderedCard
+	return MarshalWithExtras(plain(r), r.Extra)
+}
@@ -0,0 +1,72 @@
+package deck
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCardKeepsUnknownFields(t *testing.T) {
+	var c Card
+	if err := json.Unmarshal([]byte(`{"id":"c1","Count":2,"data":{},"locked":true,"tags":["a"]}`), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys matched case-insensitively by encoding/json are not extras
+	if c.Count != 2 {
+		t.Errorf("Count = %v, want 2", c.Count)
+	}
+	if len(c.Extra) != 2 || string(c.Extra["locked"]) != "true" || string(c.Extra["tags"]) != `["a"]` {
+		t.Errorf("Extra = %v, want locked and tags", c.Extra)
+	}
+
+	out, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"c1","data":{},"count":2,"frontStyleId":"","backStyleId":"","locked":true,"tags":["a"]}`
+	if string(out) != want {
+		t.Errorf("json.Marshal() = %s, want %s", out, want)
+	}
+}
+
+func TestExtrasNeverOverrideKnownFields(t *testing.T) {
+	f := FieldDefinition{Name: "cost", Type: "text", Extra: Extras{"name": json.RawMessage(`"stale"`)}}
+
+	out, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"cost","type":"text"}`; string(out) != want {
+		t.Errorf("json.Marshal() = %s, want %s", out, want)
+	}
+}
+
+func TestNestedUnknownFieldsRoundTrip(t *testing.T) {
+	in := `{"id":"d1","name":"Deck","frontStyles":{"f":{"name":"F","elements":[{"id":"e1","type":"shape","field":"","x":0,"y":0,"width":1,"height":1,"points":[{"x":0.5,"y":0,"curve":{"smooth":true}}]}]}},"backStyles":null,"cards":null,"imposition":{"rows":2,"bleedMarks":{"length":3}},"renderedCards":[{"styleId":"f","side":"front","image":"","dpi":300}]}`
+
+	var d Deck
+	if err := json.Unmarshal([]byte(in), &d); err != nil {
+		t.Fatal(err)
+	}
+	point := d.FrontStyles["f"].Elements[0].Points[0]
+	if string(point.Extra["curve"]) != `{"smooth":true}` {
+		t.Errorf("Point.Extra = %v, want curve", point.Extra)
+	}
+	if d.Imposition == nil || string(d.Imposition.Extra["bleedMarks"]) != `{"length":3}` {
+		t.Errorf("Imposition = %+v, want bleedMarks kept", d.Imposition)
+	}
+	if string(d.RenderedCards[0].Extra["dpi"]) != "300" {
+		t.Errorf("RenderedCard.Extra = %v, want dpi", d.RenderedCards[0].Extra)
+	}
+
+	out, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"curve":{"smooth":true}`, `"bleedMarks":{"length":3}`, `"dpi":300`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("json.Marshal() = %s, want it to keep %s", out, want)
+		}
+	}
+}
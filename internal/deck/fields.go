@@ -0,0 +1,252 @@
+package deck
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Field types
+const (
+	FieldText     = "text"
+	FieldImage    = "image"
+	FieldNumber   = "number"
+	FieldInteger  = "integer"
+	FieldBoolean  = "boolean"
+	FieldEnum     = "enum"     // One of the field's Options
+	FieldRichText = "richtext" // Multi-line text
+	FieldIcon     = "icon"     // Path of a small image, like FieldImage
+)
+
+var fieldTypes = map[string]bool{
+	FieldText: true, FieldImage: true, FieldNumber: true, FieldInteger: true,
+	FieldBoolean: true, FieldEnum: true, FieldRichText: true, FieldIcon: true,
+}
+
+// Spellings of boolean cells accepted on import, lowercased
+var (
+	trueWords  = map[string]bool{"true": true, "yes": true, "y": true, "1": true, "x": true, "on": true}
+	falseWords = map[string]bool{"false": true, "no": true, "n": true, "0": true, "off": true}
+)
+
+// IsEmpty reports whether a card value counts as not filled in
+func IsEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	s, ok := v.(string)
+	return ok && strings.TrimSpace(s) == ""
+}
+
+// FormatValue writes a card value as text. Numbers are written in full, as the
+// editor shows them, rather than in exponent form.
+func FormatValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	}
+	return fmt.Sprint(v)
+}
+
+// Coerce converts a value, typically a string read from a spreadsheet, to the
+// type stored for the field: float64 for numbers, int for integers, bool for
+// booleans and strings otherwise. Empty values are returned as "". On error the
+// value is returned unchanged.
+func (f FieldDefinition) Coerce(v interface{}) (interface{}, error) {
+	if IsEmpty(v) {
+		return "", nil
+	}
+
+	switch f.Type {
+	case FieldNumber:
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case float32:
+			return float64(n), nil
+		case int:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			if err != nil || math.IsInf(parsed, 0) || math.IsNaN(parsed) {
+				return v, fmt.Errorf("%q is not a number", n)
+			}
+			return parsed, nil
+		}
+		return v, fmt.Errorf("%v is not a number", v)
+
+	case FieldInteger:
+		switch n := v.(type) {
+		case int:
+			return n, nil
+		case int64:
+			return int(n), nil
+		case float64:
+			if n != math.Trunc(n) {
+				return v, fmt.Errorf("%v is not a whole number", n)
+			}
+			return int(n), nil
+		case string:
+			s := strings.TrimSpace(n)
+			if parsed, err := strconv.Atoi(s); err == nil {
+				return parsed, nil
+			}
+			// Spreadsheets may store whole numbers as "3.0"
+			if parsed, err := strconv.ParseFloat(s, 64); err == nil && parsed == math.Trunc(parsed) && math.Abs(parsed) < math.MaxInt32 {
+				return int(parsed), nil
+			}
+			return v, fmt.Errorf("%q is not a whole number", n)
+		}
+		return v, fmt.Errorf("%v is not a whole number", v)
+
+	case FieldBoolean:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			word := strings.ToLower(strings.TrimSpace(b))
+			if trueWords[word] {
+				return true, nil
+			}
+			if falseWords[word] {
+				return false, nil
+			}
+			return v, fmt.Errorf("%q is not true or false", b)
+		}
+		return v, fmt.Errorf("%v is not true or false", v)
+
+	case FieldEnum:
+		s := strings.TrimSpace(FormatValue(v))
+		for _, option := range f.Options {
+			if strings.EqualFold(option, s) {
+				return option, nil
+			}
+		}
+		return v, fmt.Errorf("%q is not one of %s", s, strings.Join(f.Options, ", "))
+
+	case FieldIcon, FieldImage:
+		s := strings.TrimSpace(FormatValue(v))
+		if strings.ContainsAny(s, "\r\n") {
+			return v, fmt.Errorf("%q is not a single path", s)
+		}
+		return s, nil
+	}
+
+	// Text and rich text keep the value as written
+	return FormatValue(v), nil
+}
+
+// Validate checks that a stored value has the field's type. Empty values are
+// always valid; Required is checked separately.
+func (f FieldDefinition) Validate(v interface{}) error {
+	if IsEmpty(v) {
+		return nil
+	}
+
+	switch f.Type {
+	case FieldNumber:
+		if _, ok := v.(float64); ok {
+			return nil
+		}
+		if _, ok := v.(int); ok {
+			return nil
+		}
+		return fmt.Errorf("%v is not a number", v)
+	case FieldInteger:
+		switch n := v.(type) {
+		case int:
+			return nil
+		case float64: // Whole numbers decode from JSON as float64
+			if n == math.Trunc(n) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%v is not a whole number", v)
+	case FieldBoolean:
+		if _, ok := v.(bool); ok {
+			return nil
+		}
+		return fmt.Errorf("%v is not true or false", v)
+	case FieldEnum, FieldIcon:
+		coerced, err := f.Coerce(v)
+		if err != nil {
+			return err
+		}
+		if coerced != v {
+			return fmt.Errorf("%v should be %v", v, coerced)
+		}
+		return nil
+	}
+
+	// Text is shown as written, so any single value will do
+	switch v.(type) {
+	case string, float64, int, bool:
+		return nil
+	}
+	return fmt.Errorf("%v is not text", v)
+}
+
+// CoerceFields converts every card value that can be converted to its field's
+// type, leaving values that cannot for ValidateFields to report. The deck gets
+// copies of its cards and their data, so copies of the deck are left unchanged.
+func (d *Deck) CoerceFields() {
+	if d.Cards == nil {
+		return
+	}
+	cards := make([]Card, len(d.Cards))
+	for i, c := range d.Cards {
+		if c.Data != nil {
+			data := make(map[string]interface{}, len(c.Data))
+			for k, v := range c.Data {
+				data[k] = v
+			}
+			for _, f := range d.Fields {
+				v, ok := data[f.Name]
+				if !ok {
+					continue
+				}
+				if coerced, err := f.Coerce(v); err == nil {
+					data[f.Name] = coerced
+				}
+			}
+			c.Data = data
+		}
+		cards[i] = c
+	}
+	d.Cards = cards
+}
+
+// ValidateFields checks the deck's field definitions and that every card value
+// has its field's type
+func (d Deck) ValidateFields() error {
+	var errs []error
+	for _, f := range d.Fields {
+		switch {
+		case f.Type == "":
+			// Older files leave text fields untyped
+		case !fieldTypes[f.Type]:
+			errs = append(errs, fmt.Errorf("field %s has unknown type %q", f.Name, f.Type))
+		case f.Type == FieldEnum && len(f.Options) == 0:
+			errs = append(errs, fmt.Errorf("field %s has no options", f.Name))
+		}
+	}
+
+	for _, c := range d.Cards {
+		for _, f := range d.Fields {
+			if err := f.Validate(c.Data[f.Name]); err != nil {
+				errs = append(errs, fmt.Errorf("card %s: field %s: %w", c.ID, f.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,104 @@
+package deck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFieldCoerce(t *testing.T) {
+	enum := FieldDefinition{Type: FieldEnum, Options: []string{"Common", "Rare"}}
+	tests := []struct {
+		field   FieldDefinition
+		in      interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{FieldDefinition{Type: FieldNumber}, " 2.5 ", 2.5, false},
+		{FieldDefinition{Type: FieldNumber}, 3, 3.0, false},
+		{FieldDefinition{Type: FieldNumber}, "three", "three", true},
+		{FieldDefinition{Type: FieldInteger}, "4", 4, false},
+		{FieldDefinition{Type: FieldInteger}, "4.0", 4, false},
+		{FieldDefinition{Type: FieldInteger}, 5.0, 5, false},
+		{FieldDefinition{Type: FieldInteger}, "4.5", "4.5", true},
+		{FieldDefinition{Type: FieldBoolean}, "TRUE", true, false},
+		{FieldDefinition{Type: FieldBoolean}, "no", false, false},
+		{FieldDefinition{Type: FieldBoolean}, "maybe", "maybe", true},
+		{enum, "rare", "Rare", false},
+		{enum, "Epic", "Epic", true},
+		{FieldDefinition{Type: FieldIcon}, " icons/fire.png ", "icons/fire.png", false},
+		{FieldDefinition{Type: FieldIcon}, "a\nb", "a\nb", true},
+		{FieldDefinition{Type: FieldRichText}, "line 1\nline 2", "line 1\nline 2", false},
+		{FieldDefinition{Type: FieldText}, 7.0, "7", false},
+		{FieldDefinition{Type: FieldText}, 1000000.0, "1000000", false},
+		{FieldDefinition{Type: FieldNumber}, "  ", "", false},
+	}
+	for _, tt := range tests {
+		got, err := tt.field.Coerce(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Coerce(%s, %#v) = %#v, %v, want %#v (error %v)", tt.field.Type, tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFieldValidate(t *testing.T) {
+	enum := FieldDefinition{Type: FieldEnum, Options: []string{"Common", "Rare"}}
+	tests := []struct {
+		field FieldDefinition
+		in    interface{}
+		valid bool
+	}{
+		{FieldDefinition{Type: FieldNumber}, 2.5, true},
+		{FieldDefinition{Type: FieldNumber}, "2.5", false},
+		{FieldDefinition{Type: FieldInteger}, 3.0, true},
+		{FieldDefinition{Type: FieldInteger}, 3.5, false},
+		{FieldDefinition{Type: FieldBoolean}, true, true},
+		{FieldDefinition{Type: FieldBoolean}, "true", false},
+		{enum, "Rare", true},
+		{enum, "rare", false},
+		{FieldDefinition{Type: FieldText}, 4.0, true},
+		{FieldDefinition{Type: FieldText}, []interface{}{"a"}, false},
+		{FieldDefinition{Type: FieldNumber}, "", true},
+		{FieldDefinition{Type: FieldNumber}, nil, true},
+	}
+	for _, tt := range tests {
+		if err := tt.field.Validate(tt.in); (err == nil) != tt.valid {
+			t.Errorf("Validate(%s, %#v) error = %v, want valid %v", tt.field.Type, tt.in, err, tt.valid)
+		}
+	}
+}
+
+func TestDeckCoerceAndValidateFields(t *testing.T) {
+	d := Deck{
+		Fields: []FieldDefinition{
+			{Name: "Cost", Type: FieldInteger},
+			{Name: "Rarity", Type: FieldEnum},
+			{Name: "Glow", Type: "sparkle"},
+		},
+		Cards: []Card{
+			{ID: "a", Data: map[string]interface{}{"Cost": "2"}},
+			{ID: "b", Data: map[string]interface{}{"Cost": "lots"}},
+		},
+	}
+
+	saved := d
+	d.CoerceFields()
+	if d.Cards[0].Data["Cost"] != 2 {
+		t.Errorf("CoerceFields() Cost = %#v, want 2", d.Cards[0].Data["Cost"])
+	}
+	if saved.Cards[0].Data["Cost"] != "2" {
+		t.Errorf("CoerceFields() changed a copy of the deck to %#v", saved.Cards[0].Data["Cost"])
+	}
+
+	err := d.ValidateFields()
+	if err == nil {
+		t.Fatal("ValidateFields() succeeded, want errors")
+	}
+	for _, want := range []string{"field Rarity has no options", `field Glow has unknown type "sparkle"`, "card b: field Cost"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateFields() error = %q, want it to mention %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "card a") {
+		t.Errorf("ValidateFields() reported a coerced value: %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"card_wizard/internal/deck"
+)
+
+// How an item differs between two games
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Diff lists what changed from one game to another, deck by deck
+type Diff struct {
+	Changes []ValueChange `json:"changes,omitempty"` // Game settings, like its name
+	Decks   []DeckDiff    `json:"decks,omitempty"`
+}
+
+// ValueChange is a setting or value that differs. Values are as they would be
+// saved, so numbers are float64.
+type ValueChange struct {
+	Key string      `json:"key"` // JSON name of the setting, or "data.<field>" for card values
+	Old interface{} `json:"old"` // nil when added
+	New interface{} `json:"new"` // nil when removed
+}
+
+// DeckDiff lists what changed in a deck
+type DeckDiff struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Change  string        `json:"change"`            // Added, Removed or Changed
+	Changes []ValueChange `json:"changes,omitempty"` // Deck settings, fields and fonts
+	Cards   []ItemDiff    `json:"cards,omitempty"`
+	Styles  []StyleDiff   `json:"styles,omitempty"`
+}
+
+// ItemDiff lists what changed in a card or layout element
+type ItemDiff struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name,omitempty"`
+	Change  string        `json:"change"`
+	Changes []ValueChange `json:"changes,omitempty"`
+	Moved   bool          `json:"moved,omitempty"` // An element whose position changed
+}
+
+// StyleDiff lists what changed in a front or back style
+type StyleDiff struct {
+	ID       string        `json:"id"`
+	Side     string        `json:"side"` // "front" or "back"
+	Name     string        `json:"name"`
+	Change   string        `json:"change"`
+	Changes  []ValueChange `json:"changes,omitempty"`
+	Elements []ItemDiff    `json:"elements,omitempty"`
+}
+
+// Empty reports whether the games are the same
+func (d Diff) Empty() bool {
+	return len(d.Changes) == 0 && len(d.Decks) == 0
+}
+
+// Compare returns what changed from game a to game b. Decks, cards, styles and
+// layout elements are matched by ID. Pre-rendered card images are ignored.
+func Compare(a, b Game) Diff {
+	var diff Diff
+	// Compare the game's own settings without marshalling its decks
+	ga, gb := a, b
+	ga.Decks, gb.Decks = nil, nil
+	diff.Changes = valueChanges("", values(ga), values(gb), "decks", "formatVersion")
+
+	old := make(map[string]deck.Deck, len(a.Decks))
+	for _, d := range a.Decks {
+		old[d.ID] = d
+	}
+	seen := make(map[string]bool, len(b.Decks))
+	for _, d := range b.Decks {
+		seen[d.ID] = true
+		prev, ok := old[d.ID]
+		if !ok {
+			diff.Decks = append(diff.Decks, DeckDiff{ID: d.ID, Name: d.Name, Change: Added})
+			continue
+		}
+		if dd := compareDecks(prev, d); dd.Change != "" {
+			diff.Decks = append(diff.Decks, dd)
+		}
+	}
+	for _, d := range a.Decks {
+		if !seen[d.ID] {
+			diff.Decks = append(diff.Decks, DeckDiff{ID: d.ID, Name: d.Name, Change: Removed})
+		}
+	}
+	return diff
+}
+
+// compareDecks returns what changed in a deck, with no Change if nothing did
+func compareDecks(a, b deck.Deck) DeckDiff {
+	diff := DeckDiff{ID: b.ID, Name: b.Name}
+	diff.Changes = valueChanges("", deckSettings(a), deckSettings(b), "id", "cards", "frontStyles", "backStyles", "renderedCards")
+	diff.Cards = compareCards(a.Cards, b.Cards)
+	diff.Styles = append(compareStyles("front", a.FrontStyles, b.FrontStyles), compareStyles("back", a.BackStyles, b.BackStyles)...)
+	if len(diff.Changes) > 0 || len(diff.Cards) > 0 || len(diff.Styles) > 0 {
+		diff.Change = Changed
+	}
+	return diff
+}
+
+// compareCards matches cards by ID; repeated IDs are matched in order
+func compareCards(a, b []deck.Card) []ItemDiff {
+	key := func(cards []deck.Card) []string {
+		keys := make([]string, len(cards))
+		count := make(map[string]int)
+		for i, c := range cards {
+			keys[i] = fmt.Sprintf("%s#%d", c.ID, count[c.ID])
+			count[c.ID]++
+		}
+		return keys
+	}
+	aKeys, bKeys := key(a), key(b)
+	old := make(map[string]deck.Card, len(a))
+	for i, c := range a {
+		old[aKeys[i]] = c
+	}
+
+	var diffs []ItemDiff
+	seen := make(map[string]bool, len(b))
+	for i, c := range b {
+		seen[bKeys[i]] = true
+		prev, ok := old[bKeys[i]]
+		if !ok {
+			diffs = append(diffs, ItemDiff{ID: c.ID, Change: Added})
+			continue
+		}
+		changes := valueChanges("", values(prev), values(c), "id", "data")
+		changes = append(changes, valueChanges("data.", values(prev.Data), values(c.Data))...)
+		if len(changes) > 0 {
+			diffs = append(diffs, ItemDiff{ID: c.ID, Change: Changed, Changes: changes})
+		}
+	}
+	for i, c := range a {
+		if !seen[aKeys[i]] {
+			diffs = append(diffs, ItemDiff{ID: c.ID, Change: Removed})
+		}
+	}
+	return diffs
+}
+
+// compareStyles matches the styles of one side by ID, in ID order
+func compareStyles(side string, a, b map[string]deck.CardLayout) []StyleDiff {
+	ids := make([]string, 0, len(a)+len(b))
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	var diffs []StyleDiff
+	for _, id := range ids {
+		prev, inA := a[id]
+		layout, inB := b[id]
+		switch {
+		case !inA:
+			diffs = append(diffs, StyleDiff{ID: id, Side: side, Name: layout.Name, Change: Added})
+		case !inB:
+			diffs = append(diffs, StyleDiff{ID: id, Side: side, Name: prev.Name, Change: Removed})
+		default:
+			diff := StyleDiff{ID: id, Side: side, Name: layout.Name}
+			diff.Changes = valueChanges("", values(prev), values(layout), "elements")
+			diff.Elements = compareElements(prev.Elements, layout.Elements)
+			if len(diff.Changes) > 0 || len(diff.Elements) > 0 {
+				diff.Change = Changed
+				diffs = append(diffs, diff)
+			}
+		}
+	}
+	return diffs
+}
+
+// compareElements matches layout elements by ID. A change in drawing order is
+// reported as a change of "layer".
+func compareElements(a, b []deck.LayoutElement) []ItemDiff {
+	old := make(map[string]int, len(a))
+	for i, el := range a {
+		old[el.ID] = i
+	}
+
+	var diffs []ItemDiff
+	seen := make(map[string]bool, len(b))
+	for i, el := range b {
+		seen[el.ID] = true
+		j, ok := old[el.ID]
+		if !ok {
+			diffs = append(diffs, ItemDiff{ID: el.ID, Name: el.Name, Change: Added})
+			continue
+		}
+		prev := a[j]
+		changes := valueChanges("", values(prev), values(el), "id")
+		if i != j {
+			changes = append(changes, ValueChange{Key: "layer", Old: float64(j), New: float64(i)})
+		}
+		if len(changes) > 0 {
+			diffs = append(diffs, ItemDiff{ID: el.ID, Name: el.Name, Change: Changed, Changes: changes, Moved: prev.X != el.X || prev.Y != el.Y})
+		}
+	}
+	for _, el := range a {
+		if !seen[el.ID] {
+			diffs = append(diffs, ItemDiff{ID: el.ID, Name: el.Name, Change: Removed})
+		}
+	}
+	return diffs
+}
+
+// deckSettings returns the values of a deck other than its cards, styles and
+// pre-rendered images
+func deckSettings(d deck.Deck) map[string]interface{} {
+	d.Cards, d.FrontStyles, d.BackStyles, d.RenderedCards = nil, nil, nil, nil
+	return values(d)
+}
+
+// values returns v as it would be saved, as a map of its JSON keys, so values
+// compare the same however they were read and settings added by newer versions
+// are compared too
+func values(v interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	if data, err := json.Marshal(v); err == nil {
+		json.Unmarshal(data, &m)
+	}
+	return m
+}
+
+// valueChanges returns the keys of a and b, other than skip, whose values
+// differ, in key order, each prefixed with prefix
+func valueChanges(prefix string, a, b map[string]interface{}, skip ...string) []ValueChange {
+	skipped := make(map[string]bool, len(skip))
+	for _, k := range skip {
+		skipped[k] = true
+	}
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []ValueChange
+	for _, k := range keys {
+		if skipped[k] || reflect.DeepEqual(a[k], b[k]) {
+			continue
+		}
+		changes = append(changes, ValueChange{Key: prefix + k, Old: a[k], New: b[k]})
+	}
+	return changes
+}
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"card_wizard/internal/deck"
+)
+
+func diffGame() Game {
+	return Game{
+		Name: "Test",
+		Decks: []deck.Deck{{
+			ID:    "weapons",
+			Name:  "Weapons",
+			Width: 63.5,
+			Cards: []deck.Card{
+				{ID: "dagger", Count: 2, Data: map[string]interface{}{"Name": "Dagger", "Cost": 1}},
+				{ID: "sword", Count: 1, Data: map[string]interface{}{"Name": "Sword", "Cost": 3}},
+			},
+			FrontStyles: map[string]deck.CardLayout{"front": {Name: "Front", Elements: []deck.LayoutElement{
+				{ID: "title", Name: "Title", Type: "text", X: 5, Y: 5, Width: 50, Height: 10},
+				{ID: "art", Name: "Art", Type: "image", X: 5, Y: 20, Width: 50, Height: 40},
+			}}},
+			BackStyles: map[string]deck.CardLayout{"back": {Name: "Back"}},
+		}, {
+			ID:   "spells",
+			Name: "Spells",
+		}},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := diffGame()
+	if diff := Compare(a, diffGame()); !diff.Empty() {
+		t.Errorf("Compare() of equal games = %+v, want empty", diff)
+	}
+
+	b := diffGame()
+	b.Name = "Renamed"
+	d := &b.Decks[0]
+	d.Width = 70
+	d.Cards[0].Data = map[string]interface{}{"Name": "Dagger", "Cost": 2.0, "Art": "images/dagger.png"}
+	d.Cards[1].Count = 2
+	d.Cards = append(d.Cards, deck.Card{ID: "bow", Count: 1})
+	d.FrontStyles = map[string]deck.CardLayout{
+		"front": {Name: "Front", Elements: []deck.LayoutElement{
+			{ID: "art", Name: "Art", Type: "image", X: 5, Y: 20, Width: 50, Height: 40},
+			{ID: "title", Name: "Title", Type: "text", X: 8, Y: 5, Width: 50, Height: 10},
+			{ID: "cost", Name: "Cost", Type: "text"},
+		}},
+		"special": {Name: "Special"},
+	}
+	d.BackStyles = nil
+	d.RenderedCards = []deck.RenderedCard{{StyleID: "front", Side: "front", Image: "data"}}
+	b.Decks = append(b.Decks[:1], deck.Deck{ID: "potions", Name: "Potions"})
+
+	diff := Compare(a, b)
+	if want := []ValueChange{{Key: "name", Old: "Test", New: "Renamed"}}; !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("Compare() game changes = %+v, want %+v", diff.Changes, want)
+	}
+	if len(diff.Decks) != 3 {
+		t.Fatalf("Compare() decks = %+v, want weapons changed, potions added and spells removed", diff.Decks)
+	}
+	if diff.Decks[1].ID != "potions" || diff.Decks[1].Change != Added || diff.Decks[2].ID != "spells" || diff.Decks[2].Change != Removed {
+		t.Errorf("Compare() added and removed decks = %+v, %+v", diff.Decks[1], diff.Decks[2])
+	}
+
+	weapons := diff.Decks[0]
+	if weapons.Change != Changed || !reflect.DeepEqual(weapons.Changes, []ValueChange{{Key: "width", Old: 63.5, New: 70.0}}) {
+		t.Errorf("Compare() deck = %s %+v, want the width changed", weapons.Change, weapons.Changes)
+	}
+
+	wantCards := []ItemDiff{
+		{ID: "dagger", Change: Changed, Changes: []ValueChange{
+			{Key: "data.Art", New: "images/dagger.png"},
+			{Key: "data.Cost", Old: 1.0, New: 2.0},
+		}},
+		{ID: "sword", Change: Changed, Changes: []ValueChange{{Key: "count", Old: 1.0, New: 2.0}}},
+		{ID: "bow", Change: Added},
+	}
+	if !reflect.DeepEqual(weapons.Cards, wantCards) {
+		t.Errorf("Compare() cards = %+v, want %+v", weapons.Cards, wantCards)
+	}
+
+	if len(weapons.Styles) != 3 {
+		t.Fatalf("Compare() styles = %+v, want front changed, special added and back removed", weapons.Styles)
+	}
+	front, special, back := weapons.Styles[0], weapons.Styles[1], weapons.Styles[2]
+	if special.ID != "special" || special.Change != Added || back.ID != "back" || back.Side != "back" || back.Change != Removed {
+		t.Errorf("Compare() added and removed styles = %+v, %+v", special, back)
+	}
+	wantElements := []ItemDiff{
+		{ID: "art", Name: "Art", Change: Changed, Changes: []ValueChange{{Key: "layer", Old: 1.0, New: 0.0}}},
+		{ID: "title", Name: "Title", Change: Changed, Moved: true, Changes: []ValueChange{
+			{Key: "x", Old: 5.0, New: 8.0},
+			{Key: "layer", Old: 0.0, New: 1.0},
+		}},
+		{ID: "cost", Name: "Cost", Change: Added},
+	}
+	if front.ID != "front" || front.Side != "front" || !reflect.DeepEqual(front.Elements, wantElements) {
+		t.Errorf("Compare() front elements = %+v, want %+v", front.Elements, wantElements)
+	}
+}
@@ -2,7 +2,23 @@ package game
 
 import "card_wizard/internal/deck"
 
+// FormatVersion is the game file format written by this version of the app.
+// Older files are upgraded by the migrate package when loaded.
+const FormatVersion = 2
+
 type Game struct {
-	Name  string      `json:"name"`
-	Decks []deck.Deck `json:"decks"`
+	FormatVersion int         `json:"formatVersion"`
+	Name          string      `json:"name"`
+	Decks         []deck.Deck `json:"decks"`
+	Extra         deck.Extras `json:"-"` // Fields not known to this version
+}
+
+func (g *Game) UnmarshalJSON(data []byte) error {
+	type plain Game
+	return deck.UnmarshalWithExtras(data, (*plain)(g), &g.Extra)
+}
+
+func (g Game) MarshalJSON() ([]byte, error) {
+	type plain Game
+	return deck.MarshalWithExtras(plain(g), g.Extra)
 }
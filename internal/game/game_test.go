@@ -0,0 +1,108 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// subset reports the first path in want that got is missing or holds a different value
+func subset(want, got interface{}, path string) string {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			return path
+		}
+		for key, value := range w {
+			if p := subset(value, g[key], path+"."+key); p != "" {
+				return p
+			}
+		}
+		return ""
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(g) != len(w) {
+			return path
+		}
+		for i := range w {
+			if p := subset(w[i], g[i], fmt.Sprintf("%s[%d]", path, i)); p != "" {
+				return p
+			}
+		}
+		return ""
+	default:
+		if !reflect.DeepEqual(want, got) {
+			return path
+		}
+		return ""
+	}
+}
+
+func roundTrip(t *testing.T, data []byte) (original, saved map[string]interface{}) {
+	t.Helper()
+
+	var g Game
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	out, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		t.Fatalf("json.MarshalIndent() error = %v", err)
+	}
+
+	if err := json.Unmarshal(data, &original); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(out, &saved); err != nil {
+		t.Fatal(err)
+	}
+	return original, saved
+}
+
+func TestExampleGameRoundTrip(t *testing.T) {
+	data, err := os.ReadFile("../../example_deck/example_game.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original, saved := roundTrip(t, data)
+	if p := subset(original, saved, "game"); p != "" {
+		t.Errorf("saving the example game lost or changed %s", p)
+	}
+}
+
+func TestRoundTripKeepsShapesFontsAndUnknownFields(t *testing.T) {
+	data := []byte(`{
+		"name": "Future Game",
+		"formatHint": {"writer": "card-wizard 9.0"},
+		"decks": [{
+			"id": "d1",
+			"name": "Deck",
+			"width": 63.5,
+			"height": 88.9,
+			"paperSize": "a4",
+			"customFonts": [{"name": "Fancy", "path": "fonts/fancy.ttf", "family": "Fancy", "weight": 700}],
+			"theme": "dark",
+			"fields": [{"name": "name", "type": "text", "required": true}],
+			"cards": [{"id": "c1", "data": {"name": "A"}, "count": 2, "frontStyleId": "f", "backStyleId": "b", "locked": true}],
+			"frontStyles": {"f": {"name": "Front", "tags": ["new"], "elements": [
+				{"id": "s1", "type": "shape", "field": "", "x": 1, "y": 2, "width": 30, "height": 20,
+				 "points": [{"x": 0, "y": 0}, {"x": 1, "y": 0}, {"x": 0.5, "y": 1}],
+				 "fillColor": "#ff0000", "strokeColor": "#000000", "strokeWidth": 2, "opacity": 0.5}
+			]}},
+			"backStyles": {"b": {"name": "Back", "elements": []}},
+			"defaultFrontStyleId": "f",
+			"defaultBackStyleId": "b",
+			"drawCutGuides": false,
+			"renderedCards": null
+		}]
+	}`)
+
+	original, saved := roundTrip(t, data)
+	if p := subset(original, saved, "game"); p != "" {
+		t.Errorf("round trip lost or changed %s", p)
+	}
+}
@@ -0,0 +1,106 @@
+// Package migrate upgrades game files saved by older versions of the app to the
+// current format, one version at a time.
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"card_wizard/internal/game"
+)
+
+// Format versions. Files written before formatVersion existed are detected by shape.
+const (
+	VersionSingleDeck = 1 // v0.1.x: one deck per file, card backs as colors or images
+	VersionGame       = 2 // v0.2.0+: a game holding several decks
+)
+
+// Step upgrades a decoded file from version From to From+1
+type Step struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]interface{}) (map[string]interface{}, error)
+}
+
+// steps run in order; each must upgrade exactly one version
+var steps = []Step{
+	{From: VersionSingleDeck, Description: "Converted a v0.1.x deck file into a game", Apply: singleDeckToGame},
+}
+
+// Result describes what Migrate did to a file
+type Result struct {
+	FromVersion int
+	Applied     []string // Description of each step run, in order
+	Newer       bool     // The file was saved by a newer version of the app
+}
+
+// Upgraded reports whether any migration step ran
+func (r Result) Upgraded() bool {
+	return len(r.Applied) > 0
+}
+
+// Version detects the format version of a decoded game file
+func Version(doc map[string]interface{}) int {
+	if v, ok := doc["formatVersion"].(float64); ok && v > 0 {
+		return int(v)
+	}
+	if _, ok := doc["decks"]; ok {
+		return VersionGame
+	}
+	return VersionSingleDeck
+}
+
+// Migrate upgrades raw game file JSON to game.FormatVersion. Files from a newer
+// version are returned unchanged with Result.Newer set.
+func Migrate(data []byte) ([]byte, Result, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, Result{}, fmt.Errorf("failed to parse game file: %w", err)
+	}
+	if doc == nil {
+		return nil, Result{}, fmt.Errorf("failed to parse game file: not a JSON object")
+	}
+
+	version := Version(doc)
+	result := Result{FromVersion: version}
+	if version > game.FormatVersion {
+		result.Newer = true
+		return data, result, nil
+	}
+
+	for _, step := range steps {
+		if step.From != version {
+			continue
+		}
+		next, err := step.Apply(doc)
+		if err != nil {
+			return nil, result, fmt.Errorf("failed to upgrade from format %d: %w", step.From, err)
+		}
+		doc = next
+		version = step.From + 1
+		result.Applied = append(result.Applied, step.Description)
+	}
+	if version != game.FormatVersion {
+		return nil, result, fmt.Errorf("no migration from format %d to %d", version, game.FormatVersion)
+	}
+
+	doc["formatVersion"] = game.FormatVersion
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, result, err
+	}
+	return out, result, nil
+}
+
+// Load upgrades raw game file JSON like Migrate and decodes it
+func Load(data []byte) (game.Game, Result, error) {
+	var g game.Game
+	data, result, err := Migrate(data)
+	if err != nil {
+		return g, result, err
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		return g, result, err
+	}
+	return g, result, nil
+}
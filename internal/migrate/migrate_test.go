@@ -0,0 +1,141 @@
+package migrate
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"card_wizard/internal/game"
+)
+
+func TestStepsAreOrdered(t *testing.T) {
+	for i, step := range steps {
+		if want := VersionSingleDeck + i; step.From != want {
+			t.Errorf("steps[%d].From = %d, want %d", i, step.From, want)
+		}
+	}
+	if last := steps[len(steps)-1].From + 1; last != game.FormatVersion {
+		t.Errorf("steps end at format %d, want %d", last, game.FormatVersion)
+	}
+}
+
+func TestMigrateSingleDeckFile(t *testing.T) {
+	v01 := []byte(`{
+		"name": "Old Deck",
+		"width": 63.5,
+		"height": 88.9,
+		"fields": [{"name": "name", "type": "text"}],
+		"layout": {"elements": [{"id": "title", "type": "text", "field": "name", "x": 5, "y": 5, "width": 50, "height": 10}]},
+		"cardBacks": [
+			{"id": "red", "name": "Red", "type": "color", "content": "#ff0000"},
+			{"id": "art", "name": "Art", "type": "image", "content": "images/back.png"}
+		],
+		"cards": [
+			{"id": "a", "data": {"name": "A"}, "count": 1, "backId": "art"},
+			{"id": "b", "data": {"name": "B"}, "count": 2}
+		]
+	}`)
+
+	out, result, err := Migrate(v01)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if result.FromVersion != VersionSingleDeck || !result.Upgraded() {
+		t.Errorf("Migrate() result = %+v, want an upgrade from format %d", result, VersionSingleDeck)
+	}
+
+	var g game.Game
+	if err := json.Unmarshal(out, &g); err != nil {
+		t.Fatalf("migrated file does not load: %v", err)
+	}
+	if g.FormatVersion != game.FormatVersion || g.Name != "Old Deck" || len(g.Decks) != 1 {
+		t.Fatalf("migrated game = version %d, name %q, %d decks", g.FormatVersion, g.Name, len(g.Decks))
+	}
+
+	d := g.Decks[0]
+	if d.ID != "deck-1" {
+		t.Errorf("deck ID = %q, want deck-1", d.ID)
+	}
+	if d.DefaultFrontStyleID != "default-front" || len(d.FrontStyles["default-front"].Elements) != 1 {
+		t.Errorf("layout was not moved to the default front style: %+v", d.FrontStyles)
+	}
+	if d.DefaultBackStyleID != "red" {
+		t.Errorf("DefaultBackStyleID = %q, want red", d.DefaultBackStyleID)
+	}
+	if el := d.BackStyles["red"].Elements[0]; el.Type != "shape" || el.FillColor != "#ff0000" || el.Width != 63.5 {
+		t.Errorf("color back = %+v, want a full-card red shape", el)
+	}
+	if el := d.BackStyles["art"].Elements[0]; el.Type != "image" || el.StaticText != "images/back.png" {
+		t.Errorf("image back = %+v, want a full-card image", el)
+	}
+	if d.Cards[0].BackStyleID != "art" || d.Cards[1].BackStyleID != "" {
+		t.Errorf("card back styles = %q, %q, want art and the default", d.Cards[0].BackStyleID, d.Cards[1].BackStyleID)
+	}
+	if _, ok := d.Extra["cardBacks"]; ok {
+		t.Error("cardBacks survived the migration")
+	}
+	if _, ok := d.Cards[0].Extra["backId"]; ok {
+		t.Error("backId survived the migration")
+	}
+}
+
+func TestMigrateCurrentFiles(t *testing.T) {
+	data, err := os.ReadFile("../../example_deck/example_game.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Game files from before formatVersion existed only get stamped
+	out, result, err := Migrate(data)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if result.FromVersion != VersionGame || result.Upgraded() || result.Newer {
+		t.Errorf("Migrate() result = %+v, want format %d left as is", result, VersionGame)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if Version(doc) != game.FormatVersion {
+		t.Errorf("migrated file has format %d, want %d", Version(doc), game.FormatVersion)
+	}
+}
+
+func TestMigrateNewerFile(t *testing.T) {
+	data := []byte(`{"formatVersion": 99, "name": "Future", "decks": [], "hologram": true}`)
+
+	out, result, err := Migrate(data)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if !result.Newer || result.Upgraded() {
+		t.Errorf("Migrate() result = %+v, want Newer", result)
+	}
+	if string(out) != string(data) {
+		t.Errorf("Migrate() changed a newer file: %s", out)
+	}
+}
+
+func TestMigrateInvalidJSON(t *testing.T) {
+	for _, data := range []string{`{"name": `, `null`, `[]`, `"x"`, `1`, ``} {
+		if _, _, err := Migrate([]byte(data)); err == nil {
+			t.Errorf("Migrate(%q) error = nil, want a parse error", data)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	data, err := os.ReadFile("../../example_deck/example_game.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, _, err := Load(data)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if g.FormatVersion != game.FormatVersion || len(g.Decks) == 0 {
+		t.Errorf("Load() = format %d with %d decks, want format %d with decks", g.FormatVersion, len(g.Decks), game.FormatVersion)
+	}
+}
@@ -0,0 +1,119 @@
+package migrate
+
+import "fmt"
+
+// singleDeckToGame wraps a v0.1.x deck file in a game. Those decks listed their card
+// backs as solid colors or images in cardBacks, referenced from each card by backId,
+// and had a single front layout; these become back and front styles.
+func singleDeckToGame(d map[string]interface{}) (map[string]interface{}, error) {
+	if id, _ := d["id"].(string); id == "" {
+		d["id"] = "deck-1"
+	}
+
+	if layout, ok := d["layout"].(map[string]interface{}); ok {
+		if _, exists := d["frontStyles"]; !exists {
+			if _, named := layout["name"]; !named {
+				layout["name"] = "Default Front"
+			}
+			d["frontStyles"] = map[string]interface{}{"default-front": layout}
+			d["defaultFrontStyleId"] = "default-front"
+		}
+		delete(d, "layout")
+	}
+
+	if backs, ok := d["cardBacks"].([]interface{}); ok {
+		if _, exists := d["backStyles"]; !exists {
+			styles, firstID, err := cardBacksToStyles(backs, d)
+			if err != nil {
+				return nil, err
+			}
+			d["backStyles"] = styles
+			if _, set := d["defaultBackStyleId"]; !set && firstID != "" {
+				d["defaultBackStyleId"] = firstID
+			}
+		}
+		delete(d, "cardBacks")
+	}
+
+	if cards, ok := d["cards"].([]interface{}); ok {
+		for _, c := range cards {
+			card, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if backID, ok := card["backId"]; ok {
+				if _, exists := card["backStyleId"]; !exists {
+					card["backStyleId"] = backID
+				}
+				delete(card, "backId")
+			}
+		}
+	}
+
+	name, _ := d["name"].(string)
+	return map[string]interface{}{
+		"name":  name,
+		"decks": []interface{}{d},
+	}, nil
+}
+
+// cardBacksToStyles turns v0.1.x card backs into back styles with one full-card
+// element each. Returns the styles and the first back's ID.
+func cardBacksToStyles(backs []interface{}, d map[string]interface{}) (map[string]interface{}, string, error) {
+	width, _ := d["width"].(float64)
+	height, _ := d["height"].(float64)
+
+	styles := make(map[string]interface{})
+	firstID := ""
+	for i, b := range backs {
+		back, ok := b.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("card back %d is not an object", i)
+		}
+
+		id, _ := back["id"].(string)
+		if id == "" {
+			id = fmt.Sprintf("back-%d", i+1)
+		}
+		name, _ := back["name"].(string)
+		if name == "" {
+			name = id
+		}
+		content, _ := back["content"].(string)
+
+		element := map[string]interface{}{
+			"id":     "background",
+			"name":   "Background",
+			"field":  "",
+			"x":      0.0,
+			"y":      0.0,
+			"width":  width,
+			"height": height,
+		}
+		switch back["type"] {
+		case "image":
+			element["type"] = "image"
+			element["staticText"] = content
+			element["objectFit"] = "cover"
+		default:
+			element["type"] = "shape"
+			element["points"] = []interface{}{
+				map[string]interface{}{"x": 0.0, "y": 0.0},
+				map[string]interface{}{"x": 1.0, "y": 0.0},
+				map[string]interface{}{"x": 1.0, "y": 1.0},
+				map[string]interface{}{"x": 0.0, "y": 1.0},
+			}
+			element["fillColor"] = content
+		}
+
+		styles[id] = map[string]interface{}{
+			"name":     name,
+			"elements": []interface{}{element},
+		}
+		if firstID == "" {
+			firstID = id
+		}
+	}
+
+	return styles, firstID, nil
+}
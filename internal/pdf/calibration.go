@@ -0,0 +1,182 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+
+	"card_wizard/internal/deck"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	calibrationRange = 10.0 // mm measured either side of each station
+	calibrationArm   = 14.0 // mm length of each arm of a back-side crosshair
+)
+
+// backCalibration is the shift and rotation applied to back pages to cancel the
+// printer's drift between the two sides of a sheet
+type backCalibration struct {
+	OffsetX  float64 // mm, positive moves right
+	OffsetY  float64 // mm, positive moves down
+	Rotation float64 // degrees, positive turns clockwise
+}
+
+func deckBackCalibration(d deck.Deck) backCalibration {
+	return backCalibration{OffsetX: d.BackOffsetX, OffsetY: d.BackOffsetY, Rotation: d.BackRotation}
+}
+
+// begin starts a transform moving everything drawn until end. Returns false, and
+// starts nothing, when there is no correction to apply.
+func (c backCalibration) begin(pdf *gofpdf.Fpdf, layout deck.PDFLayout) bool {
+	if c == (backCalibration{}) {
+		return false
+	}
+	pdf.TransformBegin()
+	pdf.TransformTranslate(c.OffsetX, c.OffsetY)
+	// gofpdf turns counter-clockwise
+	pdf.TransformRotate(-c.Rotation, layout.PageWidth/2, layout.PageHeight/2)
+	return true
+}
+
+// calibrationStations returns the measuring points on the front of the calibration
+// sheet: the page centre and two points on its horizontal centre line
+func calibrationStations(layout deck.PDFLayout) []point {
+	cy := layout.PageHeight / 2
+	return []point{
+		{layout.PageWidth * 0.2, cy},
+		{layout.PageWidth / 2, cy},
+		{layout.PageWidth * 0.8, cy},
+	}
+}
+
+// calibrationAxes reports which axes the back page is mirrored on for the deck's
+// duplex mode and the sheet's orientation, as planPages mirrors card backs.
+// Readings on a mirrored axis keep their sign; the others flip.
+func calibrationAxes(d deck.Deck, layout deck.PDFLayout) (mirrorX, mirrorY bool) {
+	mirrorY = duplexMode(d) == DuplexShortEdge
+	if layout.Orientation == OrientationLandscape {
+		mirrorY = !mirrorY
+	}
+	return !mirrorY, mirrorY
+}
+
+// backPosition returns where a front-side point lands when printed on the back
+func backPosition(layout deck.PDFLayout, p point, mirrorX, mirrorY bool) point {
+	if mirrorX {
+		p.X = layout.PageWidth - p.X
+	}
+	if mirrorY {
+		p.Y = layout.PageHeight - p.Y
+	}
+	return p
+}
+
+// scaleLabel converts a distance measured on the front scale into the offset to
+// enter, so users can copy the number under the back crosshair straight into the
+// deck settings
+func scaleLabel(reading, current float64, mirrored bool) float64 {
+	if mirrored {
+		return current + reading
+	}
+	return current - reading
+}
+
+// rotationPerMM returns the degrees of rotation to add per mm of difference between
+// the Y readings at the left and right stations
+func rotationPerMM(layout deck.PDFLayout, mirrorY bool) float64 {
+	stations := calibrationStations(layout)
+	span := stations[2].X - stations[0].X
+	perMM := (180 / math.Pi) / span
+	if mirrorY {
+		return -perMM
+	}
+	return perMM
+}
+
+// GenerateCalibration writes a two-page sheet for measuring the printer's
+// front-to-back drift. The front carries X and Y scales at three stations; the back
+// carries crosshairs at the same spots, shifted by the deck's current back
+// calibration. Printed double-sided and held up to a light, the scale value under
+// each crosshair is the offset to enter.
+func GenerateCalibration(d deck.Deck, outputPath string) error {
+	layout, err := CalculateLayout(d)
+	if err != nil {
+		return fmt.Errorf("failed to lay out page: %w", err)
+	}
+
+	orientation := "P"
+	if layout.Orientation == OrientationLandscape {
+		orientation = "L"
+	}
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: orientation,
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: min(layout.PageWidth, layout.PageHeight), Ht: max(layout.PageWidth, layout.PageHeight)},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+
+	cal := deckBackCalibration(d)
+	mirrorX, mirrorY := calibrationAxes(d, layout)
+	stations := calibrationStations(layout)
+
+	// Front: scales at each station
+	pdf.AddPage()
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetLineWidth(markLineWidth)
+	pdf.SetFont("Helvetica", "", 6)
+	for _, s := range stations {
+		for mm := -calibrationRange; mm <= calibrationRange; mm++ {
+			tick := 1.5
+			if math.Mod(mm, 5) == 0 {
+				tick = 3
+			}
+			// X scale runs along the horizontal line, Y scale along the vertical
+			pdf.Line(s.X+mm, s.Y-tick, s.X+mm, s.Y)
+			pdf.Line(s.X, s.Y+mm, s.X+tick, s.Y+mm)
+			if math.Mod(mm, 5) == 0 && mm != 0 {
+				xLabel := fmt.Sprintf("%g", scaleLabel(mm, cal.OffsetX, mirrorX))
+				pdf.Text(s.X+mm-pdf.GetStringWidth(xLabel)/2, s.Y-4, xLabel)
+				yLabel := fmt.Sprintf("%g", scaleLabel(mm, cal.OffsetY, mirrorY))
+				pdf.Text(s.X+4, s.Y+mm+1, yLabel)
+			}
+		}
+		pdf.Line(s.X-calibrationRange, s.Y, s.X+calibrationRange, s.Y)
+		pdf.Line(s.X, s.Y-calibrationRange, s.X, s.Y+calibrationRange)
+	}
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.Text(15, 20, "Printer calibration - front")
+	pdf.SetFont("Helvetica", "", 8)
+	instructions := []string{
+		"Print this sheet double-sided with the duplex setting you use for cards, then hold it up to a light.",
+		"At the centre station, read the X and Y scale values under the back-side crosshair and enter them",
+		"as the back X and Y offsets. Values already include the current offsets.",
+		fmt.Sprintf("Rotation: add (left Y - right Y) x %.4f degrees to the current back rotation (%g).", rotationPerMM(layout, mirrorY), cal.Rotation),
+		fmt.Sprintf("Current back offsets: X %gmm, Y %gmm.", cal.OffsetX, cal.OffsetY),
+	}
+	for i, text := range instructions {
+		pdf.Text(15, 27+float64(i)*4, text)
+	}
+
+	// Back: crosshairs behind each station, corrected like every other back page
+	pdf.AddPage()
+	transformed := cal.begin(pdf, layout)
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetLineWidth(markLineWidth)
+	for _, s := range stations {
+		b := backPosition(layout, s, mirrorX, mirrorY)
+		pdf.Line(b.X-calibrationArm, b.Y, b.X+calibrationArm, b.Y)
+		pdf.Line(b.X, b.Y-calibrationArm, b.X, b.Y+calibrationArm)
+		pdf.Circle(b.X, b.Y, 1, "D")
+	}
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.Text(15, 20, "Printer calibration - back")
+	if transformed {
+		pdf.TransformEnd()
+	}
+	pdf.SetLineWidth(defaultLineWidth)
+
+	return pdf.OutputFileAndClose(outputPath)
+}
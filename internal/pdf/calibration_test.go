@@ -0,0 +1,109 @@
+package pdf
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"card_wizard/internal/deck"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+func TestGenerateCalibrationWritesBothSides(t *testing.T) {
+	d := duplexDeck(DuplexLongEdge, 1)
+	d.BackOffsetX = 1.5
+	d.BackRotation = 0.3
+
+	out := filepath.Join(t.TempDir(), "calibration.pdf")
+	if err := GenerateCalibration(d, out); err != nil {
+		t.Fatalf("GenerateCalibration() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(data, []byte("/Type /Page\n")); n != 2 {
+		t.Errorf("GenerateCalibration() wrote %d pages, want 2", n)
+	}
+}
+
+func TestBackPositionMatchesCardBacks(t *testing.T) {
+	for _, mode := range []string{DuplexLongEdge, DuplexShortEdge} {
+		for _, orientation := range []string{OrientationPortrait, OrientationLandscape} {
+			t.Run(mode+" "+orientation, func(t *testing.T) {
+				d := duplexDeck(mode, 9)
+				d.Orientation = orientation
+				layout, pages := planDuplex(t, d)
+				mirrorX, mirrorY := calibrationAxes(d, layout)
+
+				// The crosshair behind a point must land where the card backs do
+				front, back := pages[0].Placements, pages[1].Placements
+				for i := range front {
+					center := point{front[i].X + layout.CardWidth/2, front[i].Y + layout.CardHeight/2}
+					got := backPosition(layout, center, mirrorX, mirrorY)
+					want := point{back[i].X + layout.CardWidth/2, back[i].Y + layout.CardHeight/2}
+					if math.Abs(got.X-want.X) > 1e-6 || math.Abs(got.Y-want.Y) > 1e-6 {
+						t.Errorf("backPosition(%v) = %v, want %v", center, got, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestScaleLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		reading  float64
+		current  float64
+		mirrored bool
+		want     float64
+	}{
+		{"Mirrored axis keeps the sign", 2, 0, true, 2},
+		{"Other axis flips the sign", 2, 0, false, -2},
+		{"Current offset is included", -1, 1.5, true, 0.5},
+		{"Current offset on the other axis", -1, 1.5, false, 2.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scaleLabel(tt.reading, tt.current, tt.mirrored); got != tt.want {
+				t.Errorf("scaleLabel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackCalibrationOnlyWhenSet(t *testing.T) {
+	layout := deck.PDFLayout{PageWidth: 215.9, PageHeight: 279.4}
+	pdf := gofpdf.New("P", "mm", "Letter", "")
+	pdf.AddPage()
+
+	if (backCalibration{}).begin(pdf, layout) {
+		t.Error("begin() with no correction started a transform")
+	}
+	if !(backCalibration{OffsetY: -0.5}).begin(pdf, layout) {
+		t.Fatal("begin() with an offset did not start a transform")
+	}
+	pdf.TransformEnd()
+	if err := pdf.Error(); err != nil {
+		t.Errorf("transform left the PDF in error: %v", err)
+	}
+}
+
+func TestGenerateWithBackCalibration(t *testing.T) {
+	d := sameStyleDeck()
+	d.BackOffsetX = -1
+	d.BackOffsetY = 0.5
+	d.BackRotation = 0.2
+
+	out := filepath.Join(t.TempDir(), "deck.pdf")
+	g := NewGenerator()
+	if err := g.Generate(d, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package pdf
+
+import (
+	"card_wizard/internal/deck"
+)
+
+// Duplex modes for deck.Deck.DuplexMode. The mirrored axes are for portrait
+// sheets; landscape sheets swap them.
+const (
+	DuplexLongEdge        = "long-edge"         // Fronts and column-mirrored backs interleaved (default)
+	DuplexShortEdge       = "short-edge"        // Fronts and row-mirrored backs interleaved
+	DuplexFrontsOnly      = "fronts-only"       // Single-sided, fronts only
+	DuplexBacksOnly       = "backs-only"        // Single-sided, backs only (mirrored for long-edge refeeding)
+	DuplexFrontsThenBacks = "fronts-then-backs" // All front pages, then all back pages for manual duplexing
+	DuplexGutterFold      = "gutter-fold"       // Front and back side by side on one page, folded down the middle
+)
+
+// duplexMode resolves the deck's duplex mode, defaulting to long-edge
+func duplexMode(d deck.Deck) string {
+	switch d.DuplexMode {
+	case DuplexShortEdge, DuplexFrontsOnly, DuplexBacksOnly, DuplexFrontsThenBacks, DuplexGutterFold:
+		return d.DuplexMode
+	}
+	return DuplexLongEdge
+}
+
+// cardsPerPage returns how many different cards fit on one page. A gutter-fold page
+// holds fronts in the left half of the grid and their backs in the right half.
+func cardsPerPage(layout deck.PDFLayout) int {
+	if layout.DuplexMode == DuplexGutterFold {
+		return (layout.CardsPerRow / 2) * layout.CardsPerCol
+	}
+	return layout.CardsPerRow * layout.CardsPerCol
+}
+
+// cellPosition returns the top-left corner of a grid cell
+func cellPosition(layout deck.PDFLayout, col, row int) (float64, float64) {
+	x := layout.MarginLeft + float64(col)*(layout.CardWidth+layout.Spacing)
+	y := layout.MarginTop + float64(row)*(layout.CardHeight+layout.Spacing)
+	return x, y
+}
+
+// foldLine returns the gutter-fold line, centred between the two halves of the grid
+// and running the full height of the page
+func foldLine(layout deck.PDFLayout) line {
+	gridWidth := float64(layout.CardsPerRow)*layout.CardWidth + float64(layout.CardsPerRow-1)*layout.Spacing
+	x := layout.MarginLeft + gridWidth/2
+	return line{x, 0, x, layout.PageHeight}
+}
@@ -0,0 +1,222 @@
+package pdf
+
+import (
+	"math"
+	"testing"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/render"
+)
+
+func duplexDeck(mode string, count int) deck.Deck {
+	return deck.Deck{
+		Width:       63.5,
+		Height:      88.9,
+		PaperSize:   "letter",
+		Orientation: OrientationPortrait,
+		DuplexMode:  mode,
+		Cards:       []deck.Card{{ID: "a", Count: count}},
+	}
+}
+
+func planDuplex(t *testing.T, d deck.Deck) (deck.PDFLayout, []page) {
+	t.Helper()
+	layout, err := CalculateLayout(d)
+	if err != nil {
+		t.Fatalf("CalculateLayout() error = %v", err)
+	}
+	return layout, planPages(d, layout)
+}
+
+func pageSides(pages []page) []render.Side {
+	var sides []render.Side
+	for _, p := range pages {
+		sides = append(sides, p.Side)
+	}
+	return sides
+}
+
+func sameSides(got, want []render.Side) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPlanPagesPageOrder(t *testing.T) {
+	front, back := render.SideFront, render.SideBack
+
+	tests := []struct {
+		mode string
+		want []render.Side
+	}{
+		{"", []render.Side{front, back, front, back}},
+		{DuplexLongEdge, []render.Side{front, back, front, back}},
+		{DuplexShortEdge, []render.Side{front, back, front, back}},
+		{DuplexFrontsOnly, []render.Side{front, front}},
+		{DuplexBacksOnly, []render.Side{back, back}},
+		{DuplexFrontsThenBacks, []render.Side{front, front, back, back}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			// 13 cards at 9 per page: two sheets
+			_, pages := planDuplex(t, duplexDeck(tt.mode, 13))
+			if got := pageSides(pages); !sameSides(got, tt.want) {
+				t.Errorf("planPages() sides = %v, want %v", got, tt.want)
+			}
+			for _, p := range pages {
+				for _, pl := range p.Placements {
+					if pl.Side != p.Side {
+						t.Errorf("planPages() %s page holds a %s placement", p.Side, pl.Side)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPlanPagesLongEdgeMirrorsColumns(t *testing.T) {
+	layout, pages := planDuplex(t, duplexDeck(DuplexLongEdge, 9))
+	front, back := pages[0].Placements, pages[1].Placements
+
+	for i := range front {
+		wantX := layout.PageWidth - front[i].X - layout.CardWidth
+		if math.Abs(back[i].X-wantX) > 1e-6 || back[i].Y != front[i].Y {
+			t.Errorf("back %d at (%v, %v), want (%v, %v)", i, back[i].X, back[i].Y, wantX, front[i].Y)
+		}
+	}
+}
+
+func TestPlanPagesShortEdgeMirrorsRows(t *testing.T) {
+	layout, pages := planDuplex(t, duplexDeck(DuplexShortEdge, 9))
+	front, back := pages[0].Placements, pages[1].Placements
+
+	for i := range front {
+		wantY := layout.PageHeight - front[i].Y - layout.CardHeight
+		if back[i].X != front[i].X || math.Abs(back[i].Y-wantY) > 1e-6 {
+			t.Errorf("back %d at (%v, %v), want (%v, %v)", i, back[i].X, back[i].Y, front[i].X, wantY)
+		}
+	}
+}
+
+func TestPlanPagesLandscapeSwapsMirroring(t *testing.T) {
+	tests := []struct {
+		mode       string
+		mirrorRows bool
+	}{
+		// A landscape sheet flipped over its long edge turns top to bottom
+		{DuplexLongEdge, true},
+		{DuplexShortEdge, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			d := duplexDeck(tt.mode, 8)
+			d.Orientation = OrientationLandscape
+			layout, pages := planDuplex(t, d)
+			if layout.Orientation != OrientationLandscape || layout.CardsPerRow == layout.CardsPerCol {
+				t.Fatalf("CalculateLayout() = %+v, want an uneven landscape grid", layout)
+			}
+			front, back := pages[0].Placements, pages[1].Placements
+
+			for i := range front {
+				wantX, wantY := layout.PageWidth-front[i].X-layout.CardWidth, front[i].Y
+				if tt.mirrorRows {
+					wantX, wantY = front[i].X, layout.PageHeight-front[i].Y-layout.CardHeight
+				}
+				if math.Abs(back[i].X-wantX) > 1e-6 || math.Abs(back[i].Y-wantY) > 1e-6 {
+					t.Errorf("back %d at (%v, %v), want (%v, %v)", i, back[i].X, back[i].Y, wantX, wantY)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanPagesAutoLandscapeMirrorsRows(t *testing.T) {
+	// Mini cards pick a landscape sheet by default
+	d := duplexDeck("", 1)
+	d.Width, d.Height, d.Orientation = 41, 63, ""
+	layout, pages := planDuplex(t, d)
+	if layout.Orientation != OrientationLandscape {
+		t.Fatalf("CalculateLayout() picked %s, want landscape", layout.Orientation)
+	}
+
+	front, back := pages[0].Placements[0], pages[1].Placements[0]
+	wantY := layout.PageHeight - front.Y - layout.CardHeight
+	if back.X != front.X || math.Abs(back.Y-wantY) > 1e-6 {
+		t.Errorf("back at (%v, %v), want (%v, %v)", back.X, back.Y, front.X, wantY)
+	}
+}
+
+func TestPlanPagesFrontsThenBacksKeepsSheetOrder(t *testing.T) {
+	d := duplexDeck(DuplexFrontsThenBacks, 1)
+	d.Cards = []deck.Card{{ID: "a", Count: 9}, {ID: "b", Count: 2}}
+	_, pages := planDuplex(t, d)
+
+	// The second back page must hold the cards from the second front page
+	if len(pages) != 4 {
+		t.Fatalf("planPages() returned %d pages, want 4", len(pages))
+	}
+	if got := pages[3].Placements[0].Card; got != 1 {
+		t.Errorf("second back page starts with card %d, want 1", got)
+	}
+}
+
+func TestPlanPagesGutterFold(t *testing.T) {
+	layout, pages := planDuplex(t, duplexDeck(DuplexGutterFold, 7))
+
+	if layout.CardsPerRow%2 != 0 {
+		t.Fatalf("CalculateLayout() CardsPerRow = %d, want an even number", layout.CardsPerRow)
+	}
+	perPage := cardsPerPage(layout)
+	if want := (7 + perPage - 1) / perPage; len(pages) != want {
+		t.Fatalf("planPages() returned %d pages, want %d", len(pages), want)
+	}
+
+	fold := foldLine(layout)
+	p := pages[0]
+	if !p.Fold {
+		t.Error("gutter-fold page does not draw the fold line")
+	}
+	if len(p.Placements) != 2*min(7, perPage) {
+		t.Fatalf("gutter-fold page has %d placements, want %d", len(p.Placements), 2*min(7, perPage))
+	}
+
+	// Placements come in front/back pairs reflected across the fold line
+	for i := 0; i < len(p.Placements); i += 2 {
+		f, b := p.Placements[i], p.Placements[i+1]
+		if f.Side != render.SideFront || b.Side != render.SideBack || f.Card != b.Card {
+			t.Fatalf("placements %d/%d are not a front/back pair: %+v %+v", i, i+1, f, b)
+		}
+		if f.X+layout.CardWidth > fold.X1+1e-6 {
+			t.Errorf("front %d crosses the fold line", i/2)
+		}
+		frontCenter := f.X + layout.CardWidth/2
+		backCenter := b.X + layout.CardWidth/2
+		if math.Abs((fold.X1-frontCenter)-(backCenter-fold.X1)) > 1e-6 || f.Y != b.Y {
+			t.Errorf("back %d is not opposite its front across the fold", i/2)
+		}
+	}
+}
+
+func TestCalculateLayoutGutterFoldErrors(t *testing.T) {
+	d := duplexDeck(DuplexGutterFold, 1)
+	d.Imposition = &deck.Imposition{Cols: 3}
+	if _, err := CalculateLayout(d); err == nil {
+		t.Error("CalculateLayout() with an odd forced column count: error = nil, want an error")
+	}
+
+	// A jumbo card fits only once across a portrait A5 page
+	d = duplexDeck(DuplexGutterFold, 1)
+	d.Width, d.Height = 88.9, 127
+	d.PaperSize = "a5"
+	if _, err := CalculateLayout(d); err == nil {
+		t.Error("CalculateLayout() with room for one column: error = nil, want an error")
+	}
+}
@@ -1,51 +1,181 @@
 package pdf
 
 import (
+	"fmt"
+
 	"card_wizard/internal/deck"
 )
 
-// CalculateLayout determines the optimal layout for the given deck and paper size
-func CalculateLayout(d deck.Deck) deck.PDFLayout {
-	// Determine paper size
-	paperSize := d.PaperSize
-	if paperSize == "" {
-		paperSize = "letter"
+// Grid alignments for deck.Imposition
+const (
+	AlignLeft   = "left"
+	AlignCenter = "center"
+	AlignRight  = "right"
+	AlignTop    = "top"
+	AlignMiddle = "middle"
+	AlignBottom = "bottom"
+)
+
+// fitTolerance absorbs float error when checking whether a grid fits (mm)
+const fitTolerance = 0.01
+
+// CalculateLayout determines the optimal layout for the given deck and paper size.
+// Unless the deck forces an orientation, both are tried and the one fitting more
+// cards wins (portrait on a tie). An error is returned when the deck's imposition
+// settings cannot be satisfied.
+func CalculateLayout(d deck.Deck) (deck.PDFLayout, error) {
+	paper := deckPaperSize(d)
+
+	switch d.Orientation {
+	case OrientationPortrait:
+		return layoutForPage(d, paper.Width, paper.Height, OrientationPortrait)
+	case OrientationLandscape:
+		return layoutForPage(d, paper.Height, paper.Width, OrientationLandscape)
 	}
 
-	var pageWidth, pageHeight float64
-	if paperSize == "a4" {
-		pageWidth = 210.0
-		pageHeight = 297.0
-	} else {
-		// Letter
-		pageWidth = 215.9
-		pageHeight = 279.4
+	portrait, portraitErr := layoutForPage(d, paper.Width, paper.Height, OrientationPortrait)
+	landscape, landscapeErr := layoutForPage(d, paper.Height, paper.Width, OrientationLandscape)
+	switch {
+	case portraitErr != nil && landscapeErr != nil:
+		return deck.PDFLayout{}, portraitErr
+	case portraitErr != nil:
+		return landscape, nil
+	case landscapeErr != nil:
+		return portrait, nil
+	}
+
+	if landscape.CardsPerRow*landscape.CardsPerCol > portrait.CardsPerRow*portrait.CardsPerCol {
+		return landscape, nil
+	}
+	return portrait, nil
+}
+
+// margins holds the space kept clear on each side of the page (mm)
+type margins struct {
+	Top, Right, Bottom, Left float64
+}
+
+// layoutForPage fits the deck's cards onto a page of the given (oriented) size
+func layoutForPage(d deck.Deck, pageWidth, pageHeight float64, orientation string) (deck.PDFLayout, error) {
+	var imp deck.Imposition
+	if d.Imposition != nil {
+		imp = *d.Imposition
 	}
 
-	cardWidth := d.Width
-	cardHeight := d.Height
+	// Cards are placed at their bleed size when the bleed is kept, otherwise at trim size
+	bleed := 0.0
+	if d.KeepBleed && d.Bleed > 0 {
+		bleed = d.Bleed
+	}
+	cardWidth := d.Width + (2 * bleed)
+	cardHeight := d.Height + (2 * bleed)
 
-	// Target: Fit 9 cards (3x3) for standard poker size on Letter/A4
+	// Default target: Fit 9 cards (3x3) for standard poker size on Letter/A4
 	// Strategy:
 	// 1. Try with ideal spacing (2mm) and safe margins (10mm)
 	// 2. If < 9 cards, try reducing margins (min 5mm)
 	// 3. If still < 9, try reducing spacing (min 0mm)
-	// 4. Calculate final margins to CENTER the grid
+	// 4. Position the grid, CENTERED unless an alignment is set
+	// Explicit margins, gutter or grid size from the deck's imposition replace the
+	// matching part of the heuristic. Nothing is placed inside the printer's
+	// non-printable margin.
 
 	// Configuration
-	minMargin := 5.0
-	idealMargin := 10.0
+	printerMargin := max(imp.PrinterMargin, 0)
+	minMargin := max(5.0, printerMargin)
+	idealMargin := max(10.0, printerMargin)
 	idealSpacing := 2.0
 
+	// Explicit margins must leave the non-printable area clear
+	for _, side := range []struct {
+		name  string
+		value *float64
+	}{
+		{"top", imp.MarginTop},
+		{"right", imp.MarginRight},
+		{"bottom", imp.MarginBottom},
+		{"left", imp.MarginLeft},
+	} {
+		if side.value != nil && *side.value < printerMargin {
+			return deck.PDFLayout{}, fmt.Errorf("%s margin %.1fmm is inside the printer's %.1fmm non-printable area", side.name, *side.value, printerMargin)
+		}
+	}
+	if imp.Gutter != nil && *imp.Gutter < 0 {
+		return deck.PDFLayout{}, fmt.Errorf("gutter cannot be negative")
+	}
+	if imp.Cols < 0 || imp.Rows < 0 {
+		return deck.PDFLayout{}, fmt.Errorf("rows and columns cannot be negative")
+	}
+
+	// Gutter-fold pages pair each front column with a back column
+	gutterFold := duplexMode(d) == DuplexGutterFold
+	if gutterFold && imp.Cols%2 != 0 {
+		return deck.PDFLayout{}, fmt.Errorf("gutter-fold needs an even number of columns, got %d", imp.Cols)
+	}
+
+	// Explicit values win over the heuristic's margin and spacing
+	withMargin := func(margin float64) margins {
+		m := margins{margin, margin, margin, margin}
+		if imp.MarginTop != nil {
+			m.Top = *imp.MarginTop
+		}
+		if imp.MarginRight != nil {
+			m.Right = *imp.MarginRight
+		}
+		if imp.MarginBottom != nil {
+			m.Bottom = *imp.MarginBottom
+		}
+		if imp.MarginLeft != nil {
+			m.Left = *imp.MarginLeft
+		}
+		return m
+	}
+	withSpacing := func(spacing float64) float64 {
+		if imp.Gutter != nil {
+			return *imp.Gutter
+		}
+		return spacing
+	}
+
+	type attempt struct {
+		margins margins
+		spacing float64
+	}
+	attempts := []attempt{
+		{withMargin(idealMargin), withSpacing(idealSpacing)},
+		{withMargin(minMargin), withSpacing(idealSpacing)},
+		{withMargin(minMargin), withSpacing(0)},
+	}
+
 	// Helper to calculate count
-	calc := func(margin, spacing float64) (int, int, int) {
-		printableW := pageWidth - (2 * margin)
-		printableH := pageHeight - (2 * margin)
+	calc := func(a attempt) (int, int, bool) {
+		printableW := pageWidth - a.margins.Left - a.margins.Right
+		printableH := pageHeight - a.margins.Top - a.margins.Bottom
 
 		// Solve for Cols: Cols(W+S) - S <= PrintableW -> Cols(W+S) <= PrintableW + S
-		cols := int((printableW + spacing) / (cardWidth + spacing))
-		rows := int((printableH + spacing) / (cardHeight + spacing))
+		cols := int((printableW + a.spacing + fitTolerance) / (cardWidth + a.spacing))
+		rows := int((printableH + a.spacing + fitTolerance) / (cardHeight + a.spacing))
 
+		if gutterFold && imp.Cols == 0 {
+			cols -= cols % 2
+		}
+
+		// A requested grid size must fit as-is
+		fits := true
+		if imp.Cols > 0 {
+			fits = fits && imp.Cols <= cols
+			cols = imp.Cols
+		}
+		if imp.Rows > 0 {
+			fits = fits && imp.Rows <= rows
+			rows = imp.Rows
+		}
+
+		// An automatic dimension must hold at least one card, or with gutter-fold
+		// a front and a back
+		if cols < 1 || rows < 1 {
+			fits = false
+		}
 		if cols < 1 {
 			cols = 1
 		}
@@ -53,59 +183,105 @@ func CalculateLayout(d deck.Deck) deck.PDFLayout {
 			rows = 1
 		}
 
-		return cols, rows, cols * rows
+		return cols, rows, fits
 	}
 
-	// 1. Try Ideal
-	cols, rows, count := calc(idealMargin, idealSpacing)
-
-	finalSpacing := idealSpacing
-
-	// If we want 9 cards but didn't get them (assuming standard poker size approx)
-	// Poker size is ~63x88. 3x3 = 9.
-	if count < 9 {
-		// 2. Try Reduced Margins
-		c, r, n := calc(minMargin, idealSpacing)
-		if n > count {
-			cols, rows, count = c, r, n
-			finalSpacing = idealSpacing
+	var chosen *attempt
+	var cols, rows int
+	if imp.Cols > 0 || imp.Rows > 0 {
+		// Use the roomiest attempt that fits the requested grid, tightening
+		// further only if that adds cards along an auto dimension
+		for i := range attempts {
+			if c, r, fits := calc(attempts[i]); fits && (chosen == nil || c*r > cols*rows) {
+				chosen, cols, rows = &attempts[i], c, r
+			}
 		}
+	} else {
+		// 1. Try Ideal
+		chosen = &attempts[0]
+		var fits bool
+		cols, rows, fits = calc(*chosen)
 
-		// 3. If still < 9, Try Reduced Spacing + Reduced Margins
-		if count < 9 {
-			c, r, n := calc(minMargin, 0)
-			if n > count {
-				cols, rows, count = c, r, n
-				finalSpacing = 0
+		// If we want 9 cards but didn't get them (assuming standard poker size approx)
+		// Poker size is ~63x88. 3x3 = 9.
+		for i := 1; i < len(attempts) && (!fits || cols*rows < 9); i++ {
+			// 2. Try Reduced Margins, then 3. Reduced Spacing + Reduced Margins
+			if c, r, ok := calc(attempts[i]); ok && (!fits || c*r > cols*rows) {
+				chosen, cols, rows, fits = &attempts[i], c, r, true
 			}
 		}
+		if !fits {
+			chosen = nil
+		}
+	}
+	if chosen == nil {
+		last := attempts[len(attempts)-1]
+		c, r, _ := calc(last)
+		if gutterFold && c < 2 {
+			return deck.PDFLayout{}, fmt.Errorf("gutter-fold needs room for a front and a back side by side")
+		}
+		needW := float64(c)*cardWidth + float64(c-1)*last.spacing
+		needH := float64(r)*cardHeight + float64(r-1)*last.spacing
+		availW := pageWidth - last.margins.Left - last.margins.Right
+		availH := pageHeight - last.margins.Top - last.margins.Bottom
+		return deck.PDFLayout{}, fmt.Errorf("a %d x %d grid needs %.1fmm x %.1fmm but only %.1fmm x %.1fmm fits inside the margins", c, r, needW, needH, availW, availH)
 	}
 
-	// 4. Calculate Centering Margins
+	if gutterFold && cols < 2 {
+		return deck.PDFLayout{}, fmt.Errorf("gutter-fold needs room for a front and a back side by side")
+	}
+
+	finalSpacing := chosen.spacing
+	m := chosen.margins
+
+	// 4. Position the grid inside the margins
 	// Total Width = (Cols * CardW) + ((Cols - 1) * Spacing)
 	totalGridWidth := (float64(cols) * cardWidth) + (float64(cols-1) * finalSpacing)
 	totalGridHeight := (float64(rows) * cardHeight) + (float64(rows-1) * finalSpacing)
 
-	marginLeft := (pageWidth - totalGridWidth) / 2
-	marginTop := (pageHeight - totalGridHeight) / 2
+	freeW := pageWidth - m.Left - m.Right - totalGridWidth
+	freeH := pageHeight - m.Top - m.Bottom - totalGridHeight
+
+	var marginLeft, marginTop float64
+	switch imp.HorizontalAlign {
+	case AlignLeft:
+		marginLeft = m.Left
+	case AlignRight:
+		marginLeft = m.Left + freeW
+	default:
+		marginLeft = m.Left + freeW/2
+	}
+	switch imp.VerticalAlign {
+	case AlignTop:
+		marginTop = m.Top
+	case AlignBottom:
+		marginTop = m.Top + freeH
+	default:
+		marginTop = m.Top + freeH/2
+	}
 
-	// Ensure non-negative margins (shouldn't happen due to calc logic, but safe guard)
-	if marginLeft < 0 {
-		marginLeft = 0
+	// Ensure margins stay printable (cards larger than the page are pinned to the printable corner)
+	if marginLeft < printerMargin {
+		marginLeft = printerMargin
 	}
-	if marginTop < 0 {
-		marginTop = 0
+	if marginTop < printerMargin {
+		marginTop = printerMargin
 	}
 
 	return deck.PDFLayout{
 		PageWidth:   pageWidth,
 		PageHeight:  pageHeight,
+		Orientation: orientation,
+		DuplexMode:  duplexMode(d),
 		CardsPerRow: cols,
 		CardsPerCol: rows,
 		CardWidth:   cardWidth,
 		CardHeight:  cardHeight,
+		TrimWidth:   d.Width,
+		TrimHeight:  d.Height,
+		Bleed:       bleed,
 		Spacing:     finalSpacing,
 		MarginLeft:  marginLeft,
 		MarginTop:   marginTop,
-	}
+	}, nil
 }
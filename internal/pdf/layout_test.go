@@ -2,16 +2,18 @@ package pdf
 
 import (
 	"card_wizard/internal/deck"
+	"math"
 	"testing"
 )
 
 func TestCalculateLayout(t *testing.T) {
 	tests := []struct {
-		name        string
-		deck        deck.Deck
-		wantCols    int
-		wantRows    int
-		wantSpacing float64
+		name            string
+		deck            deck.Deck
+		wantCols        int
+		wantRows        int
+		wantSpacing     float64
+		wantOrientation string
 	}{
 		{
 			name: "Standard Poker on Letter (3x3)",
@@ -20,8 +22,9 @@ func TestCalculateLayout(t *testing.T) {
 				Height:    88.9,
 				PaperSize: "letter",
 			},
-			wantCols: 3,
-			wantRows: 3,
+			wantCols:        3,
+			wantRows:        3,
+			wantOrientation: OrientationPortrait,
 		},
 		{
 			name: "Standard Poker on A4 (3x3)",
@@ -44,20 +47,118 @@ func TestCalculateLayout(t *testing.T) {
 			wantRows: 2,
 		},
 		{
-			name: "Mini Cards on Letter (4x4)",
+			name: "Mini Cards on Letter (5x3 landscape)",
 			deck: deck.Deck{
 				Width:     44.45,
 				Height:    63.5,
 				PaperSize: "letter",
 			},
-			wantCols: 4,
+			wantCols:        5,
+			wantRows:        3,
+			wantOrientation: OrientationLandscape,
+		},
+		{
+			name: "Mini Cards on Letter forced portrait (4x3)",
+			deck: deck.Deck{
+				Width:       44.45,
+				Height:      63.5,
+				PaperSize:   "letter",
+				Orientation: OrientationPortrait,
+			},
+			wantCols:        4,
+			wantRows:        3,
+			wantOrientation: OrientationPortrait,
+		},
+		{
+			name: "Poker with kept bleed on Letter (3x2 landscape)",
+			deck: deck.Deck{
+				Width:     63.5,
+				Height:    88.9,
+				Bleed:     3,
+				KeepBleed: true,
+				PaperSize: "letter",
+			},
+			wantCols:        3,
+			wantRows:        2,
+			wantOrientation: OrientationLandscape,
+		},
+		{
+			name: "Poker on A3 (6x3 landscape)",
+			deck: deck.Deck{
+				Width:     63.5,
+				Height:    88.9,
+				PaperSize: "a3",
+			},
+			wantCols:        6,
+			wantRows:        3,
+			wantOrientation: OrientationLandscape,
+		},
+		{
+			name: "Poker on A3 forced portrait (4x4)",
+			deck: deck.Deck{
+				Width:       63.5,
+				Height:      88.9,
+				PaperSize:   "a3",
+				Orientation: OrientationPortrait,
+			},
+			wantCols:        4,
+			wantRows:        4,
+			wantOrientation: OrientationPortrait,
+		},
+		{
+			name: "Poker on SRA3 (6x3 landscape)",
+			deck: deck.Deck{
+				Width:     63.5,
+				Height:    88.9,
+				PaperSize: "sra3",
+			},
+			wantCols:        6,
+			wantRows:        3,
+			wantOrientation: OrientationLandscape,
+		},
+		{
+			name: "Poker on custom 18x12in sheet (6x3 landscape)",
+			deck: deck.Deck{
+				Width:       63.5,
+				Height:      88.9,
+				PaperSize:   PaperSizeCustom,
+				PaperWidth:  457.2,
+				PaperHeight: 304.8,
+			},
+			wantCols:        6,
+			wantRows:        3,
+			wantOrientation: OrientationLandscape,
+		},
+		{
+			name: "Tarot on A5 (2x1 landscape)",
+			deck: deck.Deck{
+				Width:     70,
+				Height:    120,
+				PaperSize: "a5",
+			},
+			wantCols:        2,
+			wantRows:        1,
+			wantOrientation: OrientationLandscape,
+		},
+		{
+			name: "Poker with clipped bleed on Letter (3x3)",
+			deck: deck.Deck{
+				Width:     63.5,
+				Height:    88.9,
+				Bleed:     3,
+				PaperSize: "letter",
+			},
+			wantCols: 3,
 			wantRows: 3,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := CalculateLayout(tt.deck)
+			got, err := CalculateLayout(tt.deck)
+			if err != nil {
+				t.Fatalf("CalculateLayout() error = %v", err)
+			}
 
 			if got.CardsPerRow != tt.wantCols {
 				t.Errorf("CalculateLayout() CardsPerRow = %v, want %v", got.CardsPerRow, tt.wantCols)
@@ -65,6 +166,12 @@ func TestCalculateLayout(t *testing.T) {
 			if got.CardsPerCol != tt.wantRows {
 				t.Errorf("CalculateLayout() CardsPerCol = %v, want %v", got.CardsPerCol, tt.wantRows)
 			}
+			if tt.wantOrientation != "" && got.Orientation != tt.wantOrientation {
+				t.Errorf("CalculateLayout() Orientation = %v, want %v", got.Orientation, tt.wantOrientation)
+			}
+			if got.Orientation == OrientationLandscape && got.PageWidth < got.PageHeight {
+				t.Errorf("CalculateLayout() landscape page is %vx%v", got.PageWidth, got.PageHeight)
+			}
 
 			// Verify margins are positive
 			if got.MarginLeft < 0 {
@@ -74,11 +181,182 @@ func TestCalculateLayout(t *testing.T) {
 				t.Errorf("CalculateLayout() MarginTop is negative: %v", got.MarginTop)
 			}
 
+			// Verify trim and bleed boxes
+			if got.TrimWidth != tt.deck.Width || got.TrimHeight != tt.deck.Height {
+				t.Errorf("CalculateLayout() trim = %vx%v, want %vx%v", got.TrimWidth, got.TrimHeight, tt.deck.Width, tt.deck.Height)
+			}
+			if got.CardWidth != got.TrimWidth+2*got.Bleed || got.CardHeight != got.TrimHeight+2*got.Bleed {
+				t.Errorf("CalculateLayout() card %vx%v does not match trim plus bleed %v", got.CardWidth, got.CardHeight, got.Bleed)
+			}
+			if !tt.deck.KeepBleed && got.Bleed != 0 {
+				t.Errorf("CalculateLayout() Bleed = %v, want 0 when clipping to trim", got.Bleed)
+			}
+
 			// Verify content fits on page
-			totalW := (float64(got.CardsPerRow) * got.CardWidth) + (float64(got.CardsPerRow-1) * got.Spacing) + (2 * got.MarginLeft)
+			totalW := gridWidth(got) + (2 * got.MarginLeft)
 			if totalW > got.PageWidth+0.1 { // Allow small float error
 				t.Errorf("CalculateLayout() Total Width %v exceeds Page Width %v", totalW, got.PageWidth)
 			}
 		})
 	}
 }
+
+func gridWidth(l deck.PDFLayout) float64 {
+	return (float64(l.CardsPerRow) * l.CardWidth) + (float64(l.CardsPerRow-1) * l.Spacing)
+}
+
+func gridHeight(l deck.PDFLayout) float64 {
+	return (float64(l.CardsPerCol) * l.CardHeight) + (float64(l.CardsPerCol-1) * l.Spacing)
+}
+
+func mm(v float64) *float64 { return &v }
+
+func pokerDeck(imp *deck.Imposition) deck.Deck {
+	return deck.Deck{
+		Width:       63.5,
+		Height:      88.9,
+		PaperSize:   "letter",
+		Orientation: OrientationPortrait,
+		Imposition:  imp,
+	}
+}
+
+func gutterFold(d deck.Deck) deck.Deck {
+	d.DuplexMode = DuplexGutterFold
+	return d
+}
+
+func TestCalculateLayoutImposition(t *testing.T) {
+	const eps = 1e-6
+	near := func(a, b float64) bool { return math.Abs(a-b) < eps }
+
+	tests := []struct {
+		name  string
+		deck  deck.Deck
+		check func(t *testing.T, got deck.PDFLayout)
+	}{
+		{
+			name: "Explicit margins anchor a top-left grid",
+			deck: pokerDeck(&deck.Imposition{MarginTop: mm(15), MarginLeft: mm(20), HorizontalAlign: AlignLeft, VerticalAlign: AlignTop}),
+			check: func(t *testing.T, got deck.PDFLayout) {
+				if got.MarginLeft != 20 || got.MarginTop != 15 {
+					t.Errorf("CalculateLayout() margins = %v,%v, want 20,15", got.MarginLeft, got.MarginTop)
+				}
+			},
+		},
+		{
+			name: "Explicit zero margins are honoured",
+			deck: pokerDeck(&deck.Imposition{MarginTop: mm(0), MarginRight: mm(0), MarginBottom: mm(0), MarginLeft: mm(0), Gutter: mm(0)}),
+			check: func(t *testing.T, got deck.PDFLayout) {
+				// 215.9 / 63.5 = 3.4, 279.4 / 88.9 = 3.14
+				if got.CardsPerRow != 3 || got.CardsPerCol != 3 {
+					t.Errorf("CalculateLayout() grid = %dx%d, want 3x3", got.CardsPerRow, got.CardsPerCol)
+				}
+			},
+		},
+		{
+			name: "Gutter replaces the automatic spacing",
+			deck: pokerDeck(&deck.Imposition{Gutter: mm(5)}),
+			check: func(t *testing.T, got deck.PDFLayout) {
+				if got.Spacing != 5 {
+					t.Errorf("CalculateLayout() Spacing = %v, want 5", got.Spacing)
+				}
+				if got.MarginLeft+gridWidth(got) > got.PageWidth-5+eps {
+					t.Errorf("CalculateLayout() grid runs into the right margin")
+				}
+			},
+		},
+		{
+			name: "Forced grid smaller than the page fits",
+			deck: pokerDeck(&deck.Imposition{Rows: 2, Cols: 2}),
+			check: func(t *testing.T, got deck.PDFLayout) {
+				if got.CardsPerRow != 2 || got.CardsPerCol != 2 {
+					t.Errorf("CalculateLayout() grid = %dx%d, want 2x2", got.CardsPerRow, got.CardsPerCol)
+				}
+				// The roomiest settings fit 2x2, so the ideal spacing is kept
+				if got.Spacing != 2 {
+					t.Errorf("CalculateLayout() Spacing = %v, want 2", got.Spacing)
+				}
+				if !near(got.MarginLeft*2+gridWidth(got), got.PageWidth) {
+					t.Errorf("CalculateLayout() forced grid is not centred")
+				}
+			},
+		},
+		{
+			name: "Forced columns only",
+			deck: pokerDeck(&deck.Imposition{Cols: 1}),
+			check: func(t *testing.T, got deck.PDFLayout) {
+				if got.CardsPerRow != 1 || got.CardsPerCol != 3 {
+					t.Errorf("CalculateLayout() grid = %dx%d, want 1x3", got.CardsPerRow, got.CardsPerCol)
+				}
+			},
+		},
+		{
+			name: "Bottom-right alignment",
+			deck: pokerDeck(&deck.Imposition{MarginRight: mm(8), MarginBottom: mm(12), HorizontalAlign: AlignRight, VerticalAlign: AlignBottom}),
+			check: func(t *testing.T, got deck.PDFLayout) {
+				if !near(got.MarginLeft+gridWidth(got), got.PageWidth-8) {
+					t.Errorf("CalculateLayout() grid right edge = %v, want %v", got.MarginLeft+gridWidth(got), got.PageWidth-8)
+				}
+				if !near(got.MarginTop+gridHeight(got), got.PageHeight-12) {
+					t.Errorf("CalculateLayout() grid bottom edge = %v, want %v", got.MarginTop+gridHeight(got), got.PageHeight-12)
+				}
+			},
+		},
+		{
+			name: "Printer margin keeps the grid off the page edge",
+			deck: pokerDeck(&deck.Imposition{PrinterMargin: 12, HorizontalAlign: AlignLeft, VerticalAlign: AlignTop}),
+			check: func(t *testing.T, got deck.PDFLayout) {
+				if got.MarginLeft < 12 || got.MarginTop < 12 {
+					t.Errorf("CalculateLayout() margins = %v,%v, want at least 12", got.MarginLeft, got.MarginTop)
+				}
+				if got.MarginLeft+gridWidth(got) > got.PageWidth-12+eps || got.MarginTop+gridHeight(got) > got.PageHeight-12+eps {
+					t.Errorf("CalculateLayout() grid enters the non-printable area")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CalculateLayout(tt.deck)
+			if err != nil {
+				t.Fatalf("CalculateLayout() error = %v", err)
+			}
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestCalculateLayoutImpositionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		deck deck.Deck
+	}{
+		{"Forced grid larger than the page", pokerDeck(&deck.Imposition{Rows: 4, Cols: 4})},
+		{"Forced grid does not fit the explicit margins", pokerDeck(&deck.Imposition{Cols: 3, MarginLeft: mm(30), MarginRight: mm(30)})},
+		{"Margin inside the printer margin", pokerDeck(&deck.Imposition{MarginLeft: mm(2), PrinterMargin: 4})},
+		{"Negative gutter", pokerDeck(&deck.Imposition{Gutter: mm(-1)})},
+		{"No room for a card between explicit margins", pokerDeck(&deck.Imposition{MarginLeft: mm(80), MarginRight: mm(80)})},
+		{"Gutter-fold with room for one column", gutterFold(pokerDeck(&deck.Imposition{MarginLeft: mm(60), MarginRight: mm(60)}))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := CalculateLayout(tt.deck); err == nil {
+				t.Error("CalculateLayout() error = nil, want an error")
+			}
+		})
+	}
+
+	// With auto orientation, a grid that only fits in landscape is not an error
+	d := pokerDeck(&deck.Imposition{Rows: 2, Cols: 4})
+	d.Orientation = OrientationAuto
+	got, err := CalculateLayout(d)
+	if err != nil {
+		t.Fatalf("CalculateLayout() error = %v", err)
+	}
+	if got.Orientation != OrientationLandscape {
+		t.Errorf("CalculateLayout() Orientation = %v, want %v", got.Orientation, OrientationLandscape)
+	}
+}
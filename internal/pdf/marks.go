@@ -0,0 +1,197 @@
+package pdf
+
+import (
+	"math"
+	"slices"
+
+	"card_wizard/internal/deck"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Cut guide styles for deck.Deck.CutGuideStyle
+const (
+	CutGuideNone      = "none"
+	CutGuideOutline   = "outline"    // Dashed rectangle on the trim line of every card
+	CutGuideCropMarks = "crop-marks" // Trim lines extended into the page margins
+)
+
+const (
+	cropMarkGap      = 2.0  // mm between the card grid and the start of a crop mark
+	cropMarkLength   = 5.0  // mm, shortened when the margin is too narrow
+	minCropMark      = 1.0  // mm, shorter marks are skipped
+	registrationSize = 3.0  // mm radius of a registration target
+	markLineWidth    = 0.1  // mm, hairline for marks
+	defaultLineWidth = 0.2  // mm, gofpdf default
+	minMarginForMark = 0.25 // mm, marks never touch the page edge
+)
+
+// line is a mark segment in page coordinates (mm)
+type line struct {
+	X1, Y1, X2, Y2 float64
+}
+
+// point is a position in page coordinates (mm)
+type point struct {
+	X, Y float64
+}
+
+// cutGuideStyle resolves the deck's guide style, honouring the older DrawCutGuides flag
+func cutGuideStyle(d deck.Deck) string {
+	switch d.CutGuideStyle {
+	case CutGuideOutline, CutGuideCropMarks, CutGuideNone:
+		return d.CutGuideStyle
+	}
+	if d.DrawCutGuides {
+		return CutGuideOutline
+	}
+	return CutGuideNone
+}
+
+// gridBounds returns the area covered by the placed cards, including bleed
+func gridBounds(layout deck.PDFLayout, placements []placement) (minX, minY, maxX, maxY float64) {
+	for i, pl := range placements {
+		if i == 0 || pl.X < minX {
+			minX = pl.X
+		}
+		if i == 0 || pl.Y < minY {
+			minY = pl.Y
+		}
+		if i == 0 || pl.X+layout.CardWidth > maxX {
+			maxX = pl.X + layout.CardWidth
+		}
+		if i == 0 || pl.Y+layout.CardHeight > maxY {
+			maxY = pl.Y + layout.CardHeight
+		}
+	}
+	return minX, minY, maxX, maxY
+}
+
+// cropMarks extends every trim line on the page outward into the margins around the
+// card grid, so sheets can be cut with a paper trimmer without marks on the art
+func cropMarks(layout deck.PDFLayout, placements []placement) []line {
+	if len(placements) == 0 {
+		return nil
+	}
+
+	var xs, ys []float64
+	for _, pl := range placements {
+		xs = append(xs, pl.X+layout.Bleed, pl.X+layout.Bleed+layout.TrimWidth)
+		ys = append(ys, pl.Y+layout.Bleed, pl.Y+layout.Bleed+layout.TrimHeight)
+	}
+	slices.Sort(xs)
+	slices.Sort(ys)
+	// Adjacent cards share trim lines when there is no spacing
+	same := func(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+	xs = slices.CompactFunc(xs, same)
+	ys = slices.CompactFunc(ys, same)
+
+	minX, minY, maxX, maxY := gridBounds(layout, placements)
+
+	// markSpan returns the start and end of a mark running outward from edge, or false if there is no room
+	markSpan := func(edge, limit float64) (float64, float64, bool) {
+		dir := 1.0
+		if limit < edge {
+			dir = -1
+		}
+		start := edge + dir*cropMarkGap
+		room := (limit - start) * dir
+		length := min(cropMarkLength, room-minMarginForMark)
+		if length < minCropMark {
+			return 0, 0, false
+		}
+		return start, start + dir*length, true
+	}
+
+	var marks []line
+	for _, x := range xs {
+		if y1, y2, ok := markSpan(minY, 0); ok {
+			marks = append(marks, line{x, y1, x, y2})
+		}
+		if y1, y2, ok := markSpan(maxY, layout.PageHeight); ok {
+			marks = append(marks, line{x, y1, x, y2})
+		}
+	}
+	for _, y := range ys {
+		if x1, x2, ok := markSpan(minX, 0); ok {
+			marks = append(marks, line{x1, y, x2, y})
+		}
+		if x1, x2, ok := markSpan(maxX, layout.PageWidth); ok {
+			marks = append(marks, line{x1, y, x2, y})
+		}
+	}
+
+	return marks
+}
+
+// registrationTargets places targets centred in each page margin wide enough to hold
+// one. Targets sit on the page's centre lines and around the full card grid rather
+// than the cards placed, so they land in the same spot on both sides of a duplex
+// sheet even when its last page is partly filled.
+func registrationTargets(layout deck.PDFLayout, placements []placement) []point {
+	if len(placements) == 0 {
+		return nil
+	}
+
+	minX, minY := cellPosition(layout, 0, 0)
+	maxX, maxY := cellPosition(layout, layout.CardsPerRow-1, layout.CardsPerCol-1)
+	maxX += layout.CardWidth
+	maxY += layout.CardHeight
+	need := 2 * (registrationSize + minMarginForMark)
+	centerX := layout.PageWidth / 2
+	centerY := layout.PageHeight / 2
+
+	var targets []point
+	if minY >= need {
+		targets = append(targets, point{centerX, minY / 2})
+	}
+	if layout.PageHeight-maxY >= need {
+		targets = append(targets, point{centerX, (maxY + layout.PageHeight) / 2})
+	}
+	if minX >= need {
+		targets = append(targets, point{minX / 2, centerY})
+	}
+	if layout.PageWidth-maxX >= need {
+		targets = append(targets, point{(maxX + layout.PageWidth) / 2, centerY})
+	}
+
+	return targets
+}
+
+// drawPageMarks draws the page-level cutting aids chosen on the deck
+func drawPageMarks(pdf *gofpdf.Fpdf, d deck.Deck, layout deck.PDFLayout, placements []placement) {
+	drawCrop := cutGuideStyle(d) == CutGuideCropMarks
+	if !drawCrop && !d.RegistrationMarks {
+		return
+	}
+
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetLineWidth(markLineWidth)
+
+	if drawCrop {
+		for _, l := range cropMarks(layout, placements) {
+			pdf.Line(l.X1, l.Y1, l.X2, l.Y2)
+		}
+	}
+
+	if d.RegistrationMarks {
+		for _, t := range registrationTargets(layout, placements) {
+			pdf.Circle(t.X, t.Y, registrationSize*0.6, "D")
+			pdf.Line(t.X-registrationSize, t.Y, t.X+registrationSize, t.Y)
+			pdf.Line(t.X, t.Y-registrationSize, t.X, t.Y+registrationSize)
+		}
+	}
+
+	pdf.SetLineWidth(defaultLineWidth)
+}
+
+// drawFoldLine draws the dashed gutter-fold line down the page
+func drawFoldLine(pdf *gofpdf.Fpdf, layout deck.PDFLayout) {
+	l := foldLine(layout)
+	pdf.SetDrawColor(150, 150, 150)
+	pdf.SetLineWidth(markLineWidth)
+	pdf.SetDashPattern([]float64{3, 2}, 0)
+	pdf.Line(l.X1, l.Y1, l.X2, l.Y2)
+	pdf.SetDashPattern([]float64{}, 0)
+	pdf.SetLineWidth(defaultLineWidth)
+}
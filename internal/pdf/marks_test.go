@@ -0,0 +1,109 @@
+package pdf
+
+import (
+	"slices"
+	"testing"
+
+	"card_wizard/internal/deck"
+)
+
+func TestCropMarksStayInMargins(t *testing.T) {
+	d := deck.Deck{Width: 63.5, Height: 88.9, PaperSize: "letter", Cards: []deck.Card{{ID: "a", Count: 9}}}
+	layout, err := CalculateLayout(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := planPages(d, layout)
+
+	for _, p := range pages {
+		marks := cropMarks(layout, p.Placements)
+		// 3x3 with no spacing on Letter: 4 shared trim lines each way, marked on both sides of the grid
+		if len(marks) != 16 {
+			t.Errorf("%s page: got %d crop marks, want 16", p.Side, len(marks))
+		}
+
+		minX, minY, maxX, maxY := gridBounds(layout, p.Placements)
+		for _, m := range marks {
+			for _, pt := range []point{{m.X1, m.Y1}, {m.X2, m.Y2}} {
+				if pt.X < 0 || pt.Y < 0 || pt.X > layout.PageWidth || pt.Y > layout.PageHeight {
+					t.Errorf("crop mark %+v leaves the page", m)
+				}
+				if pt.X > minX && pt.X < maxX && pt.Y > minY && pt.Y < maxY {
+					t.Errorf("crop mark %+v is drawn on top of the cards", m)
+				}
+			}
+		}
+	}
+}
+
+func TestCropMarksFollowTrimWithBleed(t *testing.T) {
+	d := deck.Deck{Width: 63.5, Height: 88.9, Bleed: 3, KeepBleed: true, PaperSize: "letter", Cards: []deck.Card{{ID: "a", Count: 1}}}
+	layout, err := CalculateLayout(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl := planPages(d, layout)[0].Placements
+
+	trimLeft := pl[0].X + layout.Bleed
+	found := false
+	for _, m := range cropMarks(layout, pl) {
+		if m.X1 == m.X2 && m.X1 == trimLeft {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no vertical crop mark on the trim line x=%v", trimLeft)
+	}
+}
+
+func TestRegistrationTargetsNeedRoom(t *testing.T) {
+	d := deck.Deck{Width: 63.5, Height: 88.9, PaperSize: "letter", Cards: []deck.Card{{ID: "a", Count: 9}}}
+	layout, err := CalculateLayout(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl := planPages(d, layout)[0].Placements
+
+	// Letter leaves ~10mm at the sides but only ~6mm top and bottom
+	targets := registrationTargets(layout, pl)
+	if len(targets) != 2 {
+		t.Fatalf("got %d registration targets, want 2 (left and right)", len(targets))
+	}
+	for _, tg := range targets {
+		if tg.Y != layout.PageHeight/2 {
+			t.Errorf("target %+v is not on the horizontal centre line", tg)
+		}
+	}
+}
+
+func TestRegistrationTargetsMatchOnPartialSheet(t *testing.T) {
+	// Ten cards leave one card on the second sheet, whose back is mirrored to the other side
+	d := deck.Deck{Width: 63.5, Height: 88.9, PaperSize: "letter", Cards: []deck.Card{{ID: "a", Count: 10}}}
+	layout, err := CalculateLayout(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := planPages(d, layout)
+	if len(pages) != 4 {
+		t.Fatalf("planPages() = %d pages, want 4", len(pages))
+	}
+
+	full := registrationTargets(layout, pages[0].Placements)
+	for _, p := range pages[2:] {
+		if got := registrationTargets(layout, p.Placements); !slices.Equal(got, full) {
+			t.Errorf("%s targets on the partial sheet = %+v, want %+v like a full sheet", p.Side, got, full)
+		}
+	}
+}
+
+func TestCutGuideStyleFallsBackToDrawCutGuides(t *testing.T) {
+	if got := cutGuideStyle(deck.Deck{DrawCutGuides: true}); got != CutGuideOutline {
+		t.Errorf("cutGuideStyle(DrawCutGuides) = %q, want %q", got, CutGuideOutline)
+	}
+	if got := cutGuideStyle(deck.Deck{DrawCutGuides: true, CutGuideStyle: CutGuideCropMarks}); got != CutGuideCropMarks {
+		t.Errorf("cutGuideStyle(crop-marks) = %q, want %q", got, CutGuideCropMarks)
+	}
+	if got := cutGuideStyle(deck.Deck{}); got != CutGuideNone {
+		t.Errorf("cutGuideStyle(default) = %q, want %q", got, CutGuideNone)
+	}
+}
@@ -0,0 +1,74 @@
+package pdf
+
+import (
+	"strings"
+
+	"card_wizard/internal/deck"
+)
+
+// Page orientations for deck.Deck.Orientation
+const (
+	OrientationAuto      = "auto" // Pick whichever orientation fits more cards
+	OrientationPortrait  = "portrait"
+	OrientationLandscape = "landscape"
+)
+
+// PaperSizeCustom uses deck.Deck.PaperWidth/PaperHeight instead of a preset
+const PaperSizeCustom = "custom"
+
+// PaperSize is a sheet size in portrait orientation, in mm
+type PaperSize struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// paperSizes lists the preset sheets, in the order the UI shows them
+var paperSizes = []PaperSize{
+	{ID: "letter", Name: `Letter (8.5" × 11")`, Width: 215.9, Height: 279.4},
+	{ID: "legal", Name: `Legal (8.5" × 14")`, Width: 215.9, Height: 355.6},
+	{ID: "tabloid", Name: `Tabloid (11" × 17")`, Width: 279.4, Height: 431.8},
+	{ID: "12x18", Name: `12" × 18"`, Width: 304.8, Height: 457.2},
+	{ID: "a5", Name: "A5 (148mm × 210mm)", Width: 148, Height: 210},
+	{ID: "a4", Name: "A4 (210mm × 297mm)", Width: 210, Height: 297},
+	{ID: "a3", Name: "A3 (297mm × 420mm)", Width: 297, Height: 420},
+	{ID: "sra3", Name: "SRA3 (320mm × 450mm)", Width: 320, Height: 450},
+	{ID: "b5", Name: "B5 (176mm × 250mm)", Width: 176, Height: 250},
+	{ID: "b4", Name: "B4 (250mm × 353mm)", Width: 250, Height: 353},
+	{ID: "b3", Name: "B3 (353mm × 500mm)", Width: 353, Height: 500},
+}
+
+// PaperSizes returns the preset paper sizes
+func PaperSizes() []PaperSize {
+	sizes := make([]PaperSize, len(paperSizes))
+	copy(sizes, paperSizes)
+	return sizes
+}
+
+// LookupPaperSize finds a preset by ID (case-insensitive)
+func LookupPaperSize(id string) (PaperSize, bool) {
+	for _, size := range paperSizes {
+		if strings.EqualFold(size.ID, id) {
+			return size, true
+		}
+	}
+	return PaperSize{}, false
+}
+
+// deckPaperSize resolves the deck's sheet in portrait orientation, defaulting to Letter
+func deckPaperSize(d deck.Deck) PaperSize {
+	if d.PaperSize == PaperSizeCustom && d.PaperWidth > 0 && d.PaperHeight > 0 {
+		return PaperSize{
+			ID:     PaperSizeCustom,
+			Name:   "Custom",
+			Width:  min(d.PaperWidth, d.PaperHeight),
+			Height: max(d.PaperWidth, d.PaperHeight),
+		}
+	}
+	if size, ok := LookupPaperSize(d.PaperSize); ok {
+		return size
+	}
+	size, _ := LookupPaperSize("letter")
+	return size
+}
@@ -1,159 +1,317 @@
 package pdf
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
+	"image/png"
 	"strings"
 
 	"card_wizard/internal/deck"
+	"card_wizard/internal/render"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
-type GeneratorNew struct{}
+type GeneratorNew struct {
+	// Renderer draws cards the frontend did not pre-render
+	Renderer *render.Renderer
+}
 
 func NewGenerator() *GeneratorNew {
-	return &GeneratorNew{}
+	return &GeneratorNew{
+		Renderer: render.NewRenderer(render.DefaultDPI),
+	}
 }
 
-// Generate creates a PDF with precise positioning using gofpdf
-// Pages are interleaved: front1, back1, front2, back2, etc. for duplex printing
-func (g *GeneratorNew) Generate(d deck.Deck, outputPath string) error {
-	// Calculate layout
-	layout := CalculateLayout(d)
+// placement is one card side positioned on a page
+type placement struct {
+	Card int // Index into deck.Cards
+	Side render.Side
+	X    float64
+	Y    float64
+}
 
-	pageType := "Letter"
-	if layout.PageWidth == 210.0 {
-		pageType = "A4"
+// page is a single PDF page
+type page struct {
+	Side       render.Side // Side printed on the page, empty when a gutter-fold page holds both
+	Placements []placement
+	Fold       bool // Draw the gutter-fold line
+}
+
+// planPages expands cards by Count and lays them out on pages following the deck's
+// duplex mode. The default interleaves pages: front1, back1, front2, back2, etc.
+func planPages(d deck.Deck, layout deck.PDFLayout) []page {
+	// Expand cards based on Count
+	var expanded []int
+	for i, card := range d.Cards {
+		count := card.Count
+		if count < 1 {
+			count = 1
+		}
+		for j := 0; j < count; j++ {
+			expanded = append(expanded, i)
+		}
 	}
 
-	pdf := gofpdf.New("P", "mm", pageType, "")
-	pdf.SetMargins(0, 0, 0) // We handle margins manually
-	pdf.SetAutoPageBreak(false, 0)
+	perPage := cardsPerPage(layout)
+	if perPage < 1 {
+		return nil
+	}
 
-	// Register rendered card images
-	imageMap := make(map[string]string) // key: styleId-side, value: image name in PDF
+	mode := duplexMode(d)
+	// Flipping over the short edge turns the sheet top to bottom, and over the long
+	// edge left to right. A landscape sheet's long edge runs across the page, so
+	// the two swap.
+	mirrorRows := mode == DuplexShortEdge
+	if layout.Orientation == OrientationLandscape {
+		mirrorRows = !mirrorRows
+	}
 
-	for i, renderedCard := range d.RenderedCards {
-		// Decode base64 image
-		imageData := renderedCard.Image
-		if strings.HasPrefix(imageData, "data:image/png;base64,") {
-			imageData = strings.TrimPrefix(imageData, "data:image/png;base64,")
+	var fronts, backs []page
+	for i := 0; i < len(expanded); i += perPage {
+		end := i + perPage
+		if end > len(expanded) {
+			end = len(expanded)
 		}
-
-		decoded, err := base64.StdEncoding.DecodeString(imageData)
-		if err != nil {
+		pageCards := expanded[i:end]
+
+		if mode == DuplexGutterFold {
+			// Fronts fill the left half; each back sits opposite its front across the fold
+			half := layout.CardsPerRow / 2
+			fold := page{Fold: true}
+			for j, cardIdx := range pageCards {
+				row := j / half
+				col := j % half
+
+				x, y := cellPosition(layout, col, row)
+				fold.Placements = append(fold.Placements, placement{Card: cardIdx, Side: render.SideFront, X: x, Y: y})
+				x, y = cellPosition(layout, layout.CardsPerRow-1-col, row)
+				fold.Placements = append(fold.Placements, placement{Card: cardIdx, Side: render.SideBack, X: x, Y: y})
+			}
+			fronts = append(fronts, fold)
 			continue
 		}
 
-		// Register image with gofpdf
-		imageName := fmt.Sprintf("card_%s_%s_%d", renderedCard.StyleID, renderedCard.Side, i)
-		imageOpts := gofpdf.ImageOptions{
-			ImageType: "PNG",
-			ReadDpi:   true,
+		front := page{Side: render.SideFront}
+		back := page{Side: render.SideBack}
+		for j, cardIdx := range pageCards {
+			row := j / layout.CardsPerRow
+			col := j % layout.CardsPerRow
+
+			x, y := cellPosition(layout, col, row)
+			front.Placements = append(front.Placements, placement{Card: cardIdx, Side: render.SideFront, X: x, Y: y})
+
+			backCol, backRow := col, row
+			if mirrorRows {
+				backRow = layout.CardsPerCol - 1 - row
+			} else {
+				// Mirror columns for standard duplex printing (Back of Left is Right)
+				backCol = layout.CardsPerRow - 1 - col
+			}
+			x, y = cellPosition(layout, backCol, backRow)
+			back.Placements = append(back.Placements, placement{Card: cardIdx, Side: render.SideBack, X: x, Y: y})
 		}
 
-		pdf.RegisterImageOptionsReader(imageName, imageOpts, strings.NewReader(string(decoded)))
+		fronts = append(fronts, front)
+		backs = append(backs, back)
+	}
 
-		key := fmt.Sprintf("%s-%s", renderedCard.StyleID, renderedCard.Side)
-		imageMap[key] = imageName
+	switch mode {
+	case DuplexFrontsOnly, DuplexGutterFold:
+		return fronts
+	case DuplexBacksOnly:
+		return backs
+	case DuplexFrontsThenBacks:
+		return append(fronts, backs...)
 	}
 
-	// Expand cards based on Count
-	var expandedCards []deck.Card
-	for _, card := range d.Cards {
-		count := card.Count
-		if count < 1 {
-			count = 1
+	pages := make([]page, 0, len(fronts)+len(backs))
+	for i := range fronts {
+		pages = append(pages, fronts[i], backs[i])
+	}
+	return pages
+}
+
+// renderedImages indexes the PNGs pre-rendered by the frontend
+type renderedImages struct {
+	byCard  map[string][]byte // key: cardId-side
+	byStyle map[string][]byte // key: styleId-side, sent by older frontends
+}
+
+// decodeRenderedCards indexes the frontend's images. Images are matched to cards
+// by ID, so two images for the same card ID and side are an error rather than
+// one silently replacing the other.
+func decodeRenderedCards(cards []deck.RenderedCard) (renderedImages, error) {
+	images := renderedImages{
+		byCard:  make(map[string][]byte),
+		byStyle: make(map[string][]byte),
+	}
+
+	for _, renderedCard := range cards {
+		// Decode base64 image
+		imageData := strings.TrimPrefix(renderedCard.Image, "data:image/png;base64,")
+		decoded, err := base64.StdEncoding.DecodeString(imageData)
+		if err != nil {
+			return images, fmt.Errorf("rendered image for card %q %s side: %w", renderedCard.CardID, renderedCard.Side, err)
+		}
+
+		if renderedCard.CardID == "" {
+			images.byStyle[fmt.Sprintf("%s-%s", renderedCard.StyleID, renderedCard.Side)] = decoded
+			continue
 		}
-		for i := 0; i < count; i++ {
-			expandedCards = append(expandedCards, card)
+		key := fmt.Sprintf("%s-%s", renderedCard.CardID, renderedCard.Side)
+		if _, ok := images.byCard[key]; ok {
+			return images, fmt.Errorf("more than one card has the ID %q; give each card its own ID", renderedCard.CardID)
 		}
+		images.byCard[key] = decoded
 	}
 
-	cardsPerPage := layout.CardsPerRow * layout.CardsPerCol
+	return images, nil
+}
 
-	// Render pages with fronts and backs interleaved for duplex printing
-	for i := 0; i < len(expandedCards); i += cardsPerPage {
-		end := i + cardsPerPage
-		if end > len(expandedCards) {
-			end = len(expandedCards)
-		}
+// faceImage returns the PNG printed for one side of a card. An image pre-rendered
+// for that card wins, then the Go renderer, then the per-style image older
+// frontends sent. Frontend images are always trim size, so when the bleed is kept
+// the Go renderer goes first to extend backgrounds past the trim line, unless it
+// lacks a custom font the card uses. Returns nil when no image is available. The
+// bool reports whether the image includes the bleed.
+func (g *GeneratorNew) faceImage(d deck.Deck, pre renderedImages, card deck.Card, side render.Side, bleed float64) ([]byte, bool, error) {
+	data, ok := pre.byCard[fmt.Sprintf("%s-%s", card.ID, side)]
+	if ok && (bleed <= 0 || g.Renderer == nil || !g.Renderer.HasFonts(d, card, side)) {
+		return data, false, nil
+	}
 
-		pageCards := expandedCards[i:end]
+	if g.Renderer != nil {
+		img, err := g.Renderer.RenderCardWithBleed(d, card, side, bleed)
+		if err != nil {
+			return nil, false, err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, false, err
+		}
+		return buf.Bytes(), bleed > 0, nil
+	}
 
-		// Render FRONTS page
-		pdf.AddPage()
+	styleID := card.FrontStyleID
+	if side == render.SideBack {
+		styleID = card.BackStyleID
+	}
+	if styleID == "" {
+		styleID = fmt.Sprintf("default-%s", side)
+	}
+	return pre.byStyle[fmt.Sprintf("%s-%s", styleID, side)], false, nil
+}
 
-		for j, card := range pageCards {
-			row := j / layout.CardsPerRow
-			col := j % layout.CardsPerRow
+// Generate creates a PDF with precise positioning using gofpdf
+// Every card gets its own image, so cards sharing a style still print their own data.
+// When the deck keeps its bleed, cards are placed at bleed size with backgrounds
+// extended past the trim line; otherwise they are clipped to trim size.
+func (g *GeneratorNew) Generate(d deck.Deck, outputPath string) error {
+	// Calculate layout
+	layout, err := CalculateLayout(d)
+	if err != nil {
+		return fmt.Errorf("failed to lay out cards: %w", err)
+	}
 
-			x := layout.MarginLeft + float64(col)*(layout.CardWidth+layout.Spacing)
-			y := layout.MarginTop + float64(row)*(layout.CardHeight+layout.Spacing)
+	// gofpdf swaps the portrait size for landscape pages
+	orientation := "P"
+	if layout.Orientation == OrientationLandscape {
+		orientation = "L"
+	}
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: orientation,
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: min(layout.PageWidth, layout.PageHeight), Ht: max(layout.PageWidth, layout.PageHeight)},
+	})
+	pdf.SetMargins(0, 0, 0) // We handle margins manually
+	pdf.SetAutoPageBreak(false, 0)
 
-			// Get card image
-			styleID := card.FrontStyleID
-			if styleID == "" {
-				styleID = "default-front"
-			}
+	pre, err := decodeRenderedCards(d.RenderedCards)
+	if err != nil {
+		return err
+	}
 
-			key := fmt.Sprintf("%s-front", styleID)
-			if imageName, ok := imageMap[key]; ok {
-				pdf.Image(imageName, x, y, layout.CardWidth, layout.CardHeight, false, "", 0, "")
-			} else {
-				// Fallback: draw a border if image not found
-				pdf.SetDrawColor(200, 200, 200)
-				pdf.Rect(x, y, layout.CardWidth, layout.CardHeight, "D")
-			}
+	// Register each card face once, no matter how many copies are printed
+	type cardImage struct {
+		name      string // Image name in PDF, empty when no image is available
+		withBleed bool
+	}
+	imageMap := make(map[string]cardImage) // key: cardIndex-side
+	imageFor := func(cardIdx int, side render.Side) (cardImage, error) {
+		key := fmt.Sprintf("%d-%s", cardIdx, side)
+		if img, ok := imageMap[key]; ok {
+			return img, nil
+		}
 
-			// Draw cut guides if enabled
-			if d.DrawCutGuides {
-				pdf.SetDrawColor(150, 150, 150)        // Light gray
-				pdf.SetDashPattern([]float64{1, 1}, 0) // Dashed line
-				pdf.Rect(x, y, layout.CardWidth, layout.CardHeight, "D")
-				pdf.SetDashPattern([]float64{}, 0) // Reset dash
-			}
+		data, withBleed, err := g.faceImage(d, pre, d.Cards[cardIdx], side, layout.Bleed)
+		if err != nil {
+			return cardImage{}, fmt.Errorf("failed to render card %q %s side: %w", d.Cards[cardIdx].ID, side, err)
+		}
+		if data == nil {
+			imageMap[key] = cardImage{}
+			return cardImage{}, nil
 		}
 
-		// Render BACKS page (immediately after fronts for duplex)
-		pdf.AddPage()
+		imageName := fmt.Sprintf("card_%d_%s", cardIdx, side)
+		imageOpts := gofpdf.ImageOptions{
+			ImageType: "PNG",
+			ReadDpi:   true,
+		}
+		pdf.RegisterImageOptionsReader(imageName, imageOpts, bytes.NewReader(data))
+		imageMap[key] = cardImage{name: imageName, withBleed: withBleed}
+		return imageMap[key], nil
+	}
 
-		for j := 0; j < len(pageCards); j++ {
-			row := j / layout.CardsPerRow
-			col := j % layout.CardsPerRow
+	outline := cutGuideStyle(d) == CutGuideOutline
+	cal := deckBackCalibration(d)
 
-			// Mirror columns for standard duplex printing (Back of Left is Right)
-			mirroredCol := layout.CardsPerRow - 1 - col
+	for _, p := range planPages(d, layout) {
+		pdf.AddPage()
 
-			x := layout.MarginLeft + float64(mirroredCol)*(layout.CardWidth+layout.Spacing)
-			y := layout.MarginTop + float64(row)*(layout.CardHeight+layout.Spacing)
+		// Shift back pages to cancel the printer's drift, marks included
+		transformed := p.Side == render.SideBack && cal.begin(pdf, layout)
 
-			card := pageCards[j]
+		for _, pl := range p.Placements {
+			// Trim box inside the placed card
+			trimX := pl.X + layout.Bleed
+			trimY := pl.Y + layout.Bleed
 
-			// Get card image
-			styleID := card.BackStyleID
-			if styleID == "" {
-				styleID = "default-back"
+			img, err := imageFor(pl.Card, pl.Side)
+			if err != nil {
+				return err
 			}
-
-			key := fmt.Sprintf("%s-back", styleID)
-			if imageName, ok := imageMap[key]; ok {
-				pdf.Image(imageName, x, y, layout.CardWidth, layout.CardHeight, false, "", 0, "")
-			} else {
+			switch {
+			case img.name != "" && img.withBleed:
+				pdf.Image(img.name, pl.X, pl.Y, layout.CardWidth, layout.CardHeight, false, "", 0, "")
+			case img.name != "":
+				pdf.Image(img.name, trimX, trimY, layout.TrimWidth, layout.TrimHeight, false, "", 0, "")
+			default:
 				// Fallback: draw a border if image not found
 				pdf.SetDrawColor(200, 200, 200)
-				pdf.Rect(x, y, layout.CardWidth, layout.CardHeight, "D")
+				pdf.Rect(trimX, trimY, layout.TrimWidth, layout.TrimHeight, "D")
 			}
 
-			// Draw cut guides if enabled
-			if d.DrawCutGuides {
+			// Draw outline cut guides if enabled
+			if outline {
 				pdf.SetDrawColor(150, 150, 150)        // Light gray
 				pdf.SetDashPattern([]float64{1, 1}, 0) // Dashed line
-				pdf.Rect(x, y, layout.CardWidth, layout.CardHeight, "D")
+				pdf.Rect(trimX, trimY, layout.TrimWidth, layout.TrimHeight, "D")
 				pdf.SetDashPattern([]float64{}, 0) // Reset dash
 			}
 		}
+
+		if p.Fold {
+			drawFoldLine(pdf, layout)
+		}
+
+		// Crop and registration marks go in the margins, outside the card grid
+		drawPageMarks(pdf, d, layout, p.Placements)
+
+		if transformed {
+			pdf.TransformEnd()
+		}
 	}
 
 	return pdf.OutputFileAndClose(outputPath)
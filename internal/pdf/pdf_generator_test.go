@@ -0,0 +1,259 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/render"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func sameStyleDeck() deck.Deck {
+	return deck.Deck{
+		ID:     "weapon-deck",
+		Width:  63.5,
+		Height: 88.9,
+		Cards: []deck.Card{
+			{ID: "rusty-dagger", Count: 1, FrontStyleID: "weapon-front-bronze", Data: map[string]interface{}{"name": "Rusty Dagger", "cost": "2"}},
+			{ID: "wooden-club", Count: 1, FrontStyleID: "weapon-front-bronze", Data: map[string]interface{}{"name": "Wooden Club", "cost": "1"}},
+		},
+		FrontStyles: map[string]deck.CardLayout{
+			"weapon-front-bronze": {Name: "Bronze Front", Elements: []deck.LayoutElement{
+				{ID: "name", Type: "text", Field: "name", X: 5, Y: 5, Width: 53.5, Height: 10, FontSize: 16},
+				{ID: "cost", Type: "text", Field: "cost", X: 5, Y: 20, Width: 10, Height: 10, FontSize: 16},
+			}},
+		},
+		BackStyles: map[string]deck.CardLayout{
+			"default-back": {Name: "Default Back"},
+		},
+		DefaultFrontStyleID: "weapon-front-bronze",
+		DefaultBackStyleID:  "default-back",
+		PaperSize:           "letter",
+	}
+}
+
+func TestSameStyleCardsGetDistinctPageImages(t *testing.T) {
+	d := sameStyleDeck()
+	// An older frontend sent one image per style; it must not be reused for every card
+	d.RenderedCards = []deck.RenderedCard{
+		{StyleID: "weapon-front-bronze", Side: "front", Image: base64.StdEncoding.EncodeToString([]byte("style-image"))},
+	}
+
+	g := &GeneratorNew{Renderer: render.NewRenderer(72)}
+	pre, err := decodeRenderedCards(d.RenderedCards)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layout, err := CalculateLayout(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := planPages(d, layout)
+	if len(pages) == 0 || pages[0].Side != render.SideFront {
+		t.Fatalf("planPages() first page = %+v, want a front page", pages)
+	}
+	if len(pages[0].Placements) != 2 {
+		t.Fatalf("front page has %d placements, want 2", len(pages[0].Placements))
+	}
+
+	var images [][]byte
+	for _, pl := range pages[0].Placements {
+		data, _, err := g.faceImage(d, pre, d.Cards[pl.Card], pages[0].Side, 0)
+		if err != nil || data == nil {
+			t.Fatalf("faceImage() for card %s returned no image", d.Cards[pl.Card].ID)
+		}
+		images = append(images, data)
+	}
+
+	if bytes.Equal(images[0], images[1]) {
+		t.Error("two cards sharing a style produced identical page images")
+	}
+}
+
+func TestPreRenderedCardImageWins(t *testing.T) {
+	d := sameStyleDeck()
+	d.RenderedCards = []deck.RenderedCard{
+		{CardID: "rusty-dagger", StyleID: "weapon-front-bronze", Side: "front", Image: "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("dagger"))},
+		{CardID: "wooden-club", StyleID: "weapon-front-bronze", Side: "front", Image: "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("club"))},
+	}
+
+	g := &GeneratorNew{Renderer: render.NewRenderer(72)}
+	pre, err := decodeRenderedCards(d.RenderedCards)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _, _ := g.faceImage(d, pre, d.Cards[0], render.SideFront, 0); string(got) != "dagger" {
+		t.Errorf("faceImage() rusty-dagger = %q, want pre-rendered image", got)
+	}
+	if got, _, _ := g.faceImage(d, pre, d.Cards[1], render.SideFront, 0); string(got) != "club" {
+		t.Errorf("faceImage() wooden-club = %q, want pre-rendered image", got)
+	}
+}
+
+func TestGenerateRejectsDuplicateCardImages(t *testing.T) {
+	d := sameStyleDeck()
+	d.Cards[1].ID = d.Cards[0].ID
+	d.RenderedCards = []deck.RenderedCard{
+		{CardID: "rusty-dagger", Side: "front", Image: base64.StdEncoding.EncodeToString([]byte("dagger"))},
+		{CardID: "rusty-dagger", Side: "front", Image: base64.StdEncoding.EncodeToString([]byte("club"))},
+	}
+
+	g := &GeneratorNew{Renderer: render.NewRenderer(72)}
+	if err := g.Generate(d, filepath.Join(t.TempDir(), "deck.pdf")); err == nil || !strings.Contains(err.Error(), "rusty-dagger") {
+		t.Errorf("Generate() error = %v, want the shared card ID reported", err)
+	}
+}
+
+func TestGenerateReportsRenderErrors(t *testing.T) {
+	d := sameStyleDeck()
+	d.FrontStyles["weapon-front-bronze"] = deck.CardLayout{Elements: []deck.LayoutElement{
+		{ID: "art", Type: "image", StaticText: filepath.Join(t.TempDir(), "missing.png"), Width: 10, Height: 10},
+	}}
+	// An older frontend's per-style image must not stand in for a card that failed to render
+	d.RenderedCards = []deck.RenderedCard{
+		{StyleID: "weapon-front-bronze", Side: "front", Image: base64.StdEncoding.EncodeToString([]byte("style-image"))},
+	}
+
+	out := filepath.Join(t.TempDir(), "deck.pdf")
+	g := &GeneratorNew{Renderer: render.NewRenderer(72)}
+	if err := g.Generate(d, out); err == nil || !strings.Contains(err.Error(), "rusty-dagger") {
+		t.Errorf("Generate() error = %v, want the card that failed to render", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Error("Generate() wrote a PDF after a card failed to render")
+	}
+}
+
+func TestGenerateWritesPDF(t *testing.T) {
+	d := sameStyleDeck()
+	d.Cards[0].Count = 12 // Spill onto a second sheet
+
+	out := filepath.Join(t.TempDir(), "deck.pdf")
+	g := &GeneratorNew{Renderer: render.NewRenderer(72)}
+	if err := g.Generate(d, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		t.Error("Generate() output is not a PDF")
+	}
+	// 13 cards at 9 per page: two sheets, each with a fronts and a backs page
+	if n := bytes.Count(data, []byte("/Type /Page\n")); n != 4 {
+		t.Errorf("Generate() wrote %d pages, want 4", n)
+	}
+}
+
+func TestFaceImageIncludesBleed(t *testing.T) {
+	d := sameStyleDeck()
+	d.Bleed = 3
+	d.KeepBleed = true
+
+	layout, err := CalculateLayout(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &GeneratorNew{Renderer: render.NewRenderer(254)} // 10 px per mm
+
+	data, withBleed, err := g.faceImage(d, renderedImages{}, d.Cards[0], render.SideFront, layout.Bleed)
+	if err != nil || !withBleed {
+		t.Fatal("faceImage() did not render the bleed")
+	}
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 695 || cfg.Height != 949 {
+		t.Errorf("faceImage() size = %dx%d, want 695x949 (trim plus 3mm bleed)", cfg.Width, cfg.Height)
+	}
+}
+
+func TestGenerateKeepBleedRendersOverPreRenderedCards(t *testing.T) {
+	d := sameStyleDeck()
+	d.Bleed = 3
+	d.KeepBleed = true
+
+	// The frontend renders at trim size, which would leave a white band in the bleed
+	var small bytes.Buffer
+	if err := png.Encode(&small, image.NewRGBA(image.Rect(0, 0, 10, 10))); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range d.Cards {
+		d.RenderedCards = append(d.RenderedCards, deck.RenderedCard{
+			CardID: c.ID,
+			Side:   "front",
+			Image:  "data:image/png;base64," + base64.StdEncoding.EncodeToString(small.Bytes()),
+		})
+	}
+
+	out := filepath.Join(t.TempDir(), "deck.pdf")
+	g := &GeneratorNew{Renderer: render.NewRenderer(72)}
+	if err := g.Generate(d, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 69.5mm x 94.9mm at 72 DPI: trim plus 3mm bleed on each side
+	if bytes.Contains(data, []byte("/Width 10")) || !bytes.Contains(data, []byte("/Width 197")) || !bytes.Contains(data, []byte("/Height 269")) {
+		t.Error("Generate() placed the trim-size pre-rendered images instead of rendering the bleed")
+	}
+}
+
+func TestFaceImageKeepsPreRenderedCardWithoutItsFont(t *testing.T) {
+	d := sameStyleDeck()
+	d.Bleed = 3
+	d.CustomFonts = []deck.CustomFont{{Name: "Title", Path: "fonts/title.ttf", Family: "Title Font"}}
+	d.FrontStyles["weapon-front-bronze"].Elements[0].FontFamily = "'Title Font', sans-serif"
+	pre := renderedImages{byCard: map[string][]byte{"rusty-dagger-front": []byte("frontend")}}
+	g := &GeneratorNew{Renderer: render.NewRenderer(72)}
+
+	// Without the font the Go renderer would print the title in Go Sans
+	data, withBleed, err := g.faceImage(d, pre, d.Cards[0], render.SideFront, d.Bleed)
+	if err != nil || withBleed || string(data) != "frontend" {
+		t.Errorf("faceImage() without the font = %q, %v, %v, want the pre-rendered image", data, withBleed, err)
+	}
+
+	if err := g.Renderer.RegisterFont("Title Font", goregular.TTF); err != nil {
+		t.Fatal(err)
+	}
+	if _, withBleed, err := g.faceImage(d, pre, d.Cards[0], render.SideFront, d.Bleed); err != nil || !withBleed {
+		t.Errorf("faceImage() with the font = %v, %v, want a rendered image with bleed", withBleed, err)
+	}
+}
+
+func TestGenerateUsesLayoutPageSize(t *testing.T) {
+	d := sameStyleDeck()
+	d.PaperSize = "a3" // Fits more poker cards in landscape
+
+	out := filepath.Join(t.TempDir(), "deck.pdf")
+	g := &GeneratorNew{Renderer: render.NewRenderer(72)}
+	if err := g.Generate(d, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 420mm x 297mm in points
+	if !bytes.Contains(data, []byte("/MediaBox [0 0 1190.55 841.89]")) {
+		t.Error("Generate() did not write an A3 landscape page")
+	}
+}
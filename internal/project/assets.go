@@ -0,0 +1,187 @@
+package project
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+)
+
+// Asset server routes that serve local files to the frontend
+const (
+	ImageRoute = "/local-image"
+	FontRoute  = "/local-font"
+)
+
+// Content types of the files each route serves, by lowercase extension. SVG is
+// left out as it can carry scripts.
+var (
+	imageTypes = map[string]string{
+		".png":  "image/png",
+		".jpg":  "image/jpeg",
+		".jpeg": "image/jpeg",
+		".gif":  "image/gif",
+		".webp": "image/webp",
+		".bmp":  "image/bmp",
+		".avif": "image/avif",
+		".ico":  "image/x-icon",
+	}
+	fontTypes = map[string]string{
+		".ttf":   "font/ttf",
+		".otf":   "font/otf",
+		".woff":  "font/woff",
+		".woff2": "font/woff2",
+	}
+)
+
+// Reasons a file is not served
+var (
+	errAssetForbidden = errors.New("file is outside the project")
+	errAssetType      = errors.New("file type is not served")
+)
+
+// AllowFile lets the asset routes serve a single file, like a font the user
+// picked outside the project. Relative paths resolve against the open game.
+func (s *Service) AllowFile(path string) {
+	if real, err := realPath(s.ResolvePath(path)); err == nil {
+		s.mu.Lock()
+		s.allowedFiles[real] = true
+		s.mu.Unlock()
+	}
+}
+
+// allowGameFiles allows the fonts, static images and the images in image fields
+// a game refers to, which may have been picked from outside its directory
+func (s *Service) allowGameFiles(g game.Game) {
+	for _, d := range g.Decks {
+		for _, font := range d.CustomFonts {
+			s.AllowFile(font.Path)
+		}
+		for _, styles := range []map[string]deck.CardLayout{d.FrontStyles, d.BackStyles} {
+			for _, layout := range styles {
+				for _, el := range layout.Elements {
+					if el.Type == "image" && el.StaticText != "" {
+						s.AllowFile(el.StaticText)
+					}
+				}
+			}
+		}
+		for _, c := range d.Cards {
+			for _, f := range d.Fields {
+				if f.Type != deck.FieldImage && f.Type != deck.FieldIcon {
+					continue
+				}
+				if path, ok := c.Data[f.Name].(string); ok && path != "" {
+					s.AllowFile(path)
+				}
+			}
+		}
+	}
+}
+
+// AssetPath returns the file a request for path on an asset route should serve,
+// and its content type. Relative paths resolve against the open game. The file
+// must have a type the route serves and, after following symlinks, be in the
+// game's directory or be an allowed file.
+func (s *Service) AssetPath(path string, types map[string]string) (string, string, error) {
+	contentType, ok := types[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return "", "", errAssetType
+	}
+
+	var root string
+	if gameDir := s.GameDir(); gameDir != "" {
+		root, _ = realPath(gameDir)
+	}
+	inGame := func(path string) bool {
+		return root != "" && within(root, path)
+	}
+
+	requested, err := filepath.Abs(s.ResolvePath(path))
+	if err != nil {
+		return "", "", err
+	}
+	real, err := filepath.EvalSymlinks(requested)
+	if err != nil {
+		// Only say whether files inside the project exist
+		if inGame(requested) {
+			return "", "", err
+		}
+		return "", "", errAssetForbidden
+	}
+
+	s.mu.RLock()
+	allowed := s.allowedFiles[real]
+	s.mu.RUnlock()
+	if !allowed && !inGame(real) {
+		return "", "", errAssetForbidden
+	}
+
+	// A link's target must have a served type too
+	if info, err := os.Stat(real); err != nil {
+		return "", "", err
+	} else if info.IsDir() || types[strings.ToLower(filepath.Ext(real))] == "" {
+		return "", "", errAssetType
+	}
+	return real, contentType, nil
+}
+
+// AssetMiddleware serves images and fonts on ImageRoute and FontRoute through
+// AssetPath, passing other requests to next
+func (s *Service) AssetMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var types map[string]string
+		switch r.URL.Path {
+		case ImageRoute:
+			types = imageTypes
+		case FontRoute:
+			types = fontTypes
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		path := r.URL.Query().Get("path")
+		if path == "" {
+			http.Error(w, "No path specified", http.StatusBadRequest)
+			return
+		}
+
+		file, contentType, err := s.AssetPath(path, types)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			http.NotFound(w, r)
+			return
+		case errors.Is(err, errAssetType):
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeFile(w, r, file)
+	})
+}
+
+// realPath returns the absolute path of an existing file with symlinks resolved
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// within reports whether path is inside dir. Both must be clean and absolute.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
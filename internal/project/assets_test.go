@@ -0,0 +1,209 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+)
+
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAssetMiddleware(t *testing.T) {
+	root := t.TempDir()
+	gameDir := filepath.Join(root, "game")
+	outside := filepath.Join(root, "outside")
+	writeFiles(t, map[string]string{
+		filepath.Join(gameDir, "game.json"):           "{}",
+		filepath.Join(gameDir, "images", "a.png"):     "png",
+		filepath.Join(gameDir, "images", "b.JPG"):     "jpg",
+		filepath.Join(gameDir, "images", "c.webp"):    "webp",
+		filepath.Join(gameDir, "fonts", "title.ttf"):  "ttf",
+		filepath.Join(gameDir, "fonts", "body.woff2"): "woff2",
+		filepath.Join(gameDir, "notes.txt"):           "text",
+		filepath.Join(outside, "secret.png"):          "secret",
+		filepath.Join(outside, "secret.txt"):          "secret",
+		filepath.Join(outside, "picked.otf"):          "otf",
+	})
+	links := map[string]string{
+		filepath.Join(gameDir, "images", "escape.png"): filepath.Join(outside, "secret.png"),
+		filepath.Join(gameDir, "images", "linked"):     outside,
+		filepath.Join(gameDir, "images", "text.png"):   filepath.Join(gameDir, "notes.txt"),
+		filepath.Join(gameDir, "images", "inside.png"): filepath.Join(gameDir, "images", "a.png"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	svc := NewService()
+	svc.SetGamePath(filepath.Join(gameDir, "game.json"))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := svc.AssetMiddleware(next)
+
+	get := func(route, path string) *httptest.ResponseRecorder {
+		target := route
+		if path != "" {
+			target += "?path=" + url.QueryEscape(path)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	tests := []struct {
+		name        string
+		route       string
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"relative image", ImageRoute, "images/a.png", http.StatusOK, "image/png", "png"},
+		{"absolute image in game", ImageRoute, filepath.Join(gameDir, "images", "a.png"), http.StatusOK, "image/png", "png"},
+		{"uppercase extension", ImageRoute, "images/b.JPG", http.StatusOK, "image/jpeg", "jpg"},
+		{"webp", ImageRoute, "images/c.webp", http.StatusOK, "image/webp", "webp"},
+		{"font in game", FontRoute, "fonts/title.ttf", http.StatusOK, "font/ttf", "ttf"},
+		{"woff2 font", FontRoute, "fonts/body.woff2", http.StatusOK, "font/woff2", "woff2"},
+		{"link inside game", ImageRoute, "images/inside.png", http.StatusOK, "image/png", "png"},
+		{"traversal", ImageRoute, "../outside/secret.png", http.StatusForbidden, "", ""},
+		{"nested traversal", ImageRoute, "images/../../outside/secret.png", http.StatusForbidden, "", ""},
+		{"absolute outside", ImageRoute, filepath.Join(outside, "secret.png"), http.StatusForbidden, "", ""},
+		{"symlink escape", ImageRoute, "images/escape.png", http.StatusForbidden, "", ""},
+		{"symlinked directory escape", ImageRoute, "images/linked/secret.png", http.StatusForbidden, "", ""},
+		{"missing outside", ImageRoute, filepath.Join(outside, "missing.png"), http.StatusForbidden, "", ""},
+		{"missing in game", ImageRoute, "images/missing.png", http.StatusNotFound, "", ""},
+		{"game file as image", ImageRoute, "game.json", http.StatusUnsupportedMediaType, "", ""},
+		{"image as font", FontRoute, "images/a.png", http.StatusUnsupportedMediaType, "", ""},
+		{"link to other type", ImageRoute, "images/text.png", http.StatusUnsupportedMediaType, "", ""},
+		{"directory", ImageRoute, "images", http.StatusUnsupportedMediaType, "", ""},
+		{"unregistered font", FontRoute, filepath.Join(outside, "picked.otf"), http.StatusForbidden, "", ""},
+		{"no path", ImageRoute, "", http.StatusBadRequest, "", ""},
+		{"other route", "/index.html", "images/a.png", http.StatusTeapot, "", ""},
+	}
+	for _, tt := range tests {
+		rec := get(tt.route, tt.path)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.status)
+			continue
+		}
+		if tt.contentType != "" && rec.Header().Get("Content-Type") != tt.contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.name, rec.Header().Get("Content-Type"), tt.contentType)
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.name, rec.Body.String(), tt.body)
+		}
+	}
+
+	// Allowed files are served, but only with the route's types
+	svc.AllowFile(filepath.Join(outside, "picked.otf"))
+	svc.AllowFile(filepath.Join(outside, "secret.txt"))
+	if rec := get(FontRoute, filepath.Join(outside, "picked.otf")); rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "font/otf" {
+		t.Errorf("font after AllowFile: status = %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for path, want := range map[string]int{
+		filepath.Join(outside, "secret.txt"): http.StatusUnsupportedMediaType,
+		filepath.Join(outside, "secret.png"): http.StatusForbidden,
+	} {
+		if rec := get(ImageRoute, path); rec.Code != want {
+			t.Errorf("%s after AllowFile: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	// Nothing is served relative to an unsaved game
+	svc.SetGamePath("")
+	if rec := get(ImageRoute, "images/a.png"); rec.Code != http.StatusForbidden {
+		t.Errorf("relative image with no game: status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestLoadGameAllowsItsFiles(t *testing.T) {
+	gameDir := t.TempDir()
+	outside := t.TempDir()
+	font := filepath.Join(outside, "title.ttf")
+	art := filepath.Join(outside, "art.png")
+	other := filepath.Join(outside, "other.png")
+	logo := filepath.Join(outside, "logo.png")
+	writeFiles(t, map[string]string{font: "ttf", art: "png", other: "png", logo: "png"})
+
+	g := game.Game{Name: "Test", Decks: []deck.Deck{{
+		ID:          "d",
+		Name:        "D",
+		Fields:      []deck.FieldDefinition{{Name: "Art", Type: deck.FieldImage}, {Name: "Note", Type: deck.FieldText}},
+		Cards:       []deck.Card{{ID: "c", Data: map[string]interface{}{"Art": art, "Note": other}}},
+		CustomFonts: []deck.CustomFont{{Name: "Title", Path: font}},
+		BackStyles: map[string]deck.CardLayout{"back": {Elements: []deck.LayoutElement{
+			{ID: "logo", Type: "image", StaticText: logo},
+		}}},
+	}}}
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(gameDir, "game.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewService()
+	if _, _, err := svc.LoadGameFrom(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		path  string
+		types map[string]string
+		ok    bool
+	}{
+		{font, fontTypes, true},
+		{art, imageTypes, true},
+		{logo, imageTypes, true},
+		{other, imageTypes, false}, // Not in an image field
+	} {
+		_, _, err := svc.AssetPath(tt.path, tt.types)
+		if (err == nil) != tt.ok {
+			t.Errorf("AssetPath(%s) error = %v, want allowed %v", filepath.Base(tt.path), err, tt.ok)
+		}
+	}
+}
+
+func TestSaveGameDoesNotAllowItsFiles(t *testing.T) {
+	gameDir := t.TempDir()
+	secret := filepath.Join(t.TempDir(), "secret.png")
+	writeFiles(t, map[string]string{secret: "png"})
+
+	// Games come from the frontend when saved, so only files the user picked
+	// or games loaded from disk may widen what is served
+	g := game.Game{Name: "Test", Decks: []deck.Deck{{
+		ID:     "d",
+		Name:   "D",
+		Fields: []deck.FieldDefinition{{Name: "Art", Type: deck.FieldImage}},
+		Cards:  []deck.Card{{ID: "c", Data: map[string]interface{}{"Art": secret}}},
+	}}}
+	svc := NewService()
+	if err := svc.SaveGameTo(g, filepath.Join(gameDir, "game.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.AssetPath(secret, imageTypes); !errors.Is(err, errAssetForbidden) {
+		t.Errorf("AssetPath() after saving error = %v, want %v", err, errAssetForbidden)
+	}
+}
@@ -0,0 +1,222 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"card_wizard/internal/game"
+)
+
+// AutosaveInterval is how often changes to the open game are written to its
+// recovery file
+const AutosaveInterval = 30 * time.Second
+
+// recoverySuffix ends the names of recovery files
+const recoverySuffix = ".autosave"
+
+// Recovery is a copy of a game written by the autosave, with the files it
+// belongs to
+type Recovery struct {
+	GamePath   string    `json:"gamePath,omitempty"`   // Game file the changes belong to, "" for an unsaved game
+	BundlePath string    `json:"bundlePath,omitempty"` // Bundle the game was unpacked from, if any
+	SavedAt    time.Time `json:"savedAt"`
+	Game       game.Game `json:"game"`
+	File       string    `json:"-"` // Recovery file this was read from
+}
+
+// RecoveryPath returns the recovery file of the game at gamePath: a hidden file
+// next to it, or unsaved.autosave in dir for an unsaved game
+func RecoveryPath(gamePath, dir string) string {
+	if gamePath == "" {
+		return filepath.Join(dir, "unsaved"+recoverySuffix)
+	}
+	return filepath.Join(filepath.Dir(gamePath), "."+filepath.Base(gamePath)+recoverySuffix)
+}
+
+// DefaultAutosaveDir returns where recovery files of unsaved games are kept, in
+// the user's cache directory
+func DefaultAutosaveDir() (string, error) {
+	return cacheDir(autosaveCacheDir)
+}
+
+// Autosaver periodically writes the latest copy of the open game to a recovery
+// file, so changes survive a crash before they are saved
+type Autosaver struct {
+	svc *Service
+	dir string // Where recovery files of unsaved games go
+
+	mu      sync.Mutex
+	pending *Recovery // Changes not written yet
+	written string    // Last recovery file written
+}
+
+// NewAutosaver creates an autosaver for the game open in svc that keeps the
+// recovery files of unsaved games in dir
+func NewAutosaver(svc *Service, dir string) *Autosaver {
+	return &Autosaver{svc: svc, dir: dir}
+}
+
+// Update records the latest copy of the open game, to be written on the next Flush
+func (a *Autosaver) Update(g game.Game) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending = &Recovery{GamePath: a.svc.GamePath(), BundlePath: a.svc.BundlePath(), Game: g}
+}
+
+// Flush writes the latest copy of the game, if it changed since the last flush
+func (a *Autosaver) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pending == nil {
+		return nil
+	}
+
+	r := *a.pending
+	r.SavedAt = time.Now()
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	file := RecoveryPath(r.GamePath, a.dir)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := WriteFile(file, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return err
+	}
+	a.pending = nil
+	a.written = file
+	return nil
+}
+
+// Run flushes every interval until ctx is done, then flushes once more
+func (a *Autosaver) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			a.Flush()
+			return
+		case <-ticker.C:
+			// A failed write is retried with the next update
+			a.Flush()
+		}
+	}
+}
+
+// Reset forgets changes not written yet, as when another game is opened
+func (a *Autosaver) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending = nil
+}
+
+// Saved removes the recovery files the open game no longer needs once it has
+// been saved
+func (a *Autosaver) Saved() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending = nil
+
+	var errs []error
+	for _, file := range []string{RecoveryPath(a.svc.GamePath(), a.dir), a.written} {
+		if file == "" {
+			continue
+		}
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	a.written = ""
+	return errors.Join(errs...)
+}
+
+// Recovery returns the recovery file of the open game if it holds changes made
+// after the game was last saved
+func (a *Autosaver) Recovery() (Recovery, bool) {
+	r, err := ReadRecovery(RecoveryPath(a.svc.GamePath(), a.dir))
+	return r, err == nil && r.Newer()
+}
+
+// Find returns the recovery files, newest first, holding changes made after
+// their game was last saved: that of unsaved games, those next to the given
+// game files and those of opened bundles
+func (a *Autosaver) Find(gamePaths []string) []Recovery {
+	files := []string{RecoveryPath("", a.dir)}
+	for _, p := range gamePaths {
+		if !strings.EqualFold(filepath.Ext(p), BundleExt) {
+			files = append(files, RecoveryPath(p, a.dir))
+		}
+	}
+	if bundles, err := cacheDir(bundlesCacheDir); err == nil {
+		found, _ := filepath.Glob(filepath.Join(bundles, "*", "."+bundleGameFile+recoverySuffix))
+		files = append(files, found...)
+	}
+
+	var recoveries []Recovery
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+		if r, err := ReadRecovery(file); err == nil && r.Newer() {
+			recoveries = append(recoveries, r)
+		}
+	}
+	sort.Slice(recoveries, func(i, j int) bool {
+		return recoveries[i].SavedAt.After(recoveries[j].SavedAt)
+	})
+	return recoveries
+}
+
+// ReadRecovery reads a recovery file
+func ReadRecovery(file string) (Recovery, error) {
+	var r Recovery
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, err
+	}
+	r.File = file
+	return r, nil
+}
+
+// Newer reports whether the recovery holds changes made after its game, or the
+// bundle it came from, was last saved
+func (r Recovery) Newer() bool {
+	saved := r.GamePath
+	if r.BundlePath != "" {
+		saved = r.BundlePath
+	}
+	if saved == "" {
+		return true
+	}
+	info, err := os.Stat(saved)
+	return err != nil || r.SavedAt.After(info.ModTime())
+}
+
+// Restore opens the game the recovery belongs to in svc and returns the
+// recovered copy. The recovery file is kept until the game is saved.
+func (r Recovery) Restore(svc *Service) *game.Game {
+	svc.SetGamePath(r.GamePath)
+	svc.SetBundlePath(r.BundlePath)
+	svc.allowGameFiles(r.Game)
+	g := r.Game
+	return &g
+}
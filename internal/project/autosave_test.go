@@ -0,0 +1,99 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAutosave(t *testing.T) {
+	dir := t.TempDir()
+	cache := t.TempDir()
+	svc := NewService()
+	autosave := NewAutosaver(svc, cache)
+
+	// An unsaved game is recovered from the cache directory
+	g := testGame(dir)
+	autosave.Update(g)
+	if err := autosave.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	unsaved := RecoveryPath("", cache)
+	if recoveries := autosave.Find(nil); len(recoveries) != 1 || recoveries[0].File != unsaved || recoveries[0].Game.Name != "Test" {
+		t.Errorf("Find() = %+v, want the unsaved game", recoveries)
+	}
+
+	// Saving drops the unsaved recovery file
+	path := filepath.Join(dir, "game.json")
+	if err := svc.SaveGameTo(g, path); err != nil {
+		t.Fatal(err)
+	}
+	if err := autosave.Saved(); err != nil {
+		t.Fatalf("Saved() error = %v", err)
+	}
+	if _, err := os.Stat(unsaved); !os.IsNotExist(err) {
+		t.Error("Saved() kept the unsaved game's recovery file")
+	}
+
+	// Changes to a saved game go next to it, and nothing is written without changes
+	g.Name = "Changed"
+	autosave.Update(g)
+	if err := autosave.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	recoveryFile := filepath.Join(dir, ".game.json.autosave")
+	info, err := os.Stat(recoveryFile)
+	if err != nil {
+		t.Fatalf("recovery file not written next to the game: %v", err)
+	}
+	if err := autosave.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.Stat(recoveryFile); !again.ModTime().Equal(info.ModTime()) {
+		t.Error("Flush() without changes rewrote the recovery file")
+	}
+
+	r, ok := autosave.Recovery()
+	if !ok || r.Game.Name != "Changed" || r.GamePath != path {
+		t.Fatalf("Recovery() = %+v, %v, want the changed game", r, ok)
+	}
+	if recoveries := autosave.Find([]string{path, path}); len(recoveries) != 1 || recoveries[0].File != recoveryFile {
+		t.Errorf("Find() = %+v, want the saved game's recovery once", recoveries)
+	}
+
+	// A game saved after its recovery was written is not offered
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := autosave.Recovery(); ok {
+		t.Error("Recovery() offered changes older than the saved game")
+	}
+	if recoveries := autosave.Find([]string{path}); len(recoveries) != 0 {
+		t.Errorf("Find() = %+v, want nothing newer than the saved game", recoveries)
+	}
+
+	// Restoring reopens the game the changes belong to
+	opened := NewService()
+	if restored := r.Restore(opened); restored.Name != "Changed" || opened.GamePath() != path {
+		t.Errorf("Restore() = %q with path %q", restored.Name, opened.GamePath())
+	}
+
+	// Run flushes once more when stopped
+	autosave.Reset()
+	g.Name = "On exit"
+	autosave.Update(g)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		autosave.Run(ctx, time.Hour)
+		close(done)
+	}()
+	cancel()
+	<-done
+	if r, err := ReadRecovery(recoveryFile); err != nil || r.Game.Name != "On exit" {
+		t.Errorf("recovery after Run = %q, %v, want On exit", r.Game.Name, err)
+	}
+}
@@ -0,0 +1,307 @@
+package project
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+	"card_wizard/internal/migrate"
+)
+
+// BundleExt is the extension of single-file projects: a zip of the game file
+// with every image and font it uses
+const BundleExt = ".cwz"
+
+// FontsDir is the folder, next to the game file, that a bundle's fonts extract to
+const FontsDir = "fonts"
+
+// bundleGameFile is the name of the game file inside a bundle
+const bundleGameFile = "game.json"
+
+// BundleReport lists the assets a bundle was packed without
+type BundleReport struct {
+	Missing []string `json:"missing"` // Images and fonts the game uses that were not found
+	Extra   []string `json:"extra"`   // Files in the images folder that nothing uses
+}
+
+// bundlePacker collects the files of a bundle, naming each source file once
+type bundlePacker struct {
+	s       *Service
+	files   map[string][]byte // Bundle path to contents
+	names   map[string]string // Resolved source path to bundle path
+	missing map[string]bool
+}
+
+// add packs the file at p into dir and returns its path in the bundle, or p
+// when it cannot be read
+func (b *bundlePacker) add(p, dir string) string {
+	if p == "" || strings.HasPrefix(p, "data:") {
+		return p
+	}
+	src := filepath.Clean(b.s.ResolvePath(p))
+	if name, ok := b.names[src]; ok {
+		return name
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		b.missing[p] = true
+		return p
+	}
+
+	// Keep the file's name, adding a numeric suffix when another file has it
+	base := filepath.Base(src)
+	ext := filepath.Ext(base)
+	name := path.Join(dir, base)
+	for counter := 1; ; counter++ {
+		if existing, taken := b.files[name]; !taken || bytes.Equal(existing, data) {
+			break
+		}
+		name = path.Join(dir, fmt.Sprintf("%s_%d%s", strings.TrimSuffix(base, ext), counter, ext))
+	}
+	b.files[name] = data
+	b.names[src] = name
+	return name
+}
+
+// packDeck packs a deck's images and fonts, returning a copy of the deck that
+// refers to them by their bundle paths
+func (b *bundlePacker) packDeck(d deck.Deck) deck.Deck {
+	cards := make([]deck.Card, len(d.Cards))
+	for i, c := range d.Cards {
+		data := make(map[string]interface{}, len(c.Data))
+		for k, v := range c.Data {
+			data[k] = v
+		}
+		for _, f := range d.Fields {
+			if f.Type != deck.FieldImage && f.Type != deck.FieldIcon {
+				continue
+			}
+			if p, ok := data[f.Name].(string); ok {
+				data[f.Name] = b.add(p, ImagesDir)
+			}
+		}
+		c.Data = data
+		cards[i] = c
+	}
+	d.Cards = cards
+
+	d.FrontStyles = b.packStyles(d.FrontStyles)
+	d.BackStyles = b.packStyles(d.BackStyles)
+
+	fonts := make([]deck.CustomFont, len(d.CustomFonts))
+	for i, font := range d.CustomFonts {
+		font.Path = b.add(font.Path, FontsDir)
+		fonts[i] = font
+	}
+	if d.CustomFonts != nil {
+		d.CustomFonts = fonts
+	}
+	return d
+}
+
+// packStyles packs the static images of image elements, in style ID order so
+// that names are stable
+func (b *bundlePacker) packStyles(styles map[string]deck.CardLayout) map[string]deck.CardLayout {
+	if styles == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(styles))
+	for id := range styles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	packed := make(map[string]deck.CardLayout, len(styles))
+	for _, id := range ids {
+		layout := styles[id]
+		elements := make([]deck.LayoutElement, len(layout.Elements))
+		for i, el := range layout.Elements {
+			if el.Type == "image" {
+				el.StaticText = b.add(el.StaticText, ImagesDir)
+			}
+			elements[i] = el
+		}
+		if layout.Elements != nil {
+			layout.Elements = elements
+		}
+		packed[id] = layout
+	}
+	return packed
+}
+
+// SaveBundleTo validates a game and writes it to path as a bundle holding the
+// images and fonts it uses. Assets that cannot be found are left out and keep
+// their paths; images in the open game's images folder that nothing uses are
+// left out too. The open game does not change.
+func (s *Service) SaveBundleTo(g game.Game, dest string) (BundleReport, error) {
+	var report BundleReport
+	if err := ValidateGame(&g); err != nil {
+		return report, err
+	}
+
+	b := &bundlePacker{s: s, files: make(map[string][]byte), names: make(map[string]string), missing: make(map[string]bool)}
+	decks := make([]deck.Deck, len(g.Decks))
+	for i, d := range g.Decks {
+		decks[i] = b.packDeck(d)
+	}
+	g.Decks = decks
+	// A file from a newer version keeps its version, so that version does not
+	// migrate the data it wrote again
+	g.FormatVersion = max(g.FormatVersion, game.FormatVersion)
+
+	for p := range b.missing {
+		report.Missing = append(report.Missing, p)
+	}
+	sort.Strings(report.Missing)
+	if imagesDir, err := s.imagesDir(); err == nil {
+		files, _ := os.ReadDir(imagesDir)
+		for _, file := range files {
+			if _, ok := imageTypes[strings.ToLower(filepath.Ext(file.Name()))]; !ok || file.IsDir() {
+				continue
+			}
+			if _, used := b.names[filepath.Join(imagesDir, file.Name())]; !used {
+				report.Extra = append(report.Extra, path.Join(ImagesDir, file.Name()))
+			}
+		}
+	}
+
+	gameData, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return report, err
+	}
+	names := make([]string, 0, len(b.files))
+	for name := range b.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return report, WriteFile(dest, func(w io.Writer) error {
+		zw := zip.NewWriter(w)
+		if err := writeZipFile(zw, bundleGameFile, gameData); err != nil {
+			return err
+		}
+		for _, name := range names {
+			if err := writeZipFile(zw, name, b.files[name]); err != nil {
+				return err
+			}
+		}
+		return zw.Close()
+	})
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// errNotBundle is returned for zips without a game file
+var errNotBundle = errors.New("not a Card Wizard bundle: no " + bundleGameFile)
+
+// ExtractBundle unpacks the bundle at path into dir and returns the path of
+// its game file. Entries that would land outside dir are refused, as is a dir
+// that already holds a game.
+func ExtractBundle(path, dir string) (string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer zr.Close()
+
+	hasGame := false
+	for _, f := range zr.File {
+		if f.Name == bundleGameFile {
+			hasGame = true
+		}
+		if !filepath.IsLocal(filepath.FromSlash(f.Name)) {
+			return "", fmt.Errorf("bundle entry %q is outside the bundle", f.Name)
+		}
+	}
+	if !hasGame {
+		return "", errNotBundle
+	}
+	gamePath := filepath.Join(dir, bundleGameFile)
+	if _, err := os.Stat(gamePath); err == nil {
+		return "", fmt.Errorf("%s already has a %s", dir, bundleGameFile)
+	}
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			return "", fmt.Errorf("bundle entry %q is not a regular file", f.Name)
+		}
+		if err := extractZipFile(f, filepath.Join(dir, filepath.FromSlash(f.Name))); err != nil {
+			return "", fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+	}
+	return gamePath, nil
+}
+
+func extractZipFile(f *zip.File, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return WriteFile(dest, func(w io.Writer) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+}
+
+// OpenBundle extracts the bundle at path into dir and opens its game there
+func (s *Service) OpenBundle(path, dir string) (*game.Game, migrate.Result, error) {
+	gamePath, err := ExtractBundle(path, dir)
+	if err != nil {
+		return nil, migrate.Result{}, err
+	}
+	return s.LoadGameFrom(gamePath)
+}
+
+// BundleWorkDir creates a new directory in the user's cache directory to open
+// the bundle at path in
+func BundleWorkDir(path string) (string, error) {
+	base, err := cacheDir(bundlesCacheDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return os.MkdirTemp(base, name+"-")
+}
+
+// Folders of the app's cache directory
+const (
+	bundlesCacheDir  = "bundles"  // Opened bundles, one folder each
+	autosaveCacheDir = "autosave" // Recovery files of unsaved games
+)
+
+// cacheDir returns a folder of the app's directory in the user's cache
+func cacheDir(name string) (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "card_wizard", name), nil
+}
@@ -0,0 +1,124 @@
+package project
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"card_wizard/internal/deck"
+)
+
+func TestSaveAndOpenBundle(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	writeFiles(t, map[string]string{
+		filepath.Join(dir, "images", "dagger.png"): "dagger",
+		filepath.Join(dir, "images", "unused.png"): "unused",
+		filepath.Join(dir, "images", "frame.png"):  "frame",
+		filepath.Join(outside, "dagger.png"):       "other dagger",
+		filepath.Join(outside, "title.ttf"):        "ttf",
+	})
+
+	g := testGame(dir)
+	d := &g.Decks[0]
+	d.Cards[1].Data["Art"] = filepath.Join(outside, "dagger.png")
+	d.Cards = append(d.Cards, deck.Card{ID: "bow", Count: 1, Data: map[string]interface{}{"Name": "Bow", "Art": "images/gone.png"}})
+	layout := d.FrontStyles["front"]
+	layout.Elements = append(layout.Elements, deck.LayoutElement{ID: "frame", Type: "image", StaticText: "images/frame.png"})
+	d.FrontStyles["front"] = layout
+	d.CustomFonts = []deck.CustomFont{{Name: "Title", Path: filepath.Join(outside, "title.ttf"), Family: "Title"}}
+
+	svc := NewService()
+	if err := svc.SaveGameTo(g, filepath.Join(dir, "game.json")); err != nil {
+		t.Fatal(err)
+	}
+	bundle := filepath.Join(t.TempDir(), "game"+BundleExt)
+	report, err := svc.SaveBundleTo(g, bundle)
+	if err != nil {
+		t.Fatalf("SaveBundleTo() error = %v", err)
+	}
+	if strings.Join(report.Missing, ",") != "images/gone.png" || strings.Join(report.Extra, ",") != "images/unused.png" {
+		t.Errorf("SaveBundleTo() report = %+v, want images/gone.png missing and images/unused.png extra", report)
+	}
+	// The caller's game is left alone
+	if g.Decks[0].CustomFonts[0].Path != filepath.Join(outside, "title.ttf") {
+		t.Errorf("SaveBundleTo() changed the caller's font path to %q", g.Decks[0].CustomFonts[0].Path)
+	}
+
+	opened := NewService()
+	workDir := t.TempDir()
+	got, _, err := opened.OpenBundle(bundle, workDir)
+	if err != nil {
+		t.Fatalf("OpenBundle() error = %v", err)
+	}
+	if opened.GamePath() != filepath.Join(workDir, "game.json") {
+		t.Errorf("GamePath() = %q after OpenBundle", opened.GamePath())
+	}
+
+	od := got.Decks[0]
+	wants := map[string]struct{ path, content string }{
+		"card image":    {od.Cards[0].Data["Art"].(string), "dagger"},
+		"renamed image": {od.Cards[1].Data["Art"].(string), "other dagger"},
+		"layout image":  {od.FrontStyles["front"].Elements[1].StaticText, "frame"},
+		"font":          {od.CustomFonts[0].Path, "ttf"},
+		"missing image": {od.Cards[2].Data["Art"].(string), ""},
+	}
+	for name, want := range wants {
+		data, err := os.ReadFile(opened.ResolvePath(want.path))
+		if want.content == "" {
+			if err == nil {
+				t.Errorf("%s: %s was bundled", name, want.path)
+			}
+			continue
+		}
+		if err != nil || string(data) != want.content {
+			t.Errorf("%s: %s = %q, %v, want %q", name, want.path, data, err, want.content)
+		}
+	}
+	if od.Cards[1].Data["Art"] != "images/dagger_1.png" || od.CustomFonts[0].Path != "fonts/title.ttf" {
+		t.Errorf("bundled paths = %v, %v", od.Cards[1].Data["Art"], od.CustomFonts[0].Path)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "images", "unused.png")); !os.IsNotExist(err) {
+		t.Error("SaveBundleTo() packed an unused image")
+	}
+
+	// Opening into a folder that already holds a game is refused
+	if _, _, err := NewService().OpenBundle(bundle, workDir); err == nil {
+		t.Error("OpenBundle() over an existing game succeeded")
+	}
+}
+
+func TestExtractBundleRefusesEscapes(t *testing.T) {
+	for name, entries := range map[string][]string{
+		"traversal":  {"game.json", "../evil.png"},
+		"absolute":   {"game.json", "/tmp/evil.png"},
+		"no game":    {"images/a.png"},
+		"nested dir": {"game.json", "images/../../evil.png"},
+	} {
+		path := filepath.Join(t.TempDir(), "bad"+BundleExt)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zw := zip.NewWriter(f)
+		for _, entry := range entries {
+			if err := writeZipFile(zw, entry, []byte("{}")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		dir := filepath.Join(t.TempDir(), "out")
+		if _, err := ExtractBundle(path, dir); err == nil {
+			t.Errorf("%s: ExtractBundle() succeeded", name)
+		}
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("%s: ExtractBundle() wrote files", name)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package project
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+	"card_wizard/internal/pdf"
+	"card_wizard/internal/tabular"
+)
+
+// ThumbnailSize is the largest side, in pixels, of images embedded in exports
+const ThumbnailSize = 96
+
+// Excel limits sheet names to 31 characters
+const maxXLSXSheetName = 31
+
+// ExportXLSXTo writes a deck's cards as an Excel workbook, with a hidden sheet
+// describing its fields and styles so it can be imported again as is
+func (s *Service) ExportXLSXTo(d deck.Deck, w io.Writer) error {
+	return tabular.WriteXLSX(w, tabular.Workbook([]deck.Deck{d}, maxXLSXSheetName))
+}
+
+// ExportGameXLSXTo writes every deck of a game as a sheet of an Excel workbook.
+// With thumbnails, the images in image fields are embedded over their cells.
+func (s *Service) ExportGameXLSXTo(g game.Game, w io.Writer, thumbnails bool) error {
+	sheets := tabular.Workbook(g.Decks, maxXLSXSheetName)
+	if thumbnails {
+		// Workbook returns the deck sheets first, in deck order
+		for i, d := range g.Decks {
+			sheets[i].Pictures = tabular.Thumbnails(d, s.GameDir(), ThumbnailSize)
+		}
+	}
+	return tabular.WriteXLSX(w, sheets)
+}
+
+// ExportODSTo writes a deck's cards as an OpenDocument spreadsheet
+func (s *Service) ExportODSTo(d deck.Deck, w io.Writer) error {
+	return tabular.WriteODS(w, tabular.Workbook([]deck.Deck{d}, 0))
+}
+
+// ExportGameODSTo writes every deck of a game as a sheet of an OpenDocument spreadsheet
+func (s *Service) ExportGameODSTo(g game.Game, w io.Writer) error {
+	return tabular.WriteODS(w, tabular.Workbook(g.Decks, 0))
+}
+
+// GeneratePDFTo writes the print PDF for a deck to path. The deck's custom fonts
+// must load, or cards rendered in Go would print their text in a fallback font.
+func (s *Service) GeneratePDFTo(d deck.Deck, path string) error {
+	gen := pdf.NewGenerator()
+	// Cards not pre-rendered by the frontend are rendered in Go, resolving images like ResolvePath
+	gen.Renderer.BaseDir = s.GameDir()
+	for _, font := range d.CustomFonts {
+		data, err := os.ReadFile(s.ResolvePath(font.Path))
+		if err != nil {
+			return fmt.Errorf("failed to load font %s: %w", font.Family, err)
+		}
+		if err := gen.Renderer.RegisterFont(font.Family, data); err != nil {
+			return err
+		}
+	}
+	return gen.Generate(d, path)
+}
+
+// GenerateCalibrationPDFTo writes a double-sided sheet for measuring how far the
+// printer shifts back pages to path
+func (s *Service) GenerateCalibrationPDFTo(d deck.Deck, path string) error {
+	return pdf.GenerateCalibration(d, path)
+}
+
+// WriteFile writes path with write, naming the file in errors. It writes to a
+// temporary file next to path and renames it over path once complete, so a
+// crash or failed write never leaves a partial file behind.
+func WriteFile(path string, write func(io.Writer) error) error {
+	out, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := out.Name()
+	fail := func(err error) error {
+		out.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+
+	if err := write(out); err != nil {
+		return fail(err)
+	}
+	if err := out.Chmod(0644); err != nil {
+		return fail(err)
+	}
+	if err := out.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := out.Close(); err != nil {
+		return fail(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
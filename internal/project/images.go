@@ -0,0 +1,197 @@
+// Package project manages the files kept next to a game file, like the images
+// folder, for both the app and the command line.
+package project
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImagesDir is the folder, next to the game file, that project images are kept in
+const ImagesDir = "images"
+
+// SaveImage writes image data into the images folder of the game in gameDir
+// under fileName, or fileName with a numeric suffix when that is taken, and
+// returns its path relative to the game. With reuseSame, a file that already
+// holds the same data is returned instead of writing a copy.
+func SaveImage(gameDir, fileName string, data []byte, reuseSame bool) (string, error) {
+	imagesDir := filepath.Join(gameDir, ImagesDir)
+
+	// Create images directory if it doesn't exist
+	if err := os.MkdirAll(imagesDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create images directory: %w", err)
+	}
+
+	// Never overwrite an existing image on "Add"; replacing has its own method
+	destPath := filepath.Join(imagesDir, fileName)
+	ext := filepath.Ext(fileName)
+	name := strings.TrimSuffix(fileName, ext)
+	for counter := 1; ; counter++ {
+		existing, err := os.ReadFile(destPath)
+		if os.IsNotExist(err) {
+			break
+		}
+		if reuseSame && err == nil && bytes.Equal(existing, data) {
+			return filepath.ToSlash(filepath.Join(ImagesDir, fileName)), nil
+		}
+		fileName = fmt.Sprintf("%s_%d%s", name, counter, ext)
+		destPath = filepath.Join(imagesDir, fileName)
+	}
+
+	if err := os.WriteFile(destPath, data, 0644); err != nil {
+		return "", err
+	}
+
+	// Return relative path using forward slashes
+	return filepath.ToSlash(filepath.Join(ImagesDir, fileName)), nil
+}
+
+// errNoGame is returned by image operations while the game is unsaved
+var errNoGame = errors.New("no game loaded")
+
+// imagesDir returns the open game's images folder
+func (s *Service) imagesDir() (string, error) {
+	gameDir := s.GameDir()
+	if gameDir == "" {
+		return "", errNoGame
+	}
+	return filepath.Join(gameDir, ImagesDir), nil
+}
+
+// AddImage copies an image into the open game's images folder and returns its
+// path relative to the game
+func (s *Service) AddImage(srcPath string) (string, error) {
+	gameDir := s.GameDir()
+	if gameDir == "" {
+		return "", errNoGame
+	}
+	input, err := os.ReadFile(srcPath)
+	if err != nil {
+		return "", err
+	}
+	return SaveImage(gameDir, filepath.Base(srcPath), input, false)
+}
+
+// AddImages adds several images, returning the paths of those that were added
+// and an error naming those that were not
+func (s *Service) AddImages(srcPaths []string) ([]string, error) {
+	var addedPaths []string
+	var errs []string
+
+	for _, srcPath := range srcPaths {
+		path, err := s.AddImage(srcPath)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", filepath.Base(srcPath), err))
+		} else {
+			addedPaths = append(addedPaths, path)
+		}
+	}
+
+	if len(errs) > 0 {
+		return addedPaths, fmt.Errorf("some images failed to import: %s", strings.Join(errs, "; "))
+	}
+
+	return addedPaths, nil
+}
+
+// ListImages returns the file names of the images in the open game's images folder
+func (s *Service) ListImages() ([]string, error) {
+	imagesDir, err := s.imagesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create images directory if it doesn't exist; an empty list will do otherwise
+	if err := os.MkdirAll(imagesDir, 0755); err != nil {
+		return nil, nil
+	}
+
+	files, err := os.ReadDir(imagesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []string
+	for _, file := range files {
+		if !file.IsDir() {
+			ext := strings.ToLower(filepath.Ext(file.Name()))
+			if ext == ".png" || ext == ".jpg" || ext == ".jpeg" || ext == ".gif" || ext == ".webp" {
+				images = append(images, file.Name())
+			}
+		}
+	}
+
+	return images, nil
+}
+
+// DeleteImage deletes an image from the open game's images folder
+func (s *Service) DeleteImage(filename string) error {
+	imagesDir, err := s.imagesDir()
+	if err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(imagesDir, filename))
+}
+
+// ReplaceImage overwrites an image in the open game's images folder with another file
+func (s *Service) ReplaceImage(targetFilename, srcPath string) error {
+	imagesDir, err := s.imagesDir()
+	if err != nil {
+		return err
+	}
+
+	input, err := os.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+
+	return WriteFile(filepath.Join(imagesDir, targetFilename), func(w io.Writer) error {
+		_, err := w.Write(input)
+		return err
+	})
+}
+
+// ImageDataURL reads an image and returns it as a base64 data URL. Like the
+// image route, it only reads images AssetPath allows.
+func (s *Service) ImageDataURL(path string) (string, error) {
+	file, mimeType, err := s.AssetPath(path, imageTypes)
+	if err != nil {
+		return "", fmt.Errorf("image %s: %w", path, err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// SaveDataURLs writes a map of file name to base64 data URL into dir. Values
+// that are not data URLs are skipped.
+func SaveDataURLs(images map[string]string, dir string) error {
+	for filename, b64Data := range images {
+		// Data URI: "data:image/png;base64,..."
+		parts := strings.Split(b64Data, ",")
+		if len(parts) != 2 {
+			continue // Skip invalid data
+		}
+
+		dec, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return fmt.Errorf("failed to decode image %s: %w", filename, err)
+		}
+
+		path := filepath.Join(dir, filename)
+		if err := os.WriteFile(path, dec, 0644); err != nil {
+			return fmt.Errorf("failed to save image %s: %w", filename, err)
+		}
+	}
+
+	return nil
+}
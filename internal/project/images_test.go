@@ -0,0 +1,34 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveImage(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		data      string
+		reuseSame bool
+		want      string
+	}{
+		{"art.png", "one", false, "images/art.png"},
+		{"art.png", "one", false, "images/art_1.png"}, // Adding again keeps both
+		{"art.png", "one", true, "images/art.png"},    // Unless the same data may be reused
+		{"art.png", "two", true, "images/art_2.png"},
+	}
+	for _, tt := range tests {
+		got, err := SaveImage(dir, tt.name, []byte(tt.data), tt.reuseSame)
+		if err != nil || got != tt.want {
+			t.Errorf("SaveImage(%s, %s, %v) = %s, %v, want %s", tt.name, tt.data, tt.reuseSame, got, err, tt.want)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "images", "art_2.png"))
+	if err != nil || string(data) != "two" {
+		t.Errorf("images/art_2.png = %q, %v, want \"two\"", data, err)
+	}
+}
@@ -0,0 +1,140 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MaxRecentProjects is how many recently opened or saved projects are remembered
+const MaxRecentProjects = 10
+
+// RecentProject is a game file or bundle the user opened or saved
+type RecentProject struct {
+	Path     string    `json:"path"`
+	Name     string    `json:"name"`
+	OpenedAt time.Time `json:"openedAt"`
+}
+
+// RecentProjects keeps the most recent projects, newest first, in a JSON file
+type RecentProjects struct {
+	mu   sync.Mutex
+	file string
+}
+
+// NewRecentProjects keeps the recent projects list in file. With no file the
+// list is always empty.
+func NewRecentProjects(file string) *RecentProjects {
+	return &RecentProjects{file: file}
+}
+
+// DefaultRecentProjectsFile returns where the app keeps its recent projects, in
+// the user's config directory
+func DefaultRecentProjectsFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "card_wizard", "recent.json"), nil
+}
+
+// List returns the recent projects, newest first
+func (r *RecentProjects) List() ([]RecentProject, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.read()
+}
+
+// Add moves the project at path to the top of the list, forgetting the oldest
+// beyond MaxRecentProjects
+func (r *RecentProjects) Add(path, name string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	projects, err := r.read()
+	if err != nil {
+		return err
+	}
+	projects = append([]RecentProject{{Path: abs, Name: name, OpenedAt: time.Now()}}, without(projects, abs)...)
+	if len(projects) > MaxRecentProjects {
+		projects = projects[:MaxRecentProjects]
+	}
+	return r.write(projects)
+}
+
+// Remove forgets the project at path
+func (r *RecentProjects) Remove(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	projects, err := r.read()
+	if err != nil {
+		return err
+	}
+	return r.write(without(projects, path))
+}
+
+// Clear forgets every recent project
+func (r *RecentProjects) Clear() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.write(nil)
+}
+
+func (r *RecentProjects) read() ([]RecentProject, error) {
+	if r.file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(r.file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var projects []RecentProject
+	if err := json.Unmarshal(data, &projects); err != nil {
+		// A damaged list is not worth refusing to start over
+		return nil, nil
+	}
+	return projects, nil
+}
+
+func (r *RecentProjects) write(projects []RecentProject) error {
+	if r.file == "" {
+		return nil
+	}
+	if projects == nil {
+		projects = []RecentProject{}
+	}
+	data, err := json.MarshalIndent(projects, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.file), 0755); err != nil {
+		return err
+	}
+	return WriteFile(r.file, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// without returns projects without the one at path
+func without(projects []RecentProject, path string) []RecentProject {
+	kept := make([]RecentProject, 0, len(projects))
+	for _, p := range projects {
+		if p.Path != path {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
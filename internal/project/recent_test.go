@@ -0,0 +1,65 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecentProjects(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config", "recent.json")
+	recent := NewRecentProjects(file)
+
+	if projects, err := recent.List(); err != nil || len(projects) != 0 {
+		t.Errorf("List() before any project = %v, %v", projects, err)
+	}
+
+	a, b := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.cwz")
+	for _, p := range []string{a, b, a} {
+		if err := recent.Add(p, filepath.Base(p)); err != nil {
+			t.Fatalf("Add(%s) error = %v", p, err)
+		}
+	}
+	// The list is kept across instances, newest first and without duplicates
+	projects, err := NewRecentProjects(file).List()
+	if err != nil || len(projects) != 2 || projects[0].Path != a || projects[1].Path != b || projects[0].Name != "a.json" {
+		t.Errorf("List() = %+v, %v, want a then b", projects, err)
+	}
+
+	if err := recent.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	if projects, _ := recent.List(); len(projects) != 1 || projects[0].Path != b {
+		t.Errorf("List() after Remove = %+v, want b", projects)
+	}
+
+	for i := 0; i < MaxRecentProjects+3; i++ {
+		if err := recent.Add(filepath.Join(dir, fmt.Sprintf("%d.json", i)), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	projects, _ = recent.List()
+	if len(projects) != MaxRecentProjects || projects[0].Path != filepath.Join(dir, fmt.Sprintf("%d.json", MaxRecentProjects+2)) {
+		t.Errorf("List() = %d projects starting %+v, want the newest %d", len(projects), projects[0], MaxRecentProjects)
+	}
+
+	if err := recent.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if projects, _ := recent.List(); len(projects) != 0 {
+		t.Errorf("List() after Clear = %+v", projects)
+	}
+
+	// A damaged list starts over rather than failing
+	if err := os.WriteFile(file, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := recent.Add(a, "a"); err != nil {
+		t.Errorf("Add() over a damaged list error = %v", err)
+	}
+	if projects, _ := recent.List(); len(projects) != 1 {
+		t.Errorf("List() after repairing = %+v", projects)
+	}
+}
@@ -0,0 +1,223 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+	"card_wizard/internal/migrate"
+)
+
+// Service does the file work behind the app's dialogs on explicit paths and
+// writers. It remembers the path of the open game, which relative image and
+// font paths resolve against, and the files outside it the app may serve.
+type Service struct {
+	mu           sync.RWMutex
+	gamePath     string          // Path to the currently loaded/saved game file
+	bundlePath   string          // Bundle the open game was unpacked from, repacked on Save
+	allowedFiles map[string]bool // Single files served, like fonts, see AllowFile
+}
+
+// NewService creates a service with no game open
+func NewService() *Service {
+	return &Service{
+		allowedFiles: make(map[string]bool),
+	}
+}
+
+// GamePath returns the path of the open game, or "" for an unsaved game
+func (s *Service) GamePath() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.gamePath
+}
+
+// SetGamePath sets the path of the open game; "" starts a new, unsaved game.
+// Moving to another path forgets the bundle the game came from.
+func (s *Service) SetGamePath(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if path != s.gamePath {
+		s.bundlePath = ""
+	}
+	s.gamePath = path
+}
+
+// BundlePath returns the bundle the open game was unpacked from, if any
+func (s *Service) BundlePath() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bundlePath
+}
+
+// SetBundlePath makes Save write the open game back into the bundle at path
+func (s *Service) SetBundlePath(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bundlePath = path
+}
+
+// GameDir returns the directory of the open game, or "" for an unsaved game
+func (s *Service) GameDir() string {
+	if path := s.GamePath(); path != "" {
+		return filepath.Dir(path)
+	}
+	return ""
+}
+
+// ValidateGame stores typed field values as their types and reports values that
+// do not fit their fields. g gets its own decks, so games sharing them with g,
+// like the caller's copy of a game passed by value, are left unchanged.
+func ValidateGame(g *game.Game) error {
+	g.Decks = append([]deck.Deck(nil), g.Decks...)
+	for i := range g.Decks {
+		g.Decks[i].CoerceFields()
+		if err := g.Decks[i].ValidateFields(); err != nil {
+			return fmt.Errorf("deck %s has invalid field values:\n%w", g.Decks[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// SaveGameTo validates a game and writes it to path, which becomes the open
+// game. Absolute image paths inside the game's directory are made relative.
+// The file is replaced in one step, so an interrupted save keeps the old one,
+// and a snapshot of the save is kept in SnapshotsDir.
+func (s *Service) SaveGameTo(g game.Game, path string) error {
+	if err := ValidateGame(&g); err != nil {
+		return err
+	}
+
+	// Store the game path for relative path resolution
+	s.SetGamePath(path)
+
+	// Convert absolute image paths to relative paths before saving
+	gameDir := filepath.Dir(path)
+	decks := make([]deck.Deck, len(g.Decks))
+	for i, d := range g.Decks {
+		decks[i] = convertPathsToRelative(d, gameDir)
+	}
+	g.Decks = decks
+
+	// A file from a newer version keeps its version, so that version does not
+	// migrate the data it wrote again
+	g.FormatVersion = max(g.FormatVersion, game.FormatVersion)
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := WriteFile(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	// The game is saved; a missing snapshot is not worth failing the save over
+	_ = s.saveSnapshot(g)
+	return nil
+}
+
+// SaveGame writes a game over the open game's file, and repacks the bundle it
+// was unpacked from if there is one. It returns the path of the file the user
+// knows the game by.
+func (s *Service) SaveGame(g game.Game) (string, error) {
+	path, bundle := s.GamePath(), s.BundlePath()
+	if path == "" {
+		return "", errNoGame
+	}
+	if err := s.SaveGameTo(g, path); err != nil {
+		return "", err
+	}
+	if bundle == "" {
+		return path, nil
+	}
+	if _, err := s.SaveBundleTo(g, bundle); err != nil {
+		return "", err
+	}
+	return bundle, nil
+}
+
+// LoadGameFrom reads the game at path, which becomes the open game, upgrading
+// files saved by older versions
+func (s *Service) LoadGameFrom(path string) (*game.Game, migrate.Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, migrate.Result{}, err
+	}
+
+	// Upgrade files saved by older versions, including v0.1.x single-deck files
+	g, result, err := migrate.Load(data)
+	if err != nil {
+		return nil, result, err
+	}
+
+	// Store the game path for relative path resolution
+	s.SetGamePath(path)
+	s.allowGameFiles(g)
+	return &g, result, nil
+}
+
+// ResolvePath resolves a potentially relative image path against the open game
+func (s *Service) ResolvePath(path string) string {
+	// If already absolute, return as-is
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	// If no game is loaded, return path as-is (will likely fail, but that's expected)
+	gameDir := s.GameDir()
+	if gameDir == "" {
+		return path
+	}
+
+	// Resolve relative to game directory
+	return filepath.Join(gameDir, path)
+}
+
+// convertPathsToRelative converts all absolute image paths in a deck to relative paths
+func convertPathsToRelative(d deck.Deck, deckDir string) deck.Deck {
+	// Convert paths in card data, without changing the caller's cards
+	cards := make([]deck.Card, len(d.Cards))
+	for i, c := range d.Cards {
+		c.Data = convertMapPathsToRelative(c.Data, deckDir)
+		cards[i] = c
+	}
+	d.Cards = cards
+
+	return d
+}
+
+// convertMapPathsToRelative converts absolute paths in a map to relative paths
+func convertMapPathsToRelative(data map[string]interface{}, deckDir string) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	for key, value := range data {
+		if strValue, ok := value.(string); ok {
+			// Check if this looks like a file path (contains path separators or drive letters)
+			if strings.Contains(strValue, string(filepath.Separator)) || strings.Contains(strValue, "/") || strings.Contains(strValue, ":\\") {
+				// Try to make it relative
+				if filepath.IsAbs(strValue) {
+					relPath, err := filepath.Rel(deckDir, strValue)
+					if err == nil {
+						// Successfully made relative - use forward slashes for cross-platform compatibility
+						relPath = filepath.ToSlash(relPath)
+						result[key] = relPath
+						continue
+					}
+				}
+			}
+		}
+		// Keep value as-is if not a convertible path
+		result[key] = value
+	}
+
+	return result
+}
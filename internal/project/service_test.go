@@ -0,0 +1,445 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+)
+
+func testGame(dir string) game.Game {
+	return game.Game{
+		Name: "Test",
+		Decks: []deck.Deck{{
+			ID:     "weapons",
+			Name:   "Weapons",
+			Width:  63.5,
+			Height: 88.9,
+			Fields: []deck.FieldDefinition{
+				{Name: "Name", Type: deck.FieldText},
+				{Name: "Cost", Type: deck.FieldInteger},
+				{Name: "Art", Type: deck.FieldImage},
+			},
+			Cards: []deck.Card{
+				{ID: "dagger", Count: 2, FrontStyleID: "front", Data: map[string]interface{}{"Name": "Dagger", "Cost": "1", "Art": filepath.Join(dir, "images", "dagger.png")}},
+				{ID: "sword", Count: 1, FrontStyleID: "front", Data: map[string]interface{}{"Name": "Sword", "Cost": 3}},
+			},
+			FrontStyles: map[string]deck.CardLayout{"front": {Name: "Front", Elements: []deck.LayoutElement{
+				{ID: "name", Type: "text", Field: "Name", X: 5, Y: 5, Width: 50, Height: 10, FontSize: 12},
+			}}},
+			BackStyles:          map[string]deck.CardLayout{"back": {Name: "Back"}},
+			DefaultFrontStyleID: "front",
+			DefaultBackStyleID:  "back",
+			PaperSize:           "a4",
+		}},
+	}
+}
+
+func TestSaveAndLoadGame(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "game.json")
+	g := testGame(dir)
+	svc := NewService()
+
+	if err := svc.SaveGameTo(g, path); err != nil {
+		t.Fatalf("SaveGameTo() error = %v", err)
+	}
+	if svc.GamePath() != path {
+		t.Errorf("GamePath() = %q after saving, want %q", svc.GamePath(), path)
+	}
+	// The caller's absolute path and untyped values are left alone
+	if art := g.Decks[0].Cards[0].Data["Art"]; !filepath.IsAbs(art.(string)) {
+		t.Errorf("SaveGameTo() changed the caller's card data to %v", art)
+	}
+	if cost := g.Decks[0].Cards[0].Data["Cost"]; cost != "1" {
+		t.Errorf("SaveGameTo() changed the caller's Cost to %#v", cost)
+	}
+
+	loaded := NewService()
+	got, result, err := loaded.LoadGameFrom(path)
+	if err != nil {
+		t.Fatalf("LoadGameFrom() error = %v", err)
+	}
+	if result.Upgraded() || loaded.GamePath() != path {
+		t.Errorf("LoadGameFrom() result = %+v, path %q", result, loaded.GamePath())
+	}
+	c := got.Decks[0].Cards[0]
+	if c.Data["Art"] != "images/dagger.png" {
+		t.Errorf("saved Art = %v, want images/dagger.png", c.Data["Art"])
+	}
+	// Integers decode from JSON as float64
+	if c.Data["Cost"] != 1.0 || got.FormatVersion != game.FormatVersion {
+		t.Errorf("saved Cost = %#v, format %d", c.Data["Cost"], got.FormatVersion)
+	}
+	if resolved := loaded.ResolvePath("images/dagger.png"); resolved != filepath.Join(dir, "images", "dagger.png") {
+		t.Errorf("ResolvePath() = %q", resolved)
+	}
+
+	// Values that do not fit their fields are refused and nothing is written
+	bad := testGame(dir)
+	bad.Decks[0].Cards[1].Data["Cost"] = "lots"
+	badPath := filepath.Join(dir, "bad.json")
+	if err := svc.SaveGameTo(bad, badPath); err == nil || !strings.Contains(err.Error(), "invalid field values") {
+		t.Errorf("SaveGameTo() error = %v, want invalid field values", err)
+	}
+	if _, err := os.Stat(badPath); !os.IsNotExist(err) {
+		t.Error("SaveGameTo() wrote a game with invalid values")
+	}
+}
+
+func TestSaveGameInPlace(t *testing.T) {
+	dir := t.TempDir()
+	svc := NewService()
+	if _, err := svc.SaveGame(testGame(dir)); err == nil {
+		t.Error("SaveGame() of an unsaved game succeeded")
+	}
+
+	path := filepath.Join(dir, "game.json")
+	if err := svc.SaveGameTo(testGame(dir), path); err != nil {
+		t.Fatal(err)
+	}
+	g := testGame(dir)
+	g.Name = "Renamed"
+	if saved, err := svc.SaveGame(g); err != nil || saved != path {
+		t.Fatalf("SaveGame() = %q, %v, want %q", saved, err, path)
+	}
+	if loaded, _, err := NewService().LoadGameFrom(path); err != nil || loaded.Name != "Renamed" {
+		t.Errorf("saved game = %+v, %v, want it renamed", loaded, err)
+	}
+
+	// A game opened from a bundle is packed back into it
+	bundle := filepath.Join(dir, "game"+BundleExt)
+	if _, err := svc.SaveBundleTo(g, bundle); err != nil {
+		t.Fatal(err)
+	}
+	opened := NewService()
+	if _, _, err := opened.OpenBundle(bundle, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	opened.SetBundlePath(bundle)
+	g.Name = "Bundled"
+	if saved, err := opened.SaveGame(g); err != nil || saved != bundle {
+		t.Fatalf("SaveGame() of a bundle = %q, %v, want %q", saved, err, bundle)
+	}
+	if reopened, _, err := NewService().OpenBundle(bundle, t.TempDir()); err != nil || reopened.Name != "Bundled" {
+		t.Errorf("repacked bundle = %+v, %v", reopened, err)
+	}
+
+	// Saving somewhere else leaves the bundle behind
+	if err := opened.SaveGameTo(g, filepath.Join(dir, "copy.json")); err != nil || opened.BundlePath() != "" {
+		t.Errorf("BundlePath() = %q after saving elsewhere (%v)", opened.BundlePath(), err)
+	}
+}
+
+func TestSaveKeepsNewerFormatVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "game.json")
+	newer := game.FormatVersion + 1
+	if err := os.WriteFile(path, []byte(fmt.Sprintf(`{"formatVersion": %d, "name": "Future", "decks": []}`, newer)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewService()
+	g, result, err := svc.LoadGameFrom(path)
+	if err != nil || !result.Newer {
+		t.Fatalf("LoadGameFrom() = %+v, %v, want a newer file", result, err)
+	}
+	if _, err := svc.SaveGame(*g); err != nil {
+		t.Fatal(err)
+	}
+	saved, _, err := NewService().LoadGameFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.FormatVersion != newer {
+		t.Errorf("saved format = %d, want %d", saved.FormatVersion, newer)
+	}
+
+	bundle := filepath.Join(dir, "game"+BundleExt)
+	if _, err := svc.SaveBundleTo(*g, bundle); err != nil {
+		t.Fatal(err)
+	}
+	opened, _, err := NewService().OpenBundle(bundle, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.FormatVersion != newer {
+		t.Errorf("bundled format = %d, want %d", opened.FormatVersion, newer)
+	}
+}
+
+func TestLoadAndSaveKeepUnknownFields(t *testing.T) {
+	const file = `{%s
+		"name": "Future Game",
+		"formatHint": {"writer": "card-wizard 9.0"},
+		"decks": [{
+			"id": "d1",
+			"name": "Deck",
+			"width": 63.5,
+			"height": 88.9,
+			"paperSize": "a4",
+			"theme": "dark",
+			"customFonts": [{"name": "Fancy", "path": "fonts/fancy.ttf", "family": "Fancy", "weight": 700}],
+			"fields": [{"name": "Cost", "type": "integer", "min": 0}],
+			"cards": [{"id": "c1", "data": {"Cost": "2"}, "count": 2, "frontStyleId": "f", "backStyleId": "b", "locked": true}],
+			"frontStyles": {"f": {"name": "Front", "tags": ["new"], "elements": [
+				{"id": "s1", "type": "shape", "field": "", "x": 1, "y": 2, "width": 30, "height": 20, "glow": 3,
+				 "points": [{"x": 0, "y": 0, "curve": {"smooth": true}}, {"x": 1, "y": 0}, {"x": 0.5, "y": 1}]}
+			]}},
+			"backStyles": {"b": {"name": "Back", "elements": []}},
+			"defaultFrontStyleId": "f",
+			"defaultBackStyleId": "b",
+			"imposition": {"rows": 3, "bleedMarks": {"length": 3}},
+			"renderedCards": [{"cardId": "c1", "styleId": "f", "side": "front", "image": "", "dpi": 300}]
+		}]
+	}`
+	unknown := []string{
+		`"formatHint":{"writer":"card-wizard 9.0"}`,
+		`"theme":"dark"`,
+		`"weight":700`,
+		`"min":0`,
+		`"locked":true`,
+		`"tags":["new"]`,
+		`"glow":3`,
+		`"curve":{"smooth":true}`,
+		`"bleedMarks":{"length":3}`,
+		`"dpi":300`,
+	}
+
+	// Files at the current format and files that need migrating both go through
+	// the same load, validation and save
+	for _, tt := range []struct{ name, version string }{
+		{"current", fmt.Sprintf(`"formatVersion": %d,`, game.FormatVersion)},
+		{"migrated", ""},
+	} {
+		path := filepath.Join(t.TempDir(), "game.json")
+		if err := os.WriteFile(path, []byte(fmt.Sprintf(file, tt.version)), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		svc := NewService()
+		g, _, err := svc.LoadGameFrom(path)
+		if err != nil {
+			t.Fatalf("LoadGameFrom() error = %v", err)
+		}
+		if _, err := svc.SaveGame(*g); err != nil {
+			t.Fatalf("SaveGame() error = %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var saved bytes.Buffer
+		if err := json.Compact(&saved, data); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range unknown {
+			if !strings.Contains(saved.String(), want) {
+				t.Errorf("saving a %s file lost %s", tt.name, want)
+			}
+		}
+	}
+}
+
+func TestWriteFileIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "game.json")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed write keeps the old file and leaves nothing behind
+	err := WriteFile(path, func(w io.Writer) error {
+		w.Write([]byte("partial"))
+		return errors.New("disk full")
+	})
+	if err == nil || !strings.Contains(err.Error(), "game.json") {
+		t.Errorf("WriteFile() error = %v, want it to name the file", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("file after a failed write = %q, want old", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory after a failed write has %d entries, want 1", len(entries))
+	}
+
+	if err := WriteFile(path, func(w io.Writer) error {
+		_, err := w.Write([]byte("new"))
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("file after WriteFile = %q, want new", data)
+	}
+}
+
+func TestExportAndImportSheets(t *testing.T) {
+	dir := t.TempDir()
+	svc := NewService()
+	if err := svc.SaveGameTo(testGame(dir), filepath.Join(dir, "game.json")); err != nil {
+		t.Fatal(err)
+	}
+	g, _, err := svc.LoadGameFrom(filepath.Join(dir, "game.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := g.Decks[0]
+
+	exports := map[string]func(string) error{
+		"game.xlsx": func(path string) error {
+			return WriteFile(path, func(w io.Writer) error { return svc.ExportGameXLSXTo(*g, w, true) })
+		},
+		"deck.xlsx": func(path string) error {
+			return WriteFile(path, func(w io.Writer) error { return svc.ExportXLSXTo(d, w) })
+		},
+		"game.ods": func(path string) error {
+			return WriteFile(path, func(w io.Writer) error { return svc.ExportGameODSTo(*g, w) })
+		},
+		"deck.ods": func(path string) error {
+			return WriteFile(path, func(w io.Writer) error { return svc.ExportODSTo(d, w) })
+		},
+	}
+	for name, export := range exports {
+		path := filepath.Join(dir, name)
+		if err := export(path); err != nil {
+			t.Errorf("%s: export error = %v", name, err)
+			continue
+		}
+
+		sheets, err := DeckSheets(path)
+		if err != nil || len(sheets) != 1 || sheets[0] != "Weapons" {
+			t.Errorf("%s: DeckSheets() = %v, %v, want [Weapons]", name, sheets, err)
+			continue
+		}
+		headers, err := SheetHeaders(path, "Weapons")
+		if err != nil || strings.Join(headers, ",") != "ID,Count,Front Style,Back Style,Name,Cost,Art" {
+			t.Errorf("%s: SheetHeaders() = %v, %v", name, headers, err)
+		}
+		mapping, err := ImportMapping(path, "Weapons")
+		if err != nil || mapping == nil {
+			t.Fatalf("%s: ImportMapping() = %v, %v", name, mapping, err)
+		}
+
+		result, err := svc.ImportCards(path, "Weapons", mapping, d, true)
+		if err != nil || len(result.Cards) != 2 || result.Cards[1].Data["Cost"] != 3 {
+			t.Errorf("%s: ImportCards() = %+v, %v", name, result, err)
+		}
+		diff, err := SyncCards(path, "Weapons", mapping, d)
+		if err != nil || diff.Unchanged != 2 {
+			t.Errorf("%s: SyncCards() = %+v, %v, want 2 unchanged", name, diff, err)
+		}
+	}
+
+	if _, err := DeckSheets(filepath.Join(dir, "missing.xlsx")); err == nil {
+		t.Error("DeckSheets() of a missing file succeeded")
+	}
+}
+
+func TestGeneratePDFs(t *testing.T) {
+	dir := t.TempDir()
+	svc := NewService()
+	d := testGame(dir).Decks[0]
+
+	for name, generate := range map[string]func(deck.Deck, string) error{
+		"deck.pdf":        svc.GeneratePDFTo,
+		"calibration.pdf": svc.GenerateCalibrationPDFTo,
+	} {
+		path := filepath.Join(dir, name)
+		if err := generate(d, path); err != nil {
+			t.Errorf("%s: error = %v", name, err)
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || !bytes.HasPrefix(data, []byte("%PDF")) {
+			t.Errorf("%s: wrote %d bytes (%v), want a PDF", name, len(data), err)
+		}
+	}
+}
+
+func TestProjectImages(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(t.TempDir(), "dagger.png")
+	if err := os.WriteFile(src, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewService()
+	if _, err := svc.AddImage(src); err == nil {
+		t.Error("AddImage() without a game succeeded")
+	}
+	if _, err := svc.ListImages(); err == nil {
+		t.Error("ListImages() without a game succeeded")
+	}
+
+	svc.SetGamePath(filepath.Join(dir, "game.json"))
+	added, err := svc.AddImages([]string{src, src, filepath.Join(dir, "missing.png")})
+	if err == nil || strings.Join(added, ",") != "images/dagger.png,images/dagger_1.png" {
+		t.Errorf("AddImages() = %v, %v, want two images and an error", added, err)
+	}
+
+	images, err := svc.ListImages()
+	if err != nil || strings.Join(images, ",") != "dagger.png,dagger_1.png" {
+		t.Errorf("ListImages() = %v, %v", images, err)
+	}
+
+	replacement := filepath.Join(t.TempDir(), "new.png")
+	if err := os.WriteFile(replacement, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ReplaceImage("dagger.png", replacement); err != nil {
+		t.Fatalf("ReplaceImage() error = %v", err)
+	}
+	url, err := svc.ImageDataURL("images/dagger.png")
+	if err != nil || url != "data:image/png;base64,bmV3" {
+		t.Errorf("ImageDataURL() = %q, %v", url, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "card.JPG"), []byte("jpg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if url, err := svc.ImageDataURL("card.JPG"); err != nil || url != "data:image/jpeg;base64,anBn" {
+		t.Errorf("ImageDataURL(jpg) = %q, %v", url, err)
+	}
+	for _, path := range []string{replacement, "../" + filepath.Base(replacement), "game.json"} {
+		if _, err := svc.ImageDataURL(path); err == nil {
+			t.Errorf("ImageDataURL(%q) read a file it should refuse", path)
+		}
+	}
+
+	if err := svc.DeleteImage("dagger_1.png"); err != nil {
+		t.Fatalf("DeleteImage() error = %v", err)
+	}
+	if images, _ := svc.ListImages(); len(images) != 1 {
+		t.Errorf("ListImages() after delete = %v", images)
+	}
+}
+
+func TestSaveDataURLs(t *testing.T) {
+	dir := t.TempDir()
+	err := SaveDataURLs(map[string]string{
+		"a.png":   "data:image/png;base64,YQ==",
+		"bad.png": "not a data URL",
+	}, dir)
+	if err != nil {
+		t.Fatalf("SaveDataURLs() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "a.png")); err != nil || string(data) != "a" {
+		t.Errorf("a.png = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bad.png")); !os.IsNotExist(err) {
+		t.Error("SaveDataURLs() wrote a value that is not a data URL")
+	}
+
+	if err := SaveDataURLs(map[string]string{"c.png": "data:image/png;base64,!!"}, dir); err == nil {
+		t.Error("SaveDataURLs() with bad base64 succeeded")
+	}
+}
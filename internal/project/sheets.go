@@ -0,0 +1,119 @@
+package project
+
+import (
+	"card_wizard/internal/deck"
+	"card_wizard/internal/tabular"
+)
+
+// DeckSheets returns the sheets of a spreadsheet that can hold cards, leaving
+// out the metadata sheet of an exported workbook
+func DeckSheets(filePath string) ([]string, error) {
+	src, err := tabular.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	var sheets []string
+	for _, sheet := range src.Sheets() {
+		if sheet != tabular.MetadataSheet {
+			sheets = append(sheets, sheet)
+		}
+	}
+	return sheets, nil
+}
+
+// SheetHeaders returns the headers from the first row of a sheet
+func SheetHeaders(filePath, sheetName string) ([]string, error) {
+	src, err := tabular.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	return tabular.Headers(src, sheetName)
+}
+
+// ImportMapping returns the column mapping for a sheet exported by the app, or
+// nil when the sheet needs to be mapped by hand
+func ImportMapping(filePath, sheetName string) (map[string]string, error) {
+	src, err := tabular.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	meta, err := tabular.ReadMetadata(src)
+	if err != nil || meta[sheetName] == nil {
+		return nil, err
+	}
+	return meta[sheetName].Mapping(), nil
+}
+
+// ImportCards maps a sheet's rows to cards and reports problems with them
+// against the deck being imported into. Images embedded in the sheet are copied
+// into the open game's images folder, reusing copies made by earlier imports.
+func (s *Service) ImportCards(filePath, sheetName string, mapping map[string]string, d deck.Deck, strict bool) (tabular.ImportResult, error) {
+	src, err := tabular.Open(filePath)
+	if err != nil {
+		return tabular.ImportResult{}, err
+	}
+	defer src.Close()
+
+	rows, err := src.Rows(sheetName)
+	if err != nil {
+		return tabular.ImportResult{}, err
+	}
+
+	gameDir := s.GameDir()
+	opts := tabular.ImportOptions{Strict: strict, ImageDir: gameDir}
+
+	// Workbooks exported by the app describe their fields and styles
+	meta, err := tabular.ReadMetadata(src)
+	if err != nil {
+		return tabular.ImportResult{}, err
+	}
+	opts.Metadata = meta[sheetName]
+
+	if ps, ok := src.(tabular.PictureSource); ok {
+		if opts.Pictures, err = ps.Pictures(sheetName); err != nil {
+			return tabular.ImportResult{}, err
+		}
+		if gameDir != "" {
+			opts.SaveImage = func(fileName string, data []byte) (string, error) {
+				return SaveImage(gameDir, fileName, data, true)
+			}
+		}
+	}
+
+	return tabular.ImportCards(rows, mapping, d, opts)
+}
+
+// SyncSheet checks a sheet against a deck like ImportCards, saving its embedded
+// images, and compares its rows with the deck's cards like SyncCards, so
+// reloading a sheet keeps what it has no columns for
+func (s *Service) SyncSheet(filePath, sheetName string, mapping map[string]string, d deck.Deck) (tabular.ImportResult, tabular.SyncDiff, error) {
+	result, err := s.ImportCards(filePath, sheetName, mapping, d, false)
+	if err != nil {
+		return result, tabular.SyncDiff{}, err
+	}
+	diff, err := tabular.SyncCards(d, result.Rows, mapping)
+	return result, diff, err
+}
+
+// SyncCards compares a sheet with a deck's cards, matching rows by the "key"
+// column of the mapping
+func SyncCards(filePath, sheetName string, mapping map[string]string, d deck.Deck) (tabular.SyncDiff, error) {
+	src, err := tabular.Open(filePath)
+	if err != nil {
+		return tabular.SyncDiff{}, err
+	}
+	defer src.Close()
+
+	rows, err := src.Rows(sheetName)
+	if err != nil {
+		return tabular.SyncDiff{}, err
+	}
+
+	return tabular.SyncCards(d, rows, mapping)
+}
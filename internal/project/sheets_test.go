@@ -0,0 +1,36 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/tabular"
+)
+
+func TestSyncSheetKeepsWhatTheSheetLacks(t *testing.T) {
+	dir := t.TempDir()
+	sheet := filepath.Join(dir, "cards.csv")
+	if err := os.WriteFile(sheet, []byte("ID,Name\ndagger,Long Dagger\nsword,Sword\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The deck has a card added in the app and a count and style the sheet has no columns for
+	d := testGame(dir).Decks[0]
+	d.Cards = append(d.Cards, deck.Card{ID: "axe", Count: 1, Data: map[string]interface{}{"Name": "Axe"}})
+	d.Cards[0].FrontStyleID = "gold"
+
+	_, diff, err := NewService().SyncSheet(sheet, "", map[string]string{tabular.MapID: "ID"}, d)
+	if err != nil {
+		t.Fatalf("SyncSheet() error = %v", err)
+	}
+	cards := tabular.ApplySync(d.Cards, diff, false)
+	if len(cards) != 3 || cards[2].ID != "axe" {
+		t.Fatalf("synced cards = %+v, want the app's axe kept", cards)
+	}
+	dagger := cards[0]
+	if dagger.Data["Name"] != "Long Dagger" || dagger.Count != 2 || dagger.FrontStyleID != "gold" || dagger.Data["Art"] == nil {
+		t.Errorf("synced dagger = %+v, want the new name with its count, style and art kept", dagger)
+	}
+}
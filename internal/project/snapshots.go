@@ -0,0 +1,204 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+	"card_wizard/internal/migrate"
+)
+
+// SnapshotsDir is the hidden folder, next to the game file or bundle, that a copy
+// of each save is kept in, one subfolder per game file or bundle
+const SnapshotsDir = ".snapshots"
+
+// MaxSnapshots is how many snapshots are kept for each game; older ones are deleted
+const MaxSnapshots = 50
+
+// snapshotTime formats snapshot IDs, which sort in the order they were taken
+const snapshotTime = "20060102-150405.000"
+
+// Snapshot is a copy of a game as it was saved
+type Snapshot struct {
+	ID      string    `json:"id"`
+	SavedAt time.Time `json:"savedAt"`
+	Size    int64     `json:"size"` // Bytes
+}
+
+// snapshotDir returns the open game's snapshot folder. A game opened from a
+// bundle keeps its snapshots next to the bundle, as the bundle is unpacked to a
+// new working folder each time it is opened.
+func (s *Service) snapshotDir() (string, error) {
+	path := s.GamePath()
+	if path == "" {
+		return "", errNoGame
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if bundle := s.BundlePath(); bundle != "" {
+		// Keep the extension apart from a game file of the same name
+		path, name = bundle, filepath.Base(bundle)
+	}
+	return filepath.Join(filepath.Dir(path), SnapshotsDir, name), nil
+}
+
+// saveSnapshot keeps a copy of a game just saved, unless it matches the latest
+// snapshot, and deletes the oldest beyond MaxSnapshots. Pre-rendered card
+// images are left out, as they are rendered again when needed.
+func (s *Service) saveSnapshot(g game.Game) error {
+	dir, err := s.snapshotDir()
+	if err != nil {
+		return err
+	}
+
+	decks := make([]deck.Deck, len(g.Decks))
+	for i, d := range g.Decks {
+		d.RenderedCards = nil
+		decks[i] = d
+	}
+	g.Decks = decks
+	data, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := s.Snapshots()
+	if err != nil {
+		return err
+	}
+	if len(snapshots) > 0 {
+		latest, err := os.ReadFile(filepath.Join(dir, snapshots[0].ID+".json"))
+		if err == nil && bytes.Equal(latest, data) {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	id := time.Now().UTC().Format(snapshotTime)
+	for n := 1; ; n++ {
+		if _, err := os.Stat(filepath.Join(dir, id+".json")); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", time.Now().UTC().Format(snapshotTime), n)
+	}
+	if err := WriteFile(filepath.Join(dir, id+".json"), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	// The new snapshot is not in the list read above
+	for i := MaxSnapshots - 1; i < len(snapshots); i++ {
+		os.Remove(filepath.Join(dir, snapshots[i].ID+".json"))
+	}
+	return nil
+}
+
+// Snapshots returns the snapshots of the open game, newest first
+func (s *Service) Snapshots() ([]Snapshot, error) {
+	dir, err := s.snapshotDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []Snapshot{}
+	for _, file := range files {
+		id, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok || file.IsDir() {
+			continue
+		}
+		savedAt, err := time.Parse(snapshotTime, id[:min(len(id), len(snapshotTime))])
+		if err != nil {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{ID: id, SavedAt: savedAt, Size: info.Size()})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ID > snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+// LoadSnapshot reads a snapshot of the open game
+func (s *Service) LoadSnapshot(id string) (*game.Game, error) {
+	dir, err := s.snapshotDir()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return nil, fmt.Errorf("invalid snapshot %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	g, _, err := migrate.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", id, err)
+	}
+	return &g, nil
+}
+
+// DiffSnapshots returns what changed in the open game from one snapshot to another
+func (s *Service) DiffSnapshots(fromID, toID string) (game.Diff, error) {
+	from, err := s.LoadSnapshot(fromID)
+	if err != nil {
+		return game.Diff{}, err
+	}
+	to, err := s.LoadSnapshot(toID)
+	if err != nil {
+		return game.Diff{}, err
+	}
+	return game.Compare(*from, *to), nil
+}
+
+// DiffWithSnapshot returns what changed from a snapshot to g, a newer copy of
+// the open game. Image paths and typed values are compared as they would be
+// saved.
+func (s *Service) DiffWithSnapshot(id string, g game.Game) (game.Diff, error) {
+	from, err := s.LoadSnapshot(id)
+	if err != nil {
+		return game.Diff{}, err
+	}
+	decks := make([]deck.Deck, len(g.Decks))
+	for i, d := range g.Decks {
+		decks[i] = convertPathsToRelative(d, s.GameDir())
+		decks[i].CoerceFields()
+	}
+	g.Decks = decks
+	return game.Compare(*from, g), nil
+}
+
+// RestoreSnapshot returns a snapshot of the open game to carry on editing from.
+// Nothing is written until the game is saved again.
+func (s *Service) RestoreSnapshot(id string) (*game.Game, error) {
+	g, err := s.LoadSnapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	s.allowGameFiles(*g)
+	return g, nil
+}
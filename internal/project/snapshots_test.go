@@ -0,0 +1,138 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"card_wizard/internal/deck"
+	"card_wizard/internal/game"
+)
+
+func TestSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "game.json")
+	svc := NewService()
+	if _, err := svc.Snapshots(); err == nil {
+		t.Error("Snapshots() without a game succeeded")
+	}
+
+	g := testGame(dir)
+	g.Decks[0].RenderedCards = []deck.RenderedCard{{StyleID: "front", Side: "front", Image: "png"}}
+	if err := svc.SaveGameTo(g, path); err != nil {
+		t.Fatal(err)
+	}
+	// Saving the same game again keeps a single snapshot
+	if err := svc.SaveGameTo(g, path); err != nil {
+		t.Fatal(err)
+	}
+	g.Decks[0].Cards[1].Data["Cost"] = 4
+	g.Decks[0].FrontStyles["front"].Elements[0].X = 9
+	if err := svc.SaveGameTo(g, path); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := svc.Snapshots()
+	if err != nil || len(snapshots) != 2 {
+		t.Fatalf("Snapshots() = %+v, %v, want 2", snapshots, err)
+	}
+	newest, oldest := snapshots[0].ID, snapshots[1].ID
+	if newest <= oldest || snapshots[0].SavedAt.IsZero() {
+		t.Errorf("Snapshots() = %+v, want newest first", snapshots)
+	}
+	if _, err := os.Stat(filepath.Join(dir, SnapshotsDir, "game", newest+".json")); err != nil {
+		t.Errorf("snapshot not kept in %s: %v", SnapshotsDir, err)
+	}
+
+	diff, err := svc.DiffSnapshots(oldest, newest)
+	if err != nil || len(diff.Decks) != 1 {
+		t.Fatalf("DiffSnapshots() = %+v, %v", diff, err)
+	}
+	d := diff.Decks[0]
+	if len(d.Cards) != 1 || d.Cards[0].ID != "sword" || d.Cards[0].Changes[0].Key != "data.Cost" {
+		t.Errorf("DiffSnapshots() cards = %+v, want the sword's cost changed", d.Cards)
+	}
+	if len(d.Styles) != 1 || len(d.Styles[0].Elements) != 1 || !d.Styles[0].Elements[0].Moved {
+		t.Errorf("DiffSnapshots() styles = %+v, want the name element moved", d.Styles)
+	}
+
+	// The game being edited compares as it would be saved, so absolute image
+	// paths and untyped values are not changes
+	edited := testGame(dir)
+	edited.Decks[0].Cards[1].Data["Cost"] = "4"
+	edited.Decks[0].FrontStyles["front"].Elements[0].X = 9
+	if diff, err := svc.DiffWithSnapshot(newest, edited); err != nil || !diff.Empty() {
+		t.Errorf("DiffWithSnapshot() = %+v, %v, want no changes", diff, err)
+	}
+
+	restored, err := svc.RestoreSnapshot(oldest)
+	if err != nil || restored.Decks[0].Cards[1].Data["Cost"] != 3.0 {
+		t.Fatalf("RestoreSnapshot() = %+v, %v, want the first save", restored, err)
+	}
+	if restored.Decks[0].RenderedCards != nil || restored.FormatVersion != game.FormatVersion {
+		t.Errorf("RestoreSnapshot() rendered cards = %v, format %d", restored.Decks[0].RenderedCards, restored.FormatVersion)
+	}
+
+	for _, id := range []string{"", "../game", ".hidden", "missing"} {
+		if _, err := svc.LoadSnapshot(id); err == nil {
+			t.Errorf("LoadSnapshot(%q) succeeded", id)
+		}
+	}
+}
+
+func TestSnapshotsArePruned(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "game.json")
+	svc := NewService()
+	g := testGame(dir)
+	for i := 0; i < MaxSnapshots+5; i++ {
+		g.Name = strings.Repeat("x", i+1)
+		if err := svc.SaveGameTo(g, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots, err := svc.Snapshots()
+	if err != nil || len(snapshots) != MaxSnapshots {
+		t.Fatalf("Snapshots() = %d, %v, want %d", len(snapshots), err, MaxSnapshots)
+	}
+	latest, err := svc.LoadSnapshot(snapshots[0].ID)
+	if err != nil || len(latest.Name) != MaxSnapshots+5 {
+		t.Errorf("latest snapshot = %v, %v, want the last save", latest, err)
+	}
+}
+
+func TestBundleSnapshotsOutliveItsWorkingFolder(t *testing.T) {
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "game"+BundleExt)
+	g := testGame(dir)
+	if _, err := NewService().SaveBundleTo(g, bundle); err != nil {
+		t.Fatal(err)
+	}
+
+	open := func() *Service {
+		svc := NewService()
+		if _, _, err := svc.OpenBundle(bundle, t.TempDir()); err != nil {
+			t.Fatal(err)
+		}
+		svc.SetBundlePath(bundle)
+		return svc
+	}
+	svc := open()
+	for _, name := range []string{"First", "Second"} {
+		g.Name = name
+		if _, err := svc.SaveGame(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each open unpacks to a new folder, but the history stays with the bundle
+	snapshots, err := open().Snapshots()
+	if err != nil || len(snapshots) != 2 {
+		t.Fatalf("Snapshots() after reopening = %+v, %v, want 2", snapshots, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, SnapshotsDir, "game"+BundleExt, snapshots[0].ID+".json")); err != nil {
+		t.Errorf("snapshot not kept next to the bundle: %v", err)
+	}
+}
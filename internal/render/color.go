@@ -0,0 +1,107 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+var blackColor = color.NRGBA{A: 255}
+
+var namedColors = map[string]color.NRGBA{
+	"black": blackColor,
+	"white": {R: 255, G: 255, B: 255, A: 255},
+}
+
+// parseColor parses the CSS colors the style editor produces (#rgb, #rrggbb,
+// #rrggbbaa, rgb(), rgba()). An empty string yields the fallback; the second
+// return value is false when nothing should be drawn ("none", "transparent").
+func parseColor(s string, fallback color.NRGBA) (color.NRGBA, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "":
+		return fallback, fallback.A > 0
+	case "none", "transparent":
+		return color.NRGBA{}, false
+	}
+
+	if c, ok := namedColors[s]; ok {
+		return c, true
+	}
+
+	var c color.NRGBA
+	var err error
+	switch {
+	case strings.HasPrefix(s, "#"):
+		c, err = parseHex(s[1:])
+	case strings.HasPrefix(s, "rgb"):
+		c, err = parseRGBFunc(s)
+	default:
+		err = fmt.Errorf("unsupported color %q", s)
+	}
+	if err != nil {
+		return fallback, fallback.A > 0
+	}
+	return c, c.A > 0
+}
+
+func parseHex(hex string) (color.NRGBA, error) {
+	// Expand shorthand forms (#rgb, #rgba)
+	if len(hex) == 3 || len(hex) == 4 {
+		var b strings.Builder
+		for _, ch := range hex {
+			b.WriteRune(ch)
+			b.WriteRune(ch)
+		}
+		hex = b.String()
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid hex color %q", hex)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+func parseRGBFunc(s string) (color.NRGBA, error) {
+	open, end := strings.Index(s, "("), strings.LastIndex(s, ")")
+	if open < 0 || end < open {
+		return color.NRGBA{}, fmt.Errorf("invalid rgb color %q", s)
+	}
+
+	parts := strings.Split(s[open+1:end], ",")
+	if len(parts) != 3 && len(parts) != 4 {
+		return color.NRGBA{}, fmt.Errorf("invalid rgb color %q", s)
+	}
+
+	var channels [4]uint8
+	channels[3] = 255
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return color.NRGBA{}, err
+		}
+		if i == 3 {
+			// Alpha is 0-1
+			v *= 255
+		}
+		channels[i] = uint8(max(0, min(255, v)))
+	}
+	return color.NRGBA{R: channels[0], G: channels[1], B: channels[2], A: channels[3]}, nil
+}
+
+// mustColor parses a color literal used as a package default
+func mustColor(s string) color.NRGBA {
+	c, err := parseHex(strings.TrimPrefix(s, "#"))
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
@@ -0,0 +1,118 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"card_wizard/internal/deck"
+
+	// Register decoders for the formats the asset gallery accepts
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// drawImage places the element's image inside its box honouring ObjectFit
+func (r *Renderer) drawImage(dst *image.RGBA, box image.Rectangle, el deck.LayoutElement, c deck.Card) error {
+	path := fieldValue(c, el.Field)
+	if path == "" {
+		path = el.StaticText
+	}
+	if path == "" {
+		return nil
+	}
+
+	src, err := r.loadImage(path)
+	if err != nil {
+		return err
+	}
+
+	target := fitRect(src.Bounds(), box, el.ObjectFit)
+	xdraw.CatmullRom.Scale(dst, target, src, src.Bounds(), xdraw.Over, nil)
+	return nil
+}
+
+// fitRect computes where an image of size src lands inside box for the given CSS object-fit
+func fitRect(src, box image.Rectangle, objectFit string) image.Rectangle {
+	if objectFit == "fill" || src.Empty() {
+		return box
+	}
+
+	sw, sh := float64(src.Dx()), float64(src.Dy())
+	bw, bh := float64(box.Dx()), float64(box.Dy())
+
+	var scale float64
+	if objectFit == "cover" {
+		scale = math.Max(bw/sw, bh/sh)
+	} else {
+		// Default is "contain", matching the preview
+		scale = math.Min(bw/sw, bh/sh)
+	}
+
+	w, h := sw*scale, sh*scale
+	x := float64(box.Min.X) + (bw-w)/2
+	y := float64(box.Min.Y) + (bh-h)/2
+
+	return image.Rect(
+		int(math.Round(x)),
+		int(math.Round(y)),
+		int(math.Round(x+w)),
+		int(math.Round(y+h)),
+	)
+}
+
+// loadImage decodes an image from disk or a data URL, caching the result
+func (r *Renderer) loadImage(path string) (image.Image, error) {
+	resolved := r.resolvePath(path)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if img, ok := r.images[resolved]; ok {
+		return img, nil
+	}
+
+	var data []byte
+	if strings.HasPrefix(resolved, "data:") {
+		parts := strings.SplitN(resolved, ",", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid data URL")
+		}
+		dec, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode data URL: %w", err)
+		}
+		data = dec
+	} else {
+		raw, err := os.ReadFile(resolved)
+		if err != nil {
+			return nil, err
+		}
+		data = raw
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %s: %w", path, err)
+	}
+
+	r.images[resolved] = img
+	return img, nil
+}
+
+// resolvePath resolves relative image paths against BaseDir, like App.ResolveImagePath
+func (r *Renderer) resolvePath(path string) string {
+	if strings.HasPrefix(path, "data:") || filepath.IsAbs(path) || r.BaseDir == "" {
+		return path
+	}
+	return filepath.Join(r.BaseDir, filepath.FromSlash(path))
+}
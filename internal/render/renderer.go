@@ -0,0 +1,212 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+
+	"card_wizard/internal/deck"
+)
+
+// Side selects which face of a card is rendered
+type Side string
+
+const (
+	SideFront Side = "front"
+	SideBack  Side = "back"
+)
+
+const (
+	mmPerInch = 25.4
+	// cssPxPerInch is the reference resolution the style editor uses for font sizes and stroke widths
+	cssPxPerInch = 96.0
+	// DefaultDPI is used when a renderer is created without a resolution
+	DefaultDPI = 300.0
+	// edgeTolerance is how close (mm) an element must be to a trim edge to be extended into the bleed
+	edgeTolerance = 0.5
+)
+
+// Renderer rasterizes card layouts in Go, without going through the webview
+type Renderer struct {
+	DPI     float64
+	BaseDir string // Directory that relative image paths are resolved against
+
+	fonts *fontSet
+
+	mu     sync.Mutex
+	images map[string]image.Image // Decoded images keyed by resolved path
+}
+
+// NewRenderer creates a renderer producing images at the given DPI
+func NewRenderer(dpi float64) *Renderer {
+	if dpi <= 0 {
+		dpi = DefaultDPI
+	}
+	return &Renderer{
+		DPI:    dpi,
+		fonts:  newFontSet(),
+		images: make(map[string]image.Image),
+	}
+}
+
+// RegisterFont makes a TTF/OTF font available to text elements using the given family name
+func (r *Renderer) RegisterFont(family string, data []byte) error {
+	return r.fonts.register(family, data)
+}
+
+// LayoutFor returns the style used for one side of a card, falling back to the
+// deck default and then to the first available style, like the frontend preview does
+func LayoutFor(d deck.Deck, c deck.Card, side Side) deck.CardLayout {
+	styles := d.FrontStyles
+	styleID := c.FrontStyleID
+	defaultID := d.DefaultFrontStyleID
+	if defaultID == "" {
+		defaultID = "default-front"
+	}
+	if side == SideBack {
+		styles = d.BackStyles
+		styleID = c.BackStyleID
+		defaultID = d.DefaultBackStyleID
+		if defaultID == "" {
+			defaultID = "default-back"
+		}
+	}
+
+	if layout, ok := styles[styleID]; ok {
+		return layout
+	}
+	if layout, ok := styles[defaultID]; ok {
+		return layout
+	}
+
+	// Fallback to first available style (sorted so the choice is deterministic)
+	for _, id := range slices.Sorted(maps.Keys(styles)) {
+		return styles[id]
+	}
+
+	return deck.CardLayout{Name: "default"}
+}
+
+// RenderCard draws every element of the card's layout for the given side at trim size
+func (r *Renderer) RenderCard(d deck.Deck, c deck.Card, side Side) (*image.RGBA, error) {
+	return r.RenderCardWithBleed(d, c, side, 0)
+}
+
+// RenderCardWithBleed renders the card with bleed (mm) added on every side. Elements
+// touching a trim edge, such as backgrounds and full-art images, are extended into
+// the bleed so a slightly off cut never shows a white edge. Other elements keep
+// their position relative to the trim box.
+func (r *Renderer) RenderCardWithBleed(d deck.Deck, c deck.Card, side Side, bleed float64) (*image.RGBA, error) {
+	if d.Width <= 0 || d.Height <= 0 {
+		return nil, fmt.Errorf("deck %q has no card size", d.ID)
+	}
+	if bleed < 0 {
+		bleed = 0
+	}
+
+	scale := r.pxPerMM()
+	bounds := image.Rect(0, 0, int(math.Round((d.Width+2*bleed)*scale)), int(math.Round((d.Height+2*bleed)*scale)))
+	img := image.NewRGBA(bounds)
+	draw.Draw(img, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	layout := LayoutFor(d, c, side)
+	for _, el := range layout.Elements {
+		box := r.elementBox(el, d, bleed)
+		if err := r.drawElement(img, box, el, c); err != nil {
+			return nil, fmt.Errorf("element %s: %w", elementLabel(el), err)
+		}
+	}
+
+	return img, nil
+}
+
+// drawElement draws a single layout element, clipped to its own box like the preview's overflow: hidden
+func (r *Renderer) drawElement(img *image.RGBA, box image.Rectangle, el deck.LayoutElement, c deck.Card) error {
+	clip := box.Intersect(img.Bounds())
+	if clip.Empty() {
+		return nil
+	}
+	dst := img.SubImage(clip).(*image.RGBA)
+
+	switch el.Type {
+	case "image":
+		return r.drawImage(dst, box, el, c)
+	case "shape":
+		r.drawShape(dst, box, el)
+		return nil
+	default:
+		return r.drawText(dst, box, el, c)
+	}
+}
+
+// elementBox converts an element's mm position into a pixel rectangle, offset by the
+// bleed. Non-text elements touching a trim edge are stretched to the bleed edge.
+func (r *Renderer) elementBox(el deck.LayoutElement, d deck.Deck, bleed float64) image.Rectangle {
+	x0, y0 := el.X+bleed, el.Y+bleed
+	x1, y1 := x0+el.Width, y0+el.Height
+
+	if bleed > 0 && el.Type != "text" {
+		if el.X <= edgeTolerance {
+			x0 = 0
+		}
+		if el.Y <= edgeTolerance {
+			y0 = 0
+		}
+		if el.X+el.Width >= d.Width-edgeTolerance {
+			x1 = d.Width + 2*bleed
+		}
+		if el.Y+el.Height >= d.Height-edgeTolerance {
+			y1 = d.Height + 2*bleed
+		}
+	}
+
+	scale := r.pxPerMM()
+	return image.Rect(
+		int(math.Round(x0*scale)),
+		int(math.Round(y0*scale)),
+		int(math.Round(x1*scale)),
+		int(math.Round(y1*scale)),
+	)
+}
+
+func (r *Renderer) pxPerMM() float64 {
+	return r.DPI / mmPerInch
+}
+
+// cssPx converts a CSS pixel value from the style editor into output pixels
+func (r *Renderer) cssPx(v float64) float64 {
+	return v * r.DPI / cssPxPerInch
+}
+
+func elementLabel(el deck.LayoutElement) string {
+	if el.Name != "" {
+		return fmt.Sprintf("%q", el.Name)
+	}
+	return el.ID
+}
+
+// fieldValue returns the card data for a field as a string, or "" when missing
+func fieldValue(c deck.Card, field string) string {
+	if field == "" || c.Data == nil {
+		return ""
+	}
+	v, ok := c.Data[field]
+	if !ok || v == nil {
+		return ""
+	}
+	switch val := v.(type) {
+	case string:
+		return val
+	case bool: // Boolean fields read as they do in the editor
+		if val {
+			return "Yes"
+		}
+		return "No"
+	}
+	return deck.FormatValue(v)
+}
@@ -0,0 +1,279 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"card_wizard/internal/deck"
+)
+
+func testDeck(elements ...deck.LayoutElement) deck.Deck {
+	return deck.Deck{
+		ID:     "deck-1",
+		Width:  63.5,
+		Height: 88.9,
+		FrontStyles: map[string]deck.CardLayout{
+			"default-front": {Name: "Default Front", Elements: elements},
+		},
+		BackStyles: map[string]deck.CardLayout{
+			"default-back": {Name: "Default Back"},
+		},
+		DefaultFrontStyleID: "default-front",
+		DefaultBackStyleID:  "default-back",
+	}
+}
+
+func isWhite(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r == 0xffff && g == 0xffff && b == 0xffff
+}
+
+// countInked counts non-white pixels inside rect
+func countInked(img image.Image, rect image.Rectangle) int {
+	n := 0
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if !isWhite(img.At(x, y)) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestRenderCardSize(t *testing.T) {
+	r := NewRenderer(300)
+	img, err := r.RenderCard(testDeck(), deck.Card{ID: "c1"}, SideFront)
+	if err != nil {
+		t.Fatalf("RenderCard() error = %v", err)
+	}
+
+	// 63.5mm x 88.9mm is exactly 2.5in x 3.5in
+	if got := img.Bounds().Size(); got != image.Pt(750, 1050) {
+		t.Errorf("RenderCard() size = %v, want 750x1050", got)
+	}
+	if n := countInked(img, img.Bounds()); n != 0 {
+		t.Errorf("RenderCard() empty layout has %d non-white pixels", n)
+	}
+}
+
+func TestRenderTextStaysInsideElement(t *testing.T) {
+	el := deck.LayoutElement{
+		ID: "name", Type: "text", Field: "name",
+		X: 10, Y: 10, Width: 40, Height: 10,
+		FontSize: 16, Color: "#000000", FontWeight: "bold", TextDecoration: "underline",
+	}
+	r := NewRenderer(150)
+	img, err := r.RenderCard(testDeck(el), deck.Card{Data: map[string]interface{}{"name": "Steel Sword"}}, SideFront)
+	if err != nil {
+		t.Fatalf("RenderCard() error = %v", err)
+	}
+
+	box := r.elementBox(el, testDeck(), 0)
+	if countInked(img, box) == 0 {
+		t.Fatal("text element drew nothing inside its box")
+	}
+	if n := countInked(img, img.Bounds()) - countInked(img, box); n != 0 {
+		t.Errorf("text element drew %d pixels outside its box", n)
+	}
+}
+
+func TestRenderTextDiffersPerCard(t *testing.T) {
+	d := testDeck(deck.LayoutElement{ID: "name", Type: "text", Field: "name", X: 5, Y: 5, Width: 50, Height: 10, FontSize: 14})
+	r := NewRenderer(100)
+
+	a, err := r.RenderCard(d, deck.Card{Data: map[string]interface{}{"name": "Rusty Dagger"}}, SideFront)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := r.RenderCard(d, deck.Card{Data: map[string]interface{}{"name": "Wooden Club"}}, SideFront)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(a.Pix) == string(b.Pix) {
+		t.Error("cards with different data rendered identical images")
+	}
+}
+
+func TestFieldValueWritesNumbersInFull(t *testing.T) {
+	c := deck.Card{Data: map[string]interface{}{"cost": float64(1000000), "weight": 2.5}}
+	if got := fieldValue(c, "cost"); got != "1000000" {
+		t.Errorf("fieldValue(cost) = %q, want %q", got, "1000000")
+	}
+	if got := fieldValue(c, "weight"); got != "2.5" {
+		t.Errorf("fieldValue(weight) = %q, want %q", got, "2.5")
+	}
+}
+
+func TestRenderShape(t *testing.T) {
+	el := deck.LayoutElement{
+		ID: "square", Type: "shape",
+		X: 10, Y: 10, Width: 20, Height: 20,
+		Points:      []deck.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}},
+		FillColor:   "#ff0000",
+		StrokeColor: "#0000ff",
+		StrokeWidth: 4,
+	}
+	r := NewRenderer(100)
+	img, err := r.RenderCard(testDeck(el), deck.Card{}, SideFront)
+	if err != nil {
+		t.Fatalf("RenderCard() error = %v", err)
+	}
+
+	box := r.elementBox(el, testDeck(), 0)
+	center := img.RGBAAt((box.Min.X+box.Max.X)/2, (box.Min.Y+box.Max.Y)/2)
+	if center != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("shape center = %v, want red", center)
+	}
+	edge := img.RGBAAt(box.Min.X, (box.Min.Y+box.Max.Y)/2)
+	if edge.B == 0 {
+		t.Errorf("shape edge = %v, want blue stroke", edge)
+	}
+	outside := img.RGBAAt(box.Min.X-2, box.Min.Y-2)
+	if !isWhite(outside) {
+		t.Errorf("stroke leaked outside element box: %v", outside)
+	}
+}
+
+func TestRenderImageFromBaseDir(t *testing.T) {
+	dir := t.TempDir()
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for i := range src.Pix {
+		src.Pix[i] = 0
+		if i%4 == 3 {
+			src.Pix[i] = 255 // Opaque black
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "images"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(dir, "images", "art.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, src); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	el := deck.LayoutElement{ID: "art", Type: "image", Field: "image", X: 0, Y: 0, Width: 40, Height: 40, ObjectFit: "contain"}
+	r := NewRenderer(100)
+	r.BaseDir = dir
+
+	img, err := r.RenderCard(testDeck(el), deck.Card{Data: map[string]interface{}{"image": "images/art.png"}}, SideFront)
+	if err != nil {
+		t.Fatalf("RenderCard() error = %v", err)
+	}
+
+	box := r.elementBox(el, testDeck(), 0)
+	// A 2:1 image contained in a square box is letterboxed top and bottom
+	if !isWhite(img.At(box.Min.X+box.Dx()/2, box.Min.Y+1)) {
+		t.Error("contain: expected white letterbox at the top of the box")
+	}
+	if isWhite(img.At(box.Min.X+box.Dx()/2, box.Min.Y+box.Dy()/2)) {
+		t.Error("contain: expected image in the middle of the box")
+	}
+
+	_, err = r.RenderCard(testDeck(el), deck.Card{Data: map[string]interface{}{"image": "images/missing.png"}}, SideFront)
+	if err == nil {
+		t.Error("RenderCard() with missing image returned no error")
+	}
+}
+
+func TestFitRect(t *testing.T) {
+	src := image.Rect(0, 0, 200, 100)
+	box := image.Rect(0, 0, 100, 100)
+
+	tests := []struct {
+		objectFit string
+		want      image.Rectangle
+	}{
+		{"fill", image.Rect(0, 0, 100, 100)},
+		{"contain", image.Rect(0, 25, 100, 75)},
+		{"", image.Rect(0, 25, 100, 75)},
+		{"cover", image.Rect(-50, 0, 150, 100)},
+	}
+
+	for _, tt := range tests {
+		if got := fitRect(src, box, tt.objectFit); got != tt.want {
+			t.Errorf("fitRect(%q) = %v, want %v", tt.objectFit, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutForFallback(t *testing.T) {
+	d := testDeck()
+	d.FrontStyles["bronze"] = deck.CardLayout{Name: "Bronze"}
+
+	if got := LayoutFor(d, deck.Card{FrontStyleID: "bronze"}, SideFront).Name; got != "Bronze" {
+		t.Errorf("LayoutFor() explicit style = %q, want Bronze", got)
+	}
+	if got := LayoutFor(d, deck.Card{FrontStyleID: "missing"}, SideFront).Name; got != "Default Front" {
+		t.Errorf("LayoutFor() missing style = %q, want Default Front", got)
+	}
+	if got := LayoutFor(d, deck.Card{}, SideBack).Name; got != "Default Back" {
+		t.Errorf("LayoutFor() back = %q, want Default Back", got)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   color.NRGBA
+		wantOK bool
+	}{
+		{"#fff", color.NRGBA{255, 255, 255, 255}, true},
+		{"#1a2b3c", color.NRGBA{0x1a, 0x2b, 0x3c, 255}, true},
+		{"#ff000080", color.NRGBA{255, 0, 0, 0x80}, true},
+		{"rgba(10, 20, 30, 0.5)", color.NRGBA{10, 20, 30, 127}, true},
+		{"none", color.NRGBA{}, false},
+		{"", blackColor, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseColor(tt.in, blackColor)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseColor(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRenderCardWithBleedExtendsBackgrounds(t *testing.T) {
+	background := deck.LayoutElement{
+		ID: "bg", Type: "shape",
+		X: 0, Y: 0, Width: 63.5, Height: 88.9,
+		Points:    []deck.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}},
+		FillColor: "#0000ff",
+	}
+	title := deck.LayoutElement{ID: "title", Type: "text", StaticText: "Title", X: 0, Y: 40, Width: 63.5, Height: 10, Color: "#ffffff"}
+
+	r := NewRenderer(100)
+	img, err := r.RenderCardWithBleed(testDeck(background, title), deck.Card{}, SideFront, 3)
+	if err != nil {
+		t.Fatalf("RenderCardWithBleed() error = %v", err)
+	}
+
+	want := image.Pt(int(math.Round(69.5*100/25.4)), int(math.Round(94.9*100/25.4)))
+	if got := img.Bounds().Size(); got != want {
+		t.Errorf("RenderCardWithBleed() size = %v, want %v", got, want)
+	}
+
+	// The background reaches the outer corners of the bleed
+	for _, p := range []image.Point{{0, 0}, {want.X - 1, want.Y - 1}} {
+		if c := img.RGBAAt(p.X, p.Y); c != (color.RGBA{B: 255, A: 255}) {
+			t.Errorf("bleed corner %v = %v, want background blue", p, c)
+		}
+	}
+
+	// Text keeps its trim-relative position instead of stretching
+	box := r.elementBox(title, testDeck(), 3)
+	if box.Min.X != int(math.Round(3*100/25.4)) {
+		t.Errorf("text box starts at x=%d, want it offset by the bleed", box.Min.X)
+	}
+}
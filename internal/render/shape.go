@@ -0,0 +1,114 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"card_wizard/internal/deck"
+
+	"golang.org/x/image/vector"
+)
+
+var defaultShapeFill = mustColor("#cccccc")
+
+// drawShape fills and strokes the element's polygon, with points normalized to the element box
+func (r *Renderer) drawShape(dst *image.RGBA, box image.Rectangle, el deck.LayoutElement) {
+	if len(el.Points) < 3 || box.Empty() {
+		return
+	}
+
+	w, h := float32(box.Dx()), float32(box.Dy())
+	points := make([][2]float32, len(el.Points))
+	for i, p := range el.Points {
+		points[i] = [2]float32{float32(p.X) * w, float32(p.Y) * h}
+	}
+
+	if fill, ok := parseColor(el.FillColor, defaultShapeFill); ok {
+		z := vector.NewRasterizer(box.Dx(), box.Dy())
+		addPolygon(z, points)
+		fillMask(dst, box, z, fill)
+	}
+
+	// Stroke is non-scaling in the preview, so its width is in CSS px
+	if el.StrokeWidth <= 0 || el.StrokeColor == "" {
+		return
+	}
+	stroke, ok := parseColor(el.StrokeColor, blackColor)
+	if !ok {
+		return
+	}
+
+	half := float32(r.cssPx(el.StrokeWidth) / 2)
+	z := vector.NewRasterizer(box.Dx(), box.Dy())
+	for i, p0 := range points {
+		p1 := points[(i+1)%len(points)]
+		addSegment(z, p0, p1, half)
+		addDisc(z, p0, half)
+	}
+	fillMask(dst, box, z, stroke)
+}
+
+// fillMask rasterizes into an alpha mask first so drawing is clipped to dst
+func fillMask(dst *image.RGBA, box image.Rectangle, z *vector.Rasterizer, c color.Color) {
+	mask := image.NewAlpha(image.Rect(0, 0, box.Dx(), box.Dy()))
+	z.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	draw.DrawMask(dst, box, image.NewUniform(c), image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+// addPolygon adds a closed path, always wound the same way so overlapping parts accumulate
+func addPolygon(z *vector.Rasterizer, points [][2]float32) {
+	if signedArea(points) < 0 {
+		reversed := make([][2]float32, len(points))
+		for i, p := range points {
+			reversed[len(points)-1-i] = p
+		}
+		points = reversed
+	}
+
+	z.MoveTo(points[0][0], points[0][1])
+	for _, p := range points[1:] {
+		z.LineTo(p[0], p[1])
+	}
+	z.ClosePath()
+}
+
+// addSegment adds a rectangle of half-width hw around the line p0-p1
+func addSegment(z *vector.Rasterizer, p0, p1 [2]float32, hw float32) {
+	dx, dy := p1[0]-p0[0], p1[1]-p0[1]
+	length := float32(math.Hypot(float64(dx), float64(dy)))
+	if length == 0 {
+		return
+	}
+	nx, ny := -dy/length*hw, dx/length*hw
+	addPolygon(z, [][2]float32{
+		{p0[0] + nx, p0[1] + ny},
+		{p1[0] + nx, p1[1] + ny},
+		{p1[0] - nx, p1[1] - ny},
+		{p0[0] - nx, p0[1] - ny},
+	})
+}
+
+// addDisc adds a small polygonal disc used to round the joins between stroke segments
+func addDisc(z *vector.Rasterizer, center [2]float32, radius float32) {
+	const sides = 16
+	points := make([][2]float32, sides)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / sides
+		points[i] = [2]float32{
+			center[0] + radius*float32(math.Cos(angle)),
+			center[1] + radius*float32(math.Sin(angle)),
+		}
+	}
+	addPolygon(z, points)
+}
+
+func signedArea(points [][2]float32) float32 {
+	var area float32
+	for i, p0 := range points {
+		p1 := points[(i+1)%len(points)]
+		area += p0[0]*p1[1] - p1[0]*p0[1]
+	}
+	return area / 2
+}
@@ -0,0 +1,262 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"strings"
+	"sync"
+
+	"card_wizard/internal/deck"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const defaultFontSize = 12.0 // CSS px, matches the preview default
+
+// monospaceFamilies are CSS family names that fall back to Go Mono instead of Go Sans
+var monospaceFamilies = map[string]bool{
+	"monospace":   true,
+	"courier":     true,
+	"courier new": true,
+	"consolas":    true,
+	"menlo":       true,
+	"monaco":      true,
+}
+
+// fontVariant indexes the four weight/style combinations of a family
+type fontVariant int
+
+const (
+	variantRegular fontVariant = iota
+	variantBold
+	variantItalic
+	variantBoldItalic
+)
+
+type faceKey struct {
+	font *opentype.Font
+	size float64
+}
+
+// fontSet holds the built-in fallback fonts plus any registered custom families
+type fontSet struct {
+	mu     sync.Mutex
+	sans   [4]*opentype.Font
+	mono   [4]*opentype.Font
+	custom map[string]*opentype.Font // keyed by lowercase family name
+	faces  map[faceKey]font.Face
+}
+
+func newFontSet() *fontSet {
+	return &fontSet{
+		sans:   [4]*opentype.Font{mustParse(goregular.TTF), mustParse(gobold.TTF), mustParse(goitalic.TTF), mustParse(gobolditalic.TTF)},
+		mono:   [4]*opentype.Font{mustParse(gomono.TTF), mustParse(gomonobold.TTF), mustParse(gomonoitalic.TTF), mustParse(gomonobolditalic.TTF)},
+		custom: make(map[string]*opentype.Font),
+		faces:  make(map[faceKey]font.Face),
+	}
+}
+
+func mustParse(data []byte) *opentype.Font {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func (fs *fontSet) register(family string, data []byte) error {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse font %s: %w", family, err)
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.custom[normalizeFamily(family)] = f
+	return nil
+}
+
+// face resolves a CSS font-family list to a sized face, trying each family in order
+func (fs *fontSet) face(families string, variant fontVariant, sizePx float64) (font.Face, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	chosen := fs.sans[variant]
+	for _, family := range strings.Split(families, ",") {
+		name := normalizeFamily(family)
+		if f, ok := fs.custom[name]; ok {
+			chosen = f
+			break
+		}
+		if monospaceFamilies[name] {
+			chosen = fs.mono[variant]
+			break
+		}
+	}
+
+	key := faceKey{font: chosen, size: sizePx}
+	if face, ok := fs.faces[key]; ok {
+		return face, nil
+	}
+
+	// DPI 72 makes Size a pixel value
+	face, err := opentype.NewFace(chosen, &opentype.FaceOptions{
+		Size:    sizePx,
+		DPI:     72,
+		Hinting: font.HintingNone,
+	})
+	if err != nil {
+		return nil, err
+	}
+	fs.faces[key] = face
+	return face, nil
+}
+
+// HasFonts reports whether every custom font of the deck that the text on one
+// side of a card asks for is registered, so the card renders as it previews
+func (r *Renderer) HasFonts(d deck.Deck, c deck.Card, side Side) bool {
+	r.fonts.mu.Lock()
+	defer r.fonts.mu.Unlock()
+
+	custom := make(map[string]bool, len(d.CustomFonts))
+	for _, f := range d.CustomFonts {
+		custom[normalizeFamily(f.Family)] = true
+	}
+	for _, el := range LayoutFor(d, c, side).Elements {
+		if el.Type == "image" || el.Type == "shape" {
+			continue
+		}
+		for _, family := range strings.Split(el.FontFamily, ",") {
+			name := normalizeFamily(family)
+			if _, ok := r.fonts.custom[name]; custom[name] && !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func normalizeFamily(family string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(family), `"'`))
+}
+
+// drawText lays out wrapped text inside the element box using its alignment settings
+func (r *Renderer) drawText(dst *image.RGBA, box image.Rectangle, el deck.LayoutElement, c deck.Card) error {
+	text := el.StaticText
+	if el.Field != "" {
+		text = fieldValue(c, el.Field)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	fontSize := el.FontSize
+	if fontSize <= 0 {
+		fontSize = defaultFontSize
+	}
+
+	variant := variantRegular
+	bold := el.FontWeight == "bold"
+	italic := el.FontStyle == "italic"
+	switch {
+	case bold && italic:
+		variant = variantBoldItalic
+	case bold:
+		variant = variantBold
+	case italic:
+		variant = variantItalic
+	}
+
+	sizePx := r.cssPx(fontSize)
+	face, err := r.fonts.face(el.FontFamily, variant, sizePx)
+	if err != nil {
+		return err
+	}
+
+	textColor, ok := parseColor(el.Color, blackColor)
+	if !ok {
+		return nil
+	}
+
+	lines := wrapText(face, text, fixed.I(box.Dx()))
+	metrics := face.Metrics()
+	lineHeight := metrics.Height
+	totalHeight := lineHeight * fixed.Int26_6(len(lines))
+
+	// Vertical placement, default is middle
+	top := fixed.I(box.Min.Y)
+	switch el.VerticalAlign {
+	case "top":
+	case "bottom":
+		top = fixed.I(box.Max.Y) - totalHeight
+	default:
+		top += (fixed.I(box.Dy()) - totalHeight) / 2
+	}
+
+	drawer := &font.Drawer{Dst: dst, Src: image.NewUniform(textColor), Face: face}
+	for i, line := range lines {
+		width := drawer.MeasureString(line)
+
+		// Horizontal placement, default is center
+		x := fixed.I(box.Min.X)
+		switch el.TextAlign {
+		case "left":
+		case "right":
+			x = fixed.I(box.Max.X) - width
+		default:
+			x += (fixed.I(box.Dx()) - width) / 2
+		}
+
+		baseline := top + lineHeight*fixed.Int26_6(i) + metrics.Ascent
+		drawer.Dot = fixed.Point26_6{X: x, Y: baseline}
+		drawer.DrawString(line)
+
+		if el.TextDecoration == "underline" && width > 0 {
+			thickness := max(1, int(sizePx/15))
+			y := (baseline + metrics.Descent/3).Round()
+			underline := image.Rect(x.Round(), y, (x + width).Round(), y+thickness)
+			draw.Draw(dst, underline, image.NewUniform(textColor), image.Point{}, draw.Over)
+		}
+	}
+
+	return nil
+}
+
+// wrapText splits text into lines that fit maxWidth, keeping explicit newlines (CSS pre-wrap)
+func wrapText(face font.Face, text string, maxWidth fixed.Int26_6) []string {
+	var lines []string
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		current := words[0]
+		for _, word := range words[1:] {
+			candidate := current + " " + word
+			if font.MeasureString(face, candidate) <= maxWidth {
+				current = candidate
+				continue
+			}
+			// A single word wider than the box overflows, as it does in the browser
+			lines = append(lines, current)
+			current = word
+		}
+		lines = append(lines, current)
+	}
+
+	return lines
+}
@@ -0,0 +1,146 @@
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const (
+	sniffBytes   = 64 * 1024 // Text examined when detecting the delimiter
+	sniffRecords = 20
+)
+
+// delimiters are the separators tried when detecting a file's delimiter, in order
+// of preference on a tie
+var delimiters = []rune{',', ';', '\t', '|'}
+
+// delimitedSource is a CSV or TSV file, exposed as a single sheet named after the file
+type delimitedSource struct {
+	name string
+	rows [][]string
+}
+
+func openDelimited(path string, delimiter rune) (Source, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ReadDelimited(data, delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &delimitedSource{
+		name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		rows: rows,
+	}, nil
+}
+
+func (s *delimitedSource) Sheets() []string {
+	return []string{s.name}
+}
+
+func (s *delimitedSource) Rows(sheet string) ([][]string, error) {
+	if sheet != s.name && sheet != "" {
+		return nil, fmt.Errorf("sheet %s does not exist", sheet)
+	}
+	return s.rows, nil
+}
+
+func (s *delimitedSource) Close() error {
+	return nil
+}
+
+// ReadDelimited parses delimited text into rows. The encoding is detected from a
+// byte order mark, falling back to UTF-8 and then Windows-1252. A zero delimiter is
+// detected from the content. Quoted cells may span lines.
+func ReadDelimited(data []byte, delimiter rune) ([][]string, error) {
+	text, err := decodeText(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if delimiter == 0 {
+		delimiter = DetectDelimiter(text)
+	}
+
+	rows, err := newCSVReader(strings.NewReader(text), delimiter).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse delimited file: %w", err)
+	}
+	return rows, nil
+}
+
+func newCSVReader(r io.Reader, delimiter rune) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1 // Rows may be ragged
+	reader.LazyQuotes = true    // Spreadsheet exports are not always strict
+	return reader
+}
+
+// decodeText converts file bytes to a string, removing any byte order mark
+func decodeText(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:]), nil
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		decoded, err := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode UTF-16 text: %w", err)
+		}
+		return string(decoded), nil
+	case utf8.Valid(data):
+		return string(data), nil
+	}
+
+	// Excel on Windows saves "CSV" without a BOM in the system code page
+	decoded, err := charmap.Windows1252.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode text: %w", err)
+	}
+	return string(decoded), nil
+}
+
+// DetectDelimiter picks the separator that splits the start of the text into the
+// most columns while keeping every sampled row the same width as the header
+func DetectDelimiter(text string) rune {
+	if len(text) > sniffBytes {
+		text = text[:sniffBytes]
+	}
+
+	best, bestScore := delimiters[0], 0
+	for _, d := range delimiters {
+		reader := newCSVReader(strings.NewReader(text), d)
+		header, err := reader.Read()
+		if err != nil || len(header) < 2 {
+			continue
+		}
+
+		consistent := 1
+		for i := 0; i < sniffRecords; i++ {
+			record, err := reader.Read()
+			if err != nil {
+				break // End of sample, or a quoted cell cut off by it
+			}
+			if len(record) == len(header) {
+				consistent++
+			}
+		}
+
+		if score := consistent * len(header); score > bestScore {
+			best, bestScore = d, score
+		}
+	}
+	return best
+}
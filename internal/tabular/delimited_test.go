@@ -0,0 +1,135 @@
+package tabular
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestReadDelimited(t *testing.T) {
+	utf16le, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte("name\tcost\nÉpée\t3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp1252, err := charmap.Windows1252.NewEncoder().Bytes([]byte("name;cost\nCafé;2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		delimiter rune
+		want      [][]string
+	}{
+		{
+			name: "Comma",
+			data: []byte("name,cost\nSword,3\nShield,2\n"),
+			want: [][]string{{"name", "cost"}, {"Sword", "3"}, {"Shield", "2"}},
+		},
+		{
+			name: "Semicolon detected",
+			data: []byte("name;description\nSword;Sharp, pointy\nShield;Round, heavy\n"),
+			want: [][]string{{"name", "description"}, {"Sword", "Sharp, pointy"}, {"Shield", "Round, heavy"}},
+		},
+		{
+			name: "Tab detected",
+			data: []byte("name\tcost\r\nSword\t3\r\n"),
+			want: [][]string{{"name", "cost"}, {"Sword", "3"}},
+		},
+		{
+			name:      "Explicit tab",
+			data:      []byte("a,b\tc\n1,2\t3\n"),
+			delimiter: '\t',
+			want:      [][]string{{"a,b", "c"}, {"1,2", "3"}},
+		},
+		{
+			name: "UTF-8 BOM",
+			data: append([]byte{0xEF, 0xBB, 0xBF}, []byte("name,cost\nSword,3\n")...),
+			want: [][]string{{"name", "cost"}, {"Sword", "3"}},
+		},
+		{
+			name: "UTF-16 with BOM",
+			data: utf16le,
+			want: [][]string{{"name", "cost"}, {"Épée", "3"}},
+		},
+		{
+			name: "Windows-1252",
+			data: cp1252,
+			want: [][]string{{"name", "cost"}, {"Café", "2"}},
+		},
+		{
+			name: "Quoted multiline cells",
+			data: []byte("name,text\n\"Sword\",\"Deal 3 damage.\r\nDraw a card.\"\n\"Say \"\"hi\"\"\",x\n"),
+			want: [][]string{{"name", "text"}, {"Sword", "Deal 3 damage.\nDraw a card."}, {`Say "hi"`, "x"}},
+		},
+		{
+			name: "Ragged rows",
+			data: []byte("name,cost,tier\nSword,3\n"),
+			want: [][]string{{"name", "cost", "tier"}, {"Sword", "3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadDelimited(tt.data, tt.delimiter)
+			if err != nil {
+				t.Fatalf("ReadDelimited() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDelimited() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenDelimitedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weapons.tsv")
+	if err := os.WriteFile(path, []byte("name,alias\tcost\nSword\t3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer src.Close()
+
+	if got := src.Sheets(); !reflect.DeepEqual(got, []string{"weapons"}) {
+		t.Errorf("Sheets() = %v, want [weapons]", got)
+	}
+	// .tsv files are always split on tabs
+	headers, err := Headers(src, "weapons")
+	if err != nil {
+		t.Fatalf("Headers() error = %v", err)
+	}
+	if !reflect.DeepEqual(headers, []string{"name,alias", "cost"}) {
+		t.Errorf("Headers() = %q, want [name,alias cost]", headers)
+	}
+}
+
+func TestOpenRejectsUnsupportedFiles(t *testing.T) {
+	for _, name := range []string{"old.xls", "notes.docx"} {
+		if _, err := Open(filepath.Join(t.TempDir(), name)); err == nil {
+			t.Errorf("Open(%s) error = nil, want an error", name)
+		}
+	}
+}
+
+func TestExtensionsOpen(t *testing.T) {
+	// Every type the file dialogs offer gets past the extension check
+	dir := t.TempDir()
+	for _, ext := range Extensions {
+		if _, err := Open(filepath.Join(dir, "missing"+ext)); err == nil || strings.Contains(err.Error(), "unsupported file type") {
+			t.Errorf("Open(missing%s) error = %v, want the file to be looked for", ext, err)
+		}
+	}
+	if !strings.Contains(DialogPattern(), "*.tab") {
+		t.Errorf("DialogPattern() = %q, want it to offer .tab files", DialogPattern())
+	}
+}
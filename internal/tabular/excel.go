@@ -0,0 +1,179 @@
+package tabular
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// excelSource reads .xlsx workbooks
+type excelSource struct {
+	f *excelize.File
+}
+
+func openExcel(path string) (Source, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &excelSource{f: f}, nil
+}
+
+func (s *excelSource) Sheets() []string {
+	return s.f.GetSheetList()
+}
+
+func (s *excelSource) Rows(sheet string) ([][]string, error) {
+	return s.f.GetRows(sheet)
+}
+
+// Pictures returns the images placed over or in the sheet's cells
+func (s *excelSource) Pictures(sheet string) ([]Picture, error) {
+	cells, err := s.f.GetPictureCells(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var pictures []Picture
+	for _, cell := range cells {
+		col, row, err := excelize.CellNameToCoordinates(cell)
+		if err != nil {
+			return nil, err
+		}
+		pics, err := s.f.GetPictures(sheet, cell)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read images in %s: %w", cell, err)
+		}
+		for _, pic := range pics {
+			p := Picture{Row: row - 1, Col: col - 1, Ext: pic.Extension, Data: pic.File}
+			if pic.Format != nil {
+				p.Name = pic.Format.AltText
+			}
+			pictures = append(pictures, p)
+		}
+	}
+	return pictures, nil
+}
+
+func (s *excelSource) Close() error {
+	return s.f.Close()
+}
+
+// WriteXLSX writes the sheets as an Excel workbook, with their pictures placed
+// over the cells they belong to. The first visible sheet is made active.
+func WriteXLSX(w io.Writer, sheets []Sheet) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for _, sheet := range sheets {
+		if _, err := f.NewSheet(sheet.Name); err != nil {
+			return fmt.Errorf("failed to create sheet %s: %w", sheet.Name, err)
+		}
+
+		for i, row := range sheet.Rows {
+			for j, val := range row {
+				cell, err := excelize.CoordinatesToCellName(j+1, i+1)
+				if err != nil {
+					return err
+				}
+				if err := f.SetCellValue(sheet.Name, cell, val); err != nil {
+					return fmt.Errorf("failed to write sheet %s: %w", sheet.Name, err)
+				}
+			}
+		}
+
+		if err := addPictures(f, sheet); err != nil {
+			return fmt.Errorf("failed to add images to sheet %s: %w", sheet.Name, err)
+		}
+	}
+
+	// Drop the default sheet unless one of ours replaced it
+	if !hasSheet(sheets, "Sheet1") {
+		if err := f.DeleteSheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+
+	activeSet := false
+	for _, sheet := range sheets {
+		if sheet.Hidden {
+			if err := f.SetSheetVisible(sheet.Name, false); err != nil {
+				return err
+			}
+			continue
+		}
+		if !activeSet {
+			index, err := f.GetSheetIndex(sheet.Name)
+			if err != nil {
+				return err
+			}
+			f.SetActiveSheet(index)
+			activeSet = true
+		}
+	}
+
+	return f.Write(w)
+}
+
+func hasSheet(sheets []Sheet, name string) bool {
+	for _, sheet := range sheets {
+		if sheet.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addPictures places a sheet's pictures over their cells, growing rows and
+// columns so each picture fits in its cell
+func addPictures(f *excelize.File, sheet Sheet) error {
+	rowHeights := make(map[int]float64) // Points
+	colWidths := make(map[int]float64)  // Characters
+	for _, p := range sheet.Pictures {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(p.Data))
+		if err != nil {
+			return fmt.Errorf("image %s: %w", p.Name, err)
+		}
+		// Excel rows are measured in points and columns in characters of about 7 pixels
+		rowHeights[p.Row] = max(rowHeights[p.Row], min(float64(cfg.Height)*0.75+4, 409))
+		colWidths[p.Col] = max(colWidths[p.Col], min(float64(cfg.Width)/7+2, 255))
+
+		cell, err := excelize.CoordinatesToCellName(p.Col+1, p.Row+1)
+		if err != nil {
+			return err
+		}
+		err = f.AddPictureFromBytes(sheet.Name, cell, &excelize.Picture{
+			Extension: p.Ext,
+			File:      p.Data,
+			Format: &excelize.GraphicOptions{
+				AltText:         p.Name,
+				OffsetX:         2,
+				OffsetY:         2,
+				LockAspectRatio: true,
+				Positioning:     "oneCell",
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for row, height := range rowHeights {
+		if err := f.SetRowHeight(sheet.Name, row+1, height); err != nil {
+			return err
+		}
+	}
+	for col, width := range colWidths {
+		name, err := excelize.ColumnNumberToName(col + 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetColWidth(sheet.Name, name, name, width); err != nil {
+			return err
+		}
+	}
+	return nil
+}
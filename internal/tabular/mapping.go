@@ -0,0 +1,218 @@
+package tabular
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"card_wizard/internal/deck"
+)
+
+// Mapping keys naming the columns that feed card settings rather than card data
+const (
+	MapID             = "id" // Column of card IDs, used as written
+	MapGenerateIDFrom = "generateIdFrom"
+	MapCount          = "count"
+	MapFrontStyle     = "frontStyle"
+	MapBackStyle      = "backStyle"
+)
+
+var slugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify lowercases s, replaces runs of other characters with a dash and trims dashes
+func Slugify(s string) string {
+	slug := strings.ToLower(s)
+	slug = slugRegex.ReplaceAllString(slug, "-")
+	return strings.Trim(slug, "-")
+}
+
+// MapCards turns sheet rows, header first, into cards using a column mapping
+func MapCards(rows [][]string, mapping map[string]string) ([]deck.Card, error) {
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("file is empty or missing header")
+	}
+
+	headers := rows[0]
+	headerMap := make(map[string]int)
+	for i, h := range headers {
+		headerMap[h] = i
+	}
+
+	var cards []deck.Card
+
+	for i, row := range rows {
+		if i == 0 {
+			continue
+		}
+
+		// Helper to safely get cell value
+		getCell := func(colName string) string {
+			if colName == "" {
+				return ""
+			}
+			idx, ok := headerMap[colName]
+			if !ok || idx >= len(row) {
+				return ""
+			}
+			return row[idx]
+		}
+
+		id := strings.TrimSpace(getCell(mapping[MapID]))
+
+		// Generate from column if specified
+		if id == "" && mapping[MapGenerateIDFrom] != "" {
+			if raw := getCell(mapping[MapGenerateIDFrom]); raw != "" {
+				id = Slugify(raw)
+			}
+		}
+
+		// Fallback
+		if id == "" {
+			id = fmt.Sprintf("card-%d", i)
+		}
+
+		// ImportCards reports counts that fall back
+		count, _ := parseCount(getCell(mapping[MapCount]))
+
+		card := deck.Card{
+			ID:           id,
+			Count:        count,
+			FrontStyleID: getCell(mapping[MapFrontStyle]),
+			BackStyleID:  getCell(mapping[MapBackStyle]),
+			Data:         make(map[string]interface{}),
+		}
+
+		// Map everything else to Data, excluding the mapped system columns
+		systemCols := map[string]bool{
+			mapping[MapID]:         true,
+			mapping[MapCount]:      true,
+			mapping[MapFrontStyle]: true,
+			mapping[MapBackStyle]:  true,
+		}
+		// NOTE: We explicilty DO NOT add mapping["generateIdFrom"] to systemCols
+		// because the user wants to preserve that column in the data.
+
+		for j, cell := range row {
+			if j < len(headers) {
+				header := headers[j]
+				if !systemCols[header] {
+					card.Data[header] = cell
+				}
+			}
+		}
+
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
+
+// parseCount reads a count cell. An empty cell is one copy. Counts that are not
+// whole numbers fall back to 1 and negative ones to 0, with an error saying so.
+func parseCount(cell string) (int, error) {
+	cell = strings.TrimSpace(cell)
+	if cell == "" {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(cell)
+	switch {
+	case err != nil:
+		return 1, fmt.Errorf("count %q is not a whole number; using 1", cell)
+	case n < 0:
+		return 0, fmt.Errorf("count %d is negative; using 0", n)
+	}
+	return n, nil
+}
+
+// StandardHeaders are the card setting columns exported before a deck's fields
+var StandardHeaders = []string{"ID", "Count", "Front Style", "Back Style"}
+
+// CardRows returns a header row followed by one row per card of a deck, for
+// export. Styles are written by name where the name identifies them.
+func CardRows(d deck.Deck) [][]interface{} {
+	header := make([]interface{}, 0, len(StandardHeaders)+len(d.Fields))
+	for _, h := range StandardHeaders {
+		header = append(header, h)
+	}
+	for _, field := range d.Fields {
+		header = append(header, field.Name)
+	}
+
+	rows := [][]interface{}{header}
+	for _, card := range d.Cards {
+		row := []interface{}{
+			card.ID,
+			card.Count,
+			StyleName(card.FrontStyleID, d.FrontStyles),
+			StyleName(card.BackStyleID, d.BackStyles),
+		}
+		for _, field := range d.Fields {
+			// Typed values become number and boolean cells
+			val := card.Data[field.Name]
+			if coerced, err := field.Coerce(val); err == nil {
+				val = coerced
+			}
+			row = append(row, val)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// StyleName returns the name of a style for export, or its ID when the style is
+// unknown, unnamed or shares its name with another style
+func StyleName(id string, styles map[string]deck.CardLayout) string {
+	style, ok := styles[id]
+	if !ok || style.Name == "" {
+		return id
+	}
+	for otherID, other := range styles {
+		if otherID != id && strings.EqualFold(other.Name, style.Name) {
+			return id
+		}
+	}
+	// A name that is another style's ID would resolve to that style on import
+	if _, clash := styles[style.Name]; clash {
+		return id
+	}
+	return style.Name
+}
+
+// sortedStyles returns the style IDs in order
+func sortedStyles(styles map[string]deck.CardLayout) []string {
+	ids := make([]string, 0, len(styles))
+	for id := range styles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SheetName returns a unique sheet name for a deck, falling back to "Deck N" and
+// cutting it to maxLen characters when maxLen > 0. used collects the names taken.
+func SheetName(name string, index int, maxLen int, used map[string]bool) string {
+	if name == "" {
+		name = fmt.Sprintf("Deck %d", index+1)
+	}
+
+	truncate := func(s string) string {
+		if r := []rune(s); maxLen > 0 && len(r) > maxLen {
+			return string(r[:maxLen])
+		}
+		return s
+	}
+
+	sheet := truncate(name)
+	for n := 2; used[strings.ToLower(sheet)]; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		sheet = truncate(name)
+		if maxLen > 0 && len([]rune(sheet))+len(suffix) > maxLen {
+			sheet = string([]rune(sheet)[:maxLen-len(suffix)])
+		}
+		sheet += suffix
+	}
+	used[strings.ToLower(sheet)] = true
+	return sheet
+}
@@ -0,0 +1,120 @@
+package tabular
+
+import (
+	"reflect"
+	"testing"
+
+	"card_wizard/internal/deck"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Rusty Dagger":    "rusty-dagger",
+		"  Bow & Arrow! ": "bow-arrow",
+		"Épée":            "p-e",
+		"Level 2 -- Boss": "level-2-boss",
+	}
+	for in, want := range tests {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMapCards(t *testing.T) {
+	rows := [][]string{
+		{"Name", "Copies", "Front", "Cost"},
+		{"Rusty Dagger", "3", "bronze", "2"},
+		{"", "", "", "1"},
+	}
+	mapping := map[string]string{
+		MapGenerateIDFrom: "Name",
+		MapCount:          "Copies",
+		MapFrontStyle:     "Front",
+	}
+
+	cards, err := MapCards(rows, mapping)
+	if err != nil {
+		t.Fatalf("MapCards() error = %v", err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("MapCards() returned %d cards, want 2", len(cards))
+	}
+
+	c := cards[0]
+	if c.ID != "rusty-dagger" || c.Count != 3 || c.FrontStyleID != "bronze" {
+		t.Errorf("card 0 = %+v", c)
+	}
+	// The ID column stays in the data; mapped system columns do not
+	if c.Data["Name"] != "Rusty Dagger" || c.Data["Cost"] != "2" {
+		t.Errorf("card 0 data = %v", c.Data)
+	}
+	if _, ok := c.Data["Copies"]; ok {
+		t.Error("count column was copied into card data")
+	}
+
+	// Blank ID source and count fall back to the row number and one copy
+	if cards[1].ID != "card-2" || cards[1].Count != 1 {
+		t.Errorf("card 1 ID = %q, Count = %d, want card-2 and 1", cards[1].ID, cards[1].Count)
+	}
+}
+
+func TestMapCardsCountFallbacks(t *testing.T) {
+	rows := [][]string{{"Copies"}, {"abc"}, {"3x"}, {"-2"}, {" 4 "}}
+	cards, err := MapCards(rows, map[string]string{MapCount: "Copies"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The same fallbacks ImportCards reports
+	for i, want := range []int{1, 1, 0, 4} {
+		if cards[i].Count != want {
+			t.Errorf("MapCards() count %q = %d, want %d", rows[i+1][0], cards[i].Count, want)
+		}
+	}
+}
+
+func TestMapCardsNeedsData(t *testing.T) {
+	if _, err := MapCards([][]string{{"Name"}}, nil); err == nil {
+		t.Error("MapCards() with only a header: error = nil, want an error")
+	}
+}
+
+func TestCardRowsWritesTypedValues(t *testing.T) {
+	fields := []deck.FieldDefinition{
+		{Name: "Cost", Type: deck.FieldNumber},
+		{Name: "Flying", Type: deck.FieldBoolean},
+		{Name: "Text", Type: deck.FieldText},
+	}
+	cards := []deck.Card{{ID: "a", Count: 2, Data: map[string]interface{}{"Cost": "1.5", "Flying": "TRUE", "Text": "3"}}}
+
+	rows := CardRows(deck.Deck{Cards: cards, Fields: fields})
+	want := [][]interface{}{
+		{"ID", "Count", "Front Style", "Back Style", "Cost", "Flying", "Text"},
+		{"a", 2, "", "", 1.5, true, "3"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("CardRows() = %#v, want %#v", rows, want)
+	}
+}
+
+func TestStyleName(t *testing.T) {
+	styles := map[string]deck.CardLayout{
+		"style-1": {Name: "Gold"},
+		"style-2": {Name: "Silver"},
+		"style-3": {Name: "silver"},
+		"style-4": {},
+		"style-5": {Name: "style-1"},
+	}
+	tests := map[string]string{
+		"style-1": "Gold",
+		"style-2": "style-2", // Shares its name
+		"style-4": "style-4", // Unnamed
+		"style-5": "style-5", // Named like another style's ID
+		"missing": "missing",
+	}
+	for id, want := range tests {
+		if got := StyleName(id, styles); got != want {
+			t.Errorf("StyleName(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
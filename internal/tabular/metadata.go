@@ -0,0 +1,148 @@
+package tabular
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"card_wizard/internal/deck"
+)
+
+// MetadataSheet is the hidden sheet an export uses to describe its deck sheets,
+// so the workbook can be imported again without mapping columns by hand
+const MetadataSheet = "_cardwizard"
+
+const metadataVersion = 1
+
+// Metadata row kinds
+const (
+	metaFormat     = "format"
+	metaDeck       = "deck"
+	metaField      = "field"
+	metaFrontStyle = "frontStyle"
+	metaBackStyle  = "backStyle"
+)
+
+var metadataHeader = []interface{}{"Kind", "Sheet", "Name", "Value", "Options", "Required"}
+
+// SheetMetadata describes a deck sheet written by an export
+type SheetMetadata struct {
+	DeckID      string                 `json:"deckId"`
+	DeckName    string                 `json:"deckName"`
+	Fields      []deck.FieldDefinition `json:"fields"`
+	FrontStyles map[string]string      `json:"frontStyles"` // Style name -> ID
+	BackStyles  map[string]string      `json:"backStyles"`
+}
+
+// Mapping returns the column mapping for the sheet's standard columns. Rows are
+// matched to existing cards by their ID.
+func (m *SheetMetadata) Mapping() map[string]string {
+	return map[string]string{
+		MapID:         StandardHeaders[0],
+		MapKey:        StandardHeaders[0],
+		MapCount:      StandardHeaders[1],
+		MapFrontStyle: StandardHeaders[2],
+		MapBackStyle:  StandardHeaders[3],
+	}
+}
+
+// exportedStyleID returns the ID an export wrote a style name for, if that style
+// exists in styles, or the name unchanged
+func exportedStyleID(name string, exported map[string]string, styles map[string]deck.CardLayout) string {
+	if id, ok := exported[name]; ok {
+		if _, exists := styles[id]; exists {
+			return id
+		}
+	}
+	return name
+}
+
+// Workbook returns a sheet per deck, named after the deck and cut to
+// maxSheetName characters, followed by the hidden metadata sheet
+func Workbook(decks []deck.Deck, maxSheetName int) []Sheet {
+	used := map[string]bool{MetadataSheet: true}
+	meta := [][]interface{}{
+		metadataHeader,
+		{metaFormat, "", "version", metadataVersion},
+	}
+
+	var sheets []Sheet
+	for i, d := range decks {
+		name := SheetName(d.Name, i, maxSheetName, used)
+		sheets = append(sheets, Sheet{Name: name, Rows: CardRows(d)})
+
+		meta = append(meta, []interface{}{metaDeck, name, d.Name, d.ID})
+		for _, f := range d.Fields {
+			var required interface{}
+			if f.Required {
+				required = true
+			}
+			meta = append(meta, []interface{}{metaField, name, f.Name, f.Type, strings.Join(f.Options, "\n"), required})
+		}
+		for _, s := range sortedStyles(d.FrontStyles) {
+			meta = append(meta, []interface{}{metaFrontStyle, name, StyleName(s, d.FrontStyles), s})
+		}
+		for _, s := range sortedStyles(d.BackStyles) {
+			meta = append(meta, []interface{}{metaBackStyle, name, StyleName(s, d.BackStyles), s})
+		}
+	}
+
+	return append(sheets, Sheet{Name: MetadataSheet, Rows: meta, Hidden: true})
+}
+
+// ReadMetadata reads the metadata sheet of an exported workbook, keyed by sheet
+// name. It returns nil when the file has no metadata sheet.
+func ReadMetadata(src Source) (map[string]*SheetMetadata, error) {
+	found := false
+	for _, name := range src.Sheets() {
+		if name == MetadataSheet {
+			found = true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	rows, err := src.Rows(MetadataSheet)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := make(map[string]*SheetMetadata)
+	sheet := func(name string) *SheetMetadata {
+		if sheets[name] == nil {
+			sheets[name] = &SheetMetadata{FrontStyles: map[string]string{}, BackStyles: map[string]string{}}
+		}
+		return sheets[name]
+	}
+
+	for i, row := range rows {
+		if i == 0 || len(row) < 4 {
+			continue
+		}
+		kind, name, key, value := row[0], row[1], row[2], row[3]
+		switch kind {
+		case metaFormat:
+			if v, err := strconv.Atoi(value); err == nil && v > metadataVersion {
+				return nil, fmt.Errorf("workbook metadata version %d is newer than this app supports", v)
+			}
+		case metaDeck:
+			sheet(name).DeckName = key
+			sheet(name).DeckID = value
+		case metaField:
+			f := deck.FieldDefinition{Name: key, Type: value}
+			if len(row) > 4 && row[4] != "" {
+				f.Options = strings.Split(row[4], "\n")
+			}
+			if len(row) > 5 {
+				f.Required = strings.EqualFold(row[5], "true")
+			}
+			sheet(name).Fields = append(sheet(name).Fields, f)
+		case metaFrontStyle:
+			sheet(name).FrontStyles[key] = value
+		case metaBackStyle:
+			sheet(name).BackStyles[key] = value
+		}
+	}
+	return sheets, nil
+}
@@ -0,0 +1,132 @@
+package tabular
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"card_wizard/internal/deck"
+)
+
+func metadataDeck() deck.Deck {
+	return deck.Deck{
+		ID:   "deck-1",
+		Name: "Weapons",
+		Fields: []deck.FieldDefinition{
+			{Name: "Name", Type: deck.FieldText, Required: true},
+			{Name: "Cost", Type: deck.FieldInteger},
+			{Name: "Rarity", Type: deck.FieldEnum, Options: []string{"Common", "Rare"}},
+		},
+		FrontStyles: map[string]deck.CardLayout{"front-1": {Name: "Gold"}, "front-2": {Name: "Plain"}},
+		BackStyles:  map[string]deck.CardLayout{"back-1": {Name: "Red Back"}},
+		Cards: []deck.Card{
+			{ID: "Dagger_1", Count: 2, FrontStyleID: "front-1", BackStyleID: "back-1",
+				Data: map[string]interface{}{"Name": "Dagger", "Cost": 1, "Rarity": "Common"}},
+			{ID: "sword", Count: 1, FrontStyleID: "front-2", BackStyleID: "back-1",
+				Data: map[string]interface{}{"Name": "Sword", "Cost": 3, "Rarity": "Rare"}},
+		},
+	}
+}
+
+func TestWorkbookRoundTrip(t *testing.T) {
+	d := metadataDeck()
+
+	for _, tt := range []struct {
+		ext   string
+		write func(io.Writer, []Sheet) error
+	}{
+		{".xlsx", WriteXLSX},
+		{".ods", WriteODS},
+	} {
+		var buf bytes.Buffer
+		if err := tt.write(&buf, Workbook([]deck.Deck{d}, 31)); err != nil {
+			t.Fatalf("write %s error = %v", tt.ext, err)
+		}
+		path := filepath.Join(t.TempDir(), "export"+tt.ext)
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		src, err := Open(path)
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", tt.ext, err)
+		}
+		defer src.Close()
+
+		if got := src.Sheets(); !reflect.DeepEqual(got, []string{"Weapons", MetadataSheet}) {
+			t.Errorf("%s Sheets() = %v", tt.ext, got)
+		}
+		rows, err := src.Rows("Weapons")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rows[1][:4]; !reflect.DeepEqual(got, []string{"Dagger_1", "2", "Gold", "Red Back"}) {
+			t.Errorf("%s first row = %v, want style names", tt.ext, got)
+		}
+
+		meta, err := ReadMetadata(src)
+		if err != nil {
+			t.Fatalf("ReadMetadata(%s) error = %v", tt.ext, err)
+		}
+		sheet := meta["Weapons"]
+		if sheet == nil || sheet.DeckID != "deck-1" || !reflect.DeepEqual(sheet.Fields, d.Fields) {
+			t.Fatalf("%s metadata = %+v", tt.ext, sheet)
+		}
+		if sheet.FrontStyles["Gold"] != "front-1" || sheet.BackStyles["Red Back"] != "back-1" {
+			t.Errorf("%s metadata styles = %v, %v", tt.ext, sheet.FrontStyles, sheet.BackStyles)
+		}
+
+		// Importing with the metadata's mapping restores IDs, styles and types
+		result, err := ImportCards(rows, sheet.Mapping(), d, ImportOptions{Strict: true, Metadata: sheet})
+		if err != nil {
+			t.Fatalf("ImportCards(%s) error = %v", tt.ext, err)
+		}
+		if len(result.Issues) != 0 {
+			t.Errorf("%s import issues = %+v", tt.ext, result.Issues)
+		}
+		if !reflect.DeepEqual(result.Cards, d.Cards) {
+			t.Errorf("%s imported cards =\n%+v\nwant\n%+v", tt.ext, result.Cards, d.Cards)
+		}
+
+		// An empty deck learns the fields from the metadata
+		fresh, err := ImportCards(rows, sheet.Mapping(), deck.Deck{}, ImportOptions{Metadata: sheet})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(fresh.Fields, d.Fields) || fresh.Cards[0].Data["Cost"] != 1 || fresh.Cards[0].FrontStyleID != "Gold" {
+			t.Errorf("%s fresh import = %+v", tt.ext, fresh)
+		}
+	}
+}
+
+func TestWriteXLSXHidesMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, Workbook([]deck.Deck{metadataDeck()}, 31)); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if visible, _ := f.GetSheetVisible(MetadataSheet); visible {
+		t.Error("metadata sheet is visible")
+	}
+	if name := f.GetSheetName(f.GetActiveSheetIndex()); name != "Weapons" {
+		t.Errorf("active sheet = %q, want Weapons", name)
+	}
+}
+
+func TestReadMetadataWithoutSheet(t *testing.T) {
+	src := &delimitedSource{name: "cards", rows: [][]string{{"Name"}}}
+	meta, err := ReadMetadata(src)
+	if err != nil || meta != nil {
+		t.Errorf("ReadMetadata() = %v, %v, want nil", meta, err)
+	}
+}
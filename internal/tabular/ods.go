@@ -0,0 +1,199 @@
+package tabular
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// OpenDocument XML namespaces
+const (
+	nsOffice = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	nsStyle  = "urn:oasis:names:tc:opendocument:xmlns:style:1.0"
+	nsTable  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	nsText   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+// maxRepeat caps how often a repeated non-empty row or cell is expanded. Empty
+// repeats, which spreadsheets use to pad to the sheet size, are never expanded
+// unless content follows them.
+const maxRepeat = 16384
+
+// odsSource reads OpenDocument spreadsheets (.ods)
+type odsSource struct {
+	names  []string
+	sheets map[string][][]string
+}
+
+func openODS(path string) (Source, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ODS file: %w", err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.Name != "content.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return parseODSContent(rc)
+	}
+	return nil, fmt.Errorf("ODS file has no content.xml")
+}
+
+func (s *odsSource) Sheets() []string {
+	return s.names
+}
+
+func (s *odsSource) Rows(sheet string) ([][]string, error) {
+	rows, ok := s.sheets[sheet]
+	if !ok {
+		return nil, fmt.Errorf("sheet %s does not exist", sheet)
+	}
+	return rows, nil
+}
+
+func (s *odsSource) Close() error {
+	return nil
+}
+
+// repeatAttr reads a table:number-*-repeated attribute, defaulting to 1
+func repeatAttr(el xml.StartElement, name string) int {
+	for _, attr := range el.Attr {
+		if attr.Name.Space == nsTable && attr.Name.Local == name {
+			if n, err := strconv.Atoi(attr.Value); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 1
+}
+
+// parseODSContent reads every table in an ODS content.xml as text rows
+func parseODSContent(r io.Reader) (*odsSource, error) {
+	src := &odsSource{sheets: make(map[string][][]string)}
+
+	var (
+		sheet        string
+		rows         [][]string
+		emptyRows    int // Empty rows seen since the last row with content
+		cells        []string
+		emptyCells   int // Empty cells seen since the last cell with content
+		rowRepeat    int
+		cellRepeat   int
+		cell         strings.Builder
+		paragraphs   int
+		inParagraph  int // Depth of text:p / text:h elements
+		inAnnotation int // Comments hold paragraphs that are not cell text
+	)
+
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ODS content: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == nsOffice && t.Name.Local == "annotation":
+				inAnnotation++
+			case inAnnotation > 0:
+			case t.Name.Space == nsTable && t.Name.Local == "table":
+				sheet = ""
+				for _, attr := range t.Attr {
+					if attr.Name.Space == nsTable && attr.Name.Local == "name" {
+						sheet = attr.Value
+					}
+				}
+				rows, emptyRows = nil, 0
+			case t.Name.Space == nsTable && t.Name.Local == "table-row":
+				rowRepeat = repeatAttr(t, "number-rows-repeated")
+				cells, emptyCells = nil, 0
+			case t.Name.Space == nsTable && (t.Name.Local == "table-cell" || t.Name.Local == "covered-table-cell"):
+				cellRepeat = repeatAttr(t, "number-columns-repeated")
+				cell.Reset()
+				paragraphs = 0
+			case t.Name.Space == nsText && (t.Name.Local == "p" || t.Name.Local == "h"):
+				if inParagraph == 0 && paragraphs > 0 {
+					cell.WriteByte('\n')
+				}
+				inParagraph++
+				paragraphs++
+			case t.Name.Space == nsText && t.Name.Local == "s":
+				count := 1
+				for _, attr := range t.Attr {
+					if attr.Name.Space == nsText && attr.Name.Local == "c" {
+						if n, err := strconv.Atoi(attr.Value); err == nil && n > 0 {
+							count = n
+						}
+					}
+				}
+				cell.WriteString(strings.Repeat(" ", count))
+			case t.Name.Space == nsText && t.Name.Local == "tab":
+				cell.WriteByte('\t')
+			case t.Name.Space == nsText && t.Name.Local == "line-break":
+				cell.WriteByte('\n')
+			}
+
+		case xml.CharData:
+			if inParagraph > 0 && inAnnotation == 0 {
+				cell.Write(t)
+			}
+
+		case xml.EndElement:
+			switch {
+			case t.Name.Space == nsOffice && t.Name.Local == "annotation":
+				inAnnotation--
+			case inAnnotation > 0:
+			case t.Name.Space == nsText && (t.Name.Local == "p" || t.Name.Local == "h"):
+				inParagraph--
+			case t.Name.Space == nsTable && (t.Name.Local == "table-cell" || t.Name.Local == "covered-table-cell"):
+				value := cell.String()
+				if value == "" {
+					emptyCells += cellRepeat
+					continue
+				}
+				for ; emptyCells > 0; emptyCells-- {
+					cells = append(cells, "")
+				}
+				for i := 0; i < min(cellRepeat, maxRepeat); i++ {
+					cells = append(cells, value)
+				}
+			case t.Name.Space == nsTable && t.Name.Local == "table-row":
+				if len(cells) == 0 {
+					emptyRows += rowRepeat
+					continue
+				}
+				for ; emptyRows > 0; emptyRows-- {
+					rows = append(rows, []string{})
+				}
+				for i := 0; i < min(rowRepeat, maxRepeat); i++ {
+					rows = append(rows, append([]string(nil), cells...))
+				}
+			case t.Name.Space == nsTable && t.Name.Local == "table":
+				if _, exists := src.sheets[sheet]; !exists {
+					src.names = append(src.names, sheet)
+				}
+				src.sheets[sheet] = rows
+			}
+		}
+	}
+
+	if len(src.names) == 0 {
+		return nil, fmt.Errorf("ODS file has no sheets")
+	}
+	return src, nil
+}
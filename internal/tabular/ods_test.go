@@ -0,0 +1,116 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestODSRoundTrip(t *testing.T) {
+	sheets := []Sheet{
+		{Name: "Weapons & Armor", Rows: [][]interface{}{
+			{"ID", "Count", "Name", "Text", "Rare"},
+			{"sword", 3, "Sword", "Deal 3.\nDraw  a card.", true},
+			{"shield", 1.5, nil, " <b>leading</b>\tspace", false},
+		}},
+		{Name: "Spells", Rows: [][]interface{}{{"ID"}, {"fireball"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteODS(&buf, sheets); err != nil {
+		t.Fatalf("WriteODS() error = %v", err)
+	}
+
+	// The mimetype entry must be first and uncompressed for other apps to detect the file
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first := zr.File[0]; first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("first entry = %s (method %d), want stored mimetype", first.Name, first.Method)
+	}
+
+	path := filepath.Join(t.TempDir(), "deck.ods")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer src.Close()
+
+	if got := src.Sheets(); !reflect.DeepEqual(got, []string{"Weapons & Armor", "Spells"}) {
+		t.Errorf("Sheets() = %v", got)
+	}
+	rows, err := src.Rows("Weapons & Armor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"ID", "Count", "Name", "Text", "Rare"},
+		{"sword", "3", "Sword", "Deal 3.\nDraw  a card.", "TRUE"},
+		{"shield", "1.5", "", " <b>leading</b>\tspace", "FALSE"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Rows() = %q, want %q", rows, want)
+	}
+}
+
+func TestParseODSContent(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:spreadsheet>
+<table:table table:name="Cards">
+ <table:table-row>
+  <table:table-cell office:value-type="string"><text:p>name</text:p></table:table-cell>
+  <table:table-cell table:number-columns-repeated="2" office:value-type="string"><text:p>x</text:p></table:table-cell>
+  <table:table-cell table:number-columns-repeated="1020"/>
+ </table:table-row>
+ <table:table-row table:number-rows-repeated="2"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+ <table:table-row>
+  <table:table-cell table:number-columns-spanned="2" office:value-type="string">
+   <office:annotation><text:p>a comment</text:p></office:annotation>
+   <text:p>Big <text:span>sword</text:span></text:p><text:p>two<text:s text:c="3"/>spaces<text:line-break/>end</text:p>
+  </table:table-cell>
+  <table:covered-table-cell/>
+  <table:table-cell office:value-type="float" office:value="2"><text:p>2</text:p></table:table-cell>
+ </table:table-row>
+ <table:table-row table:number-rows-repeated="1048570"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table>
+</office:spreadsheet></office:body></office:document-content>`
+
+	src, err := parseODSContent(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("parseODSContent() error = %v", err)
+	}
+	rows, _ := src.Rows("Cards")
+	want := [][]string{
+		{"name", "x", "x"},
+		{},
+		{},
+		{"Big sword\ntwo   spaces\nend", "", "2"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Rows() = %q, want %q", rows, want)
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	used := make(map[string]bool)
+	got := []string{
+		SheetName("Weapons", 0, 31, used),
+		SheetName("weapons", 1, 31, used),
+		SheetName("", 2, 31, used),
+		SheetName(strings.Repeat("é", 40), 3, 31, used),
+		SheetName(strings.Repeat("é", 40), 4, 31, used),
+	}
+	want := []string{"Weapons", "weapons (2)", "Deck 3", strings.Repeat("é", 31), strings.Repeat("é", 27) + " (2)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SheetName() = %q, want %q", got, want)
+	}
+}
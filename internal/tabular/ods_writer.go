@@ -0,0 +1,180 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
+// Sheet is a named table of cell values for WriteODS. Values may be strings,
+// numbers, booleans or nil for an empty cell.
+type Sheet struct {
+	Name     string
+	Rows     [][]interface{}
+	Hidden   bool
+	Pictures []Picture // Images placed over cells; only WriteXLSX writes them
+}
+
+const odsManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+ <manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="` + odsMimeType + `"/>
+ <manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+ <manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>
+`
+
+const odsStyles = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-styles xmlns:office="` + nsOffice + `" office:version="1.2"/>
+`
+
+// WriteODS writes the sheets as an OpenDocument spreadsheet
+func WriteODS(w io.Writer, sheets []Sheet) error {
+	zw := zip.NewWriter(w)
+
+	// The mimetype entry must come first and be stored uncompressed
+	mt, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mt, odsMimeType); err != nil {
+		return err
+	}
+
+	for _, entry := range []struct{ name, body string }{
+		{"META-INF/manifest.xml", odsManifest},
+		{"styles.xml", odsStyles},
+	} {
+		f, err := zw.Create(entry.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, entry.body); err != nil {
+			return err
+		}
+	}
+
+	content, err := zw.Create("content.xml")
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(content)
+	if err := writeODSContent(bw, sheets); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeODSContent(w *bufio.Writer, sheets []Sheet) error {
+	w.WriteString(xml.Header)
+	w.WriteString(`<office:document-content xmlns:office="` + nsOffice + `" xmlns:style="` + nsStyle + `" xmlns:table="` + nsTable + `" xmlns:text="` + nsText + `" office:version="1.2">`)
+	w.WriteString(`<office:automatic-styles><style:style style:name="ta_hidden" style:family="table"><style:table-properties table:display="false"/></style:style></office:automatic-styles>`)
+	w.WriteString(`<office:body><office:spreadsheet>`)
+
+	for _, sheet := range sheets {
+		w.WriteString(`<table:table table:name="`)
+		if err := xml.EscapeText(w, []byte(sheet.Name)); err != nil {
+			return err
+		}
+		if sheet.Hidden {
+			w.WriteString(`" table:style-name="ta_hidden`)
+		}
+		w.WriteString(`">`)
+
+		for _, row := range sheet.Rows {
+			w.WriteString(`<table:table-row>`)
+			for _, value := range row {
+				if err := writeODSCell(w, value); err != nil {
+					return err
+				}
+			}
+			w.WriteString(`</table:table-row>`)
+		}
+
+		w.WriteString(`</table:table>`)
+	}
+
+	w.WriteString(`</office:spreadsheet></office:body></office:document-content>`)
+	return nil
+}
+
+// writeODSCell writes one cell, typed so spreadsheets see numbers and booleans
+func writeODSCell(w *bufio.Writer, value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		w.WriteString(`<table:table-cell/>`)
+		return nil
+	case bool:
+		text = strings.ToUpper(strconv.FormatBool(v))
+		fmt.Fprintf(w, `<table:table-cell office:value-type="boolean" office:boolean-value="%t">`, v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		text = fmt.Sprint(v)
+		fmt.Fprintf(w, `<table:table-cell office:value-type="float" office:value="%s">`, text)
+	case float32:
+		text = strconv.FormatFloat(float64(v), 'f', -1, 32)
+		fmt.Fprintf(w, `<table:table-cell office:value-type="float" office:value="%s">`, text)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+		fmt.Fprintf(w, `<table:table-cell office:value-type="float" office:value="%s">`, text)
+	case string:
+		if v == "" {
+			w.WriteString(`<table:table-cell/>`)
+			return nil
+		}
+		text = v
+		w.WriteString(`<table:table-cell office:value-type="string">`)
+	default:
+		text = fmt.Sprint(v)
+		w.WriteString(`<table:table-cell office:value-type="string">`)
+	}
+
+	// Each line is its own paragraph
+	for _, line := range strings.Split(text, "\n") {
+		w.WriteString(`<text:p>`)
+		if err := writeODSText(w, line); err != nil {
+			return err
+		}
+		w.WriteString(`</text:p>`)
+	}
+	w.WriteString(`</table:table-cell>`)
+	return nil
+}
+
+// writeODSText escapes a line, keeping tabs and runs of spaces that ODF would
+// otherwise collapse
+func writeODSText(w *bufio.Writer, line string) error {
+	for len(line) > 0 {
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			return xml.EscapeText(w, []byte(line))
+		}
+		if err := xml.EscapeText(w, []byte(line[:i])); err != nil {
+			return err
+		}
+		if line[i] == '\t' {
+			w.WriteString(`<text:tab/>`)
+			line = line[i+1:]
+			continue
+		}
+
+		spaces := len(line[i:]) - len(strings.TrimLeft(line[i:], " "))
+		// A single space between words is kept as is; leading, trailing and repeated spaces are not
+		if spaces == 1 && i > 0 && i+1 < len(line) {
+			w.WriteByte(' ')
+		} else {
+			fmt.Fprintf(w, `<text:s text:c="%d"/>`, spaces)
+		}
+		line = line[i+spaces:]
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package tabular
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"card_wizard/internal/deck"
+)
+
+// Picture is an image embedded in a sheet cell
+type Picture struct {
+	Row  int    // 0-based, like the indexes of Rows
+	Col  int    // 0-based
+	Name string // Alt text or original file name, if the workbook kept one
+	Ext  string // File extension, including the dot
+	Data []byte
+}
+
+// PictureSource is a Source whose sheets can hold embedded images
+type PictureSource interface {
+	Pictures(sheet string) ([]Picture, error)
+}
+
+// isImageField reports whether a field holds image paths
+func isImageField(f deck.FieldDefinition) bool {
+	return f.Type == deck.FieldImage || f.Type == deck.FieldIcon
+}
+
+// placePictures writes the path of each picture saved with save into the cell it
+// sits in, returning a copy of rows. Cells that already hold text keep it.
+// Pictures in columns of fields that are not images are reported and left out;
+// columns that are not fields yet are returned as new image fields.
+func placePictures(rows [][]string, pictures []Picture, fields []deck.FieldDefinition, systemCols map[string]bool,
+	save func(fileName string, data []byte) (string, error), report func(row int, column, severity, format string, args ...any)) ([][]string, []deck.FieldDefinition) {
+
+	byName := make(map[string]deck.FieldDefinition)
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+
+	out := make([][]string, len(rows))
+	for i, row := range rows {
+		out[i] = append([]string(nil), row...)
+	}
+
+	var added []deck.FieldDefinition
+	placed := make(map[[2]int]bool)
+	headers := rows[0]
+	for _, p := range pictures {
+		if p.Row == 0 {
+			continue // Logos and the like above the header
+		}
+		rowNum := p.Row + 1
+		header := cellAt(headers, p.Col)
+		switch {
+		case header == "":
+			report(rowNum, "", SeverityWarning, "embedded image in a column without a header was ignored")
+			continue
+		case systemCols[header]:
+			report(rowNum, header, SeverityWarning, "embedded image in the %s column was ignored", header)
+			continue
+		}
+		if f, ok := byName[header]; ok && !isImageField(f) {
+			report(rowNum, header, SeverityWarning, "field %s is not an image field; its embedded image was ignored", header)
+			continue
+		}
+		if placed[[2]int{p.Row, p.Col}] {
+			report(rowNum, header, SeverityWarning, "cell has more than one embedded image; using the first")
+			continue
+		}
+		placed[[2]int{p.Row, p.Col}] = true
+
+		// A path in the cell wins, so thumbnails from an export are not imported
+		if p.Row < len(out) && strings.TrimSpace(cellAt(out[p.Row], p.Col)) != "" {
+			continue
+		}
+		if save == nil {
+			report(rowNum, header, SeverityWarning, "embedded image was not imported; save the game first so it has an images folder")
+			continue
+		}
+		path, err := save(pictureFileName(p, header), p.Data)
+		if err != nil {
+			report(rowNum, header, SeverityWarning, "failed to save embedded image: %v", err)
+			continue
+		}
+
+		if _, ok := byName[header]; !ok {
+			f := deck.FieldDefinition{Name: header, Type: deck.FieldImage}
+			byName[header] = f
+			added = append(added, f)
+		}
+		for len(out) <= p.Row {
+			out = append(out, nil)
+		}
+		for len(out[p.Row]) <= p.Col {
+			out[p.Row] = append(out[p.Row], "")
+		}
+		out[p.Row][p.Col] = path
+	}
+	return out, added
+}
+
+// pictureFileName names an embedded image after its alt text, or its column and
+// row when it has none
+func pictureFileName(p Picture, header string) string {
+	ext := strings.ToLower(p.Ext)
+	if ext == "" {
+		ext = ".png"
+	}
+	name := strings.TrimSuffix(filepath.Base(p.Name), filepath.Ext(p.Name))
+	if slug := Slugify(name); slug != "" {
+		return slug + ext
+	}
+	return fmt.Sprintf("%s-%d%s", Slugify(header), p.Row+1, ext)
+}
+
+// Thumbnails returns a picture for each image a deck's cards show in image
+// fields, laid out like CardRows and scaled to fit in size pixels. Relative
+// paths resolve against imageDir. Images that cannot be read are skipped.
+func Thumbnails(d deck.Deck, imageDir string, size int) []Picture {
+	var pictures []Picture
+	cache := make(map[string][]byte)
+	for i, card := range d.Cards {
+		for j, f := range d.Fields {
+			if !isImageField(f) {
+				continue
+			}
+			path, _ := card.Data[f.Name].(string)
+			path = strings.TrimSpace(path)
+			if path == "" || strings.Contains(path, "://") || strings.HasPrefix(path, "data:") {
+				continue
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(imageDir, filepath.FromSlash(path))
+			}
+
+			data, ok := cache[path]
+			if !ok {
+				data, _ = thumbnail(path, size)
+				cache[path] = data
+			}
+			if data == nil {
+				continue
+			}
+			pictures = append(pictures, Picture{
+				Row:  i + 1,
+				Col:  len(StandardHeaders) + j,
+				Name: filepath.Base(path),
+				Ext:  ".png",
+				Data: data,
+			})
+		}
+	}
+	return pictures
+}
+
+// thumbnail reads an image and returns it as a PNG no larger than size pixels
+func thumbnail(path string, size int) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/w)
+		} else {
+			w, h = max(1, w*size/h), size
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, b, xdraw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
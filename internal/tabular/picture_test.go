@@ -0,0 +1,185 @@
+package tabular
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"card_wizard/internal/deck"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImportEmbeddedPictures(t *testing.T) {
+	art := testPNG(t, 4, 4)
+	path := filepath.Join(t.TempDir(), "cards.xlsx")
+
+	f := excelize.NewFile()
+	rows := [][]interface{}{
+		{"Name", "Art", "Cost", "Portrait"},
+		{"Dagger", nil, 1},
+		{"Sword", "images/sword.png", 2},
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, pic := range []struct {
+		cell, alt string
+	}{
+		{"B2", "Dagger Art.png"},
+		{"B3", "Sword"}, // The path in the cell wins
+		{"C2", ""},      // Cost is not an image field
+		{"D3", ""},      // Portrait becomes an image field
+	} {
+		err := f.AddPictureFromBytes("Sheet1", pic.cell, &excelize.Picture{
+			Extension: ".png", File: art, Format: &excelize.GraphicOptions{AltText: pic.alt},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	src, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	sheetRows, err := src.Rows("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pictures, err := src.(PictureSource).Pictures("Sheet1")
+	if err != nil {
+		t.Fatalf("Pictures() error = %v", err)
+	}
+	if len(pictures) != 4 || !bytes.Equal(pictures[0].Data, art) {
+		t.Fatalf("Pictures() returned %d pictures, want 4 with the embedded data", len(pictures))
+	}
+
+	var saved []string
+	d := deck.Deck{Fields: []deck.FieldDefinition{
+		{Name: "Art", Type: deck.FieldImage},
+		{Name: "Cost", Type: deck.FieldInteger},
+	}}
+	result, err := ImportCards(sheetRows, map[string]string{MapGenerateIDFrom: "Name"}, d, ImportOptions{
+		Pictures: pictures,
+		SaveImage: func(fileName string, data []byte) (string, error) {
+			saved = append(saved, fileName)
+			return "images/" + fileName, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ImportCards() error = %v", err)
+	}
+
+	if want := []string{"dagger-art.png", "portrait-3.png"}; !reflect.DeepEqual(saved, want) {
+		t.Errorf("saved images = %v, want %v", saved, want)
+	}
+	if got := result.Cards[0].Data["Art"]; got != "images/dagger-art.png" {
+		t.Errorf("card 0 Art = %v, want images/dagger-art.png", got)
+	}
+	if got := result.Cards[1].Data["Art"]; got != "images/sword.png" {
+		t.Errorf("card 1 Art = %v, want images/sword.png", got)
+	}
+	if got := result.Cards[1].Data["Portrait"]; got != "images/portrait-3.png" {
+		t.Errorf("card 1 Portrait = %v, want images/portrait-3.png", got)
+	}
+	if want := []deck.FieldDefinition{{Name: "Portrait", Type: deck.FieldImage}}; !reflect.DeepEqual(result.Fields, want) {
+		t.Errorf("ImportCards() fields = %+v, want %+v", result.Fields, want)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Column != "Cost" {
+		t.Errorf("ImportCards() issues = %+v, want one for Cost", result.Issues)
+	}
+
+	// Without somewhere to save them, pictures are reported and skipped
+	unsaved, err := ImportCards(sheetRows, map[string]string{MapGenerateIDFrom: "Name"}, d, ImportOptions{Pictures: pictures})
+	if err != nil {
+		t.Fatalf("ImportCards() error = %v", err)
+	}
+	if got := unsaved.Cards[0].Data["Art"]; got != "" {
+		t.Errorf("unsaved card 0 Art = %#v, want empty", got)
+	}
+	if len(unsaved.Issues) != 3 {
+		t.Errorf("unsaved issues = %+v, want 3", unsaved.Issues)
+	}
+}
+
+func TestWriteXLSXThumbnails(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "big.png"), testPNG(t, 200, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := deck.Deck{
+		Name:   "Weapons",
+		Fields: []deck.FieldDefinition{{Name: "Name"}, {Name: "Art", Type: deck.FieldImage}},
+		Cards: []deck.Card{
+			{ID: "a", Data: map[string]interface{}{"Name": "A", "Art": "big.png"}},
+			{ID: "b", Data: map[string]interface{}{"Name": "B", "Art": "missing.png"}},
+		},
+	}
+	thumbs := Thumbnails(d, dir, 50)
+	if len(thumbs) != 1 || thumbs[0].Row != 1 || thumbs[0].Col != len(StandardHeaders)+1 {
+		t.Fatalf("Thumbnails() = %+v, want one picture in row 1, column %d", thumbs, len(StandardHeaders)+1)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(thumbs[0].Data))
+	if err != nil || cfg.Width != 50 || cfg.Height != 25 {
+		t.Errorf("thumbnail size = %dx%d (%v), want 50x25", cfg.Width, cfg.Height, err)
+	}
+
+	sheets := Workbook([]deck.Deck{d}, 31)
+	sheets[0].Pictures = thumbs
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, sheets); err != nil {
+		t.Fatalf("WriteXLSX() error = %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	src := &excelSource{f: f}
+	pictures, err := src.Pictures("Weapons")
+	if err != nil || len(pictures) != 1 || pictures[0].Row != 1 || pictures[0].Col != 5 {
+		t.Fatalf("Pictures() = %+v, %v, want the thumbnail at row 1, column 5", pictures, err)
+	}
+
+	// Re-importing keeps the paths rather than the thumbnails
+	rows, _ := src.Rows("Weapons")
+	result, err := ImportCards(rows, map[string]string{MapID: "ID"}, d, ImportOptions{
+		Pictures: pictures,
+		SaveImage: func(string, []byte) (string, error) {
+			t.Error("SaveImage called for a cell with a path")
+			return "", nil
+		},
+	})
+	if err != nil || result.Cards[0].Data["Art"] != "big.png" {
+		t.Errorf("ImportCards() Art = %v, %v, want big.png", result.Cards[0].Data["Art"], err)
+	}
+}
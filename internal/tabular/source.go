@@ -0,0 +1,60 @@
+// Package tabular reads card lists from spreadsheets and delimited text files
+// behind a single interface, and maps their rows onto deck cards.
+package tabular
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Source is an open file holding one or more sheets of rows. The first row of a
+// sheet is its header.
+type Source interface {
+	// Sheets lists the sheet names in file order
+	Sheets() []string
+	// Rows returns every row of a sheet as text, header first
+	Rows(sheet string) ([][]string, error)
+	Close() error
+}
+
+// Extensions lists the file types Open understands, for file dialog filters
+var Extensions = []string{".xlsx", ".xlsm", ".ods", ".csv", ".tsv", ".tab", ".txt"}
+
+// Open opens a tabular file, picking the reader from its extension
+func Open(path string) (Source, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx", ".xlsm":
+		return openExcel(path)
+	case ".ods":
+		return openODS(path)
+	case ".csv", ".txt":
+		return openDelimited(path, 0)
+	case ".tsv", ".tab":
+		return openDelimited(path, '\t')
+	case ".xls":
+		return nil, fmt.Errorf("legacy .xls files are not supported; save the file as .xlsx or .csv")
+	}
+	return nil, fmt.Errorf("unsupported file type %q", filepath.Ext(path))
+}
+
+// DialogPattern returns the extensions as a file dialog pattern, e.g. "*.xlsx;*.csv"
+func DialogPattern() string {
+	patterns := make([]string, len(Extensions))
+	for i, ext := range Extensions {
+		patterns[i] = "*" + ext
+	}
+	return strings.Join(patterns, ";")
+}
+
+// Headers returns the header row of a sheet
+func Headers(src Source, sheet string) ([]string, error) {
+	rows, err := src.Rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) < 1 {
+		return nil, fmt.Errorf("sheet is empty")
+	}
+	return rows[0], nil
+}
@@ -0,0 +1,376 @@
+package tabular
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"card_wizard/internal/deck"
+)
+
+// MapKey names the column that matches sheet rows to existing cards when syncing.
+// It is either a deck field, compared with the card data, or a column of card IDs.
+const MapKey = "key"
+
+// FieldChange is one value a sync overwrites
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// CardChange is an existing card whose sheet row differs from it
+type CardChange struct {
+	Card    deck.Card     `json:"card"` // The card as it will be after the sync
+	Row     int           `json:"row"`  // 1-based sheet row, counting the header
+	Changes []FieldChange `json:"changes"`
+}
+
+// SyncDiff describes what re-importing a sheet would do to a deck's cards
+type SyncDiff struct {
+	Added     []deck.Card  `json:"added"`
+	Changed   []CardChange `json:"changed"`
+	Removed   []deck.Card  `json:"removed"` // Cards with no row in the sheet
+	Unchanged int          `json:"unchanged"`
+	Skipped   []int        `json:"skipped"` // Sheet rows with an empty key
+	Issues    []Issue      `json:"issues"`  // Cells that fell back, like counts that are not whole numbers
+}
+
+// SyncCards matches sheet rows to a deck's cards and reports the difference.
+// Rows are matched by the MapKey column, or by the ID MapCards would give them
+// when no key is mapped. Matched cards keep their ID and any data, styles and
+// count the sheet has no column for. An empty style cell keeps the card's style.
+func SyncCards(d deck.Deck, rows [][]string, mapping map[string]string) (SyncDiff, error) {
+	var diff SyncDiff
+	if len(rows) < 1 {
+		return diff, fmt.Errorf("file is empty or missing header")
+	}
+
+	headers := rows[0]
+	headerMap := make(map[string]int)
+	for i, h := range headers {
+		if _, dup := headerMap[h]; !dup {
+			headerMap[h] = i
+		}
+	}
+
+	key := mapping[MapKey]
+	if key != "" {
+		if _, ok := headerMap[key]; !ok {
+			return diff, fmt.Errorf("key column %q is not in the sheet", key)
+		}
+	}
+
+	// A key column that is not a deck field holds card IDs
+	fields := make(map[string]deck.FieldDefinition)
+	for _, f := range d.Fields {
+		fields[f.Name] = f
+	}
+	_, byData := fields[key]
+	byData = byData && key != ""
+
+	systemCols := map[string]bool{
+		mapping[MapID]:         true,
+		mapping[MapCount]:      true,
+		mapping[MapFrontStyle]: true,
+		mapping[MapBackStyle]:  true,
+	}
+	if key != "" && !byData {
+		systemCols[key] = true
+	}
+	delete(systemCols, "")
+
+	// Index existing cards by key; on duplicates the first card wins
+	index := make(map[string]int)
+	usedIDs := make(map[string]bool)
+	for i, c := range d.Cards {
+		usedIDs[c.ID] = true
+		k := c.ID
+		if byData {
+			k = cellString(c.Data[key])
+		}
+		if _, dup := index[k]; !dup && k != "" {
+			index[k] = i
+		}
+	}
+
+	matched := make(map[int]int) // Card index -> sheet row
+	for i, row := range rows {
+		if i == 0 {
+			continue
+		}
+		getCell := func(colName string) string {
+			idx, ok := headerMap[colName]
+			if colName == "" || !ok || idx >= len(row) {
+				return ""
+			}
+			return row[idx]
+		}
+
+		id := strings.TrimSpace(getCell(mapping[MapID]))
+		if raw := getCell(mapping[MapGenerateIDFrom]); raw != "" && id == "" {
+			id = Slugify(raw)
+		}
+
+		k := strings.TrimSpace(getCell(key))
+		switch {
+		case key == "":
+			if id == "" {
+				id = fmt.Sprintf("card-%d", i)
+			}
+			k = id
+		case k == "":
+			diff.Skipped = append(diff.Skipped, i+1)
+			continue
+		case !byData && id == "":
+			id = k
+		case id == "":
+			id = Slugify(k)
+		}
+
+		count, err := parseCount(getCell(mapping[MapCount]))
+		if err != nil {
+			diff.Issues = append(diff.Issues, Issue{Row: i + 1, Column: mapping[MapCount], Severity: SeverityError, Message: err.Error()})
+		}
+
+		cardIdx, exists := index[k]
+		if !exists {
+			card := deck.Card{
+				ID:           uniqueID(id, i, usedIDs),
+				Count:        count,
+				FrontStyleID: resolveStyle(getCell(mapping[MapFrontStyle]), d.FrontStyles),
+				BackStyleID:  resolveStyle(getCell(mapping[MapBackStyle]), d.BackStyles),
+				Data:         make(map[string]interface{}),
+			}
+			for j, h := range headers {
+				if !systemCols[h] {
+					card.Data[h] = fieldValue(fields, h, cellAt(row, j))
+				}
+			}
+			diff.Added = append(diff.Added, card)
+			continue
+		}
+		if prev, dup := matched[cardIdx]; dup {
+			return diff, fmt.Errorf("rows %d and %d have the same key %q", prev, i+1, k)
+		}
+		matched[cardIdx] = i + 1
+
+		// Start from the existing card so fields the sheet does not own survive
+		old := d.Cards[cardIdx]
+		card := old
+		card.Data = make(map[string]interface{}, len(old.Data))
+		for k, v := range old.Data {
+			card.Data[k] = v
+		}
+
+		var changes []FieldChange
+		set := func(field, oldVal, newVal string) {
+			if oldVal != newVal {
+				changes = append(changes, FieldChange{Field: field, Old: oldVal, New: newVal})
+			}
+		}
+
+		if mapping[MapCount] != "" {
+			set(StandardHeaders[1], fmt.Sprint(old.Count), fmt.Sprint(count))
+			card.Count = count
+		}
+		if style := getCell(mapping[MapFrontStyle]); style != "" {
+			card.FrontStyleID = resolveStyle(style, d.FrontStyles)
+			set(StandardHeaders[2], old.FrontStyleID, card.FrontStyleID)
+		}
+		if style := getCell(mapping[MapBackStyle]); style != "" {
+			card.BackStyleID = resolveStyle(style, d.BackStyles)
+			set(StandardHeaders[3], old.BackStyleID, card.BackStyleID)
+		}
+		for j, h := range headers {
+			if systemCols[h] {
+				continue
+			}
+			val := fieldValue(fields, h, cellAt(row, j))
+			if cellString(old.Data[h]) != cellString(val) {
+				set(h, cellString(old.Data[h]), cellString(val))
+				card.Data[h] = val
+			}
+		}
+
+		if len(changes) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		diff.Changed = append(diff.Changed, CardChange{Card: card, Row: i + 1, Changes: changes})
+	}
+
+	for i, c := range d.Cards {
+		if _, ok := matched[i]; !ok {
+			diff.Removed = append(diff.Removed, c)
+		}
+	}
+
+	return diff, nil
+}
+
+// ApplySync applies a diff to the cards it was made from, keeping their order and
+// appending added cards. Cards missing from the sheet are only dropped when
+// removeMissing is set.
+func ApplySync(cards []deck.Card, diff SyncDiff, removeMissing bool) []deck.Card {
+	changed := make(map[string]deck.Card)
+	for _, c := range diff.Changed {
+		changed[c.Card.ID] = c.Card
+	}
+	removed := make(map[string]bool)
+	if removeMissing {
+		for _, c := range diff.Removed {
+			removed[c.ID] = true
+		}
+	}
+
+	result := make([]deck.Card, 0, len(cards)+len(diff.Added))
+	for _, c := range cards {
+		if removed[c.ID] {
+			continue
+		}
+		if updated, ok := changed[c.ID]; ok {
+			c = updated
+		}
+		result = append(result, c)
+	}
+	return append(result, diff.Added...)
+}
+
+// MergeImport gives a deck the cards of an import or sync, the way the app does:
+// style names the deck does not have become new, empty styles, cards without a
+// style get the deck's default, and data columns that are not fields yet become
+// fields, typed as declared or as text. New fields are added in the order of
+// headers, the sheet's header row.
+func MergeImport(d deck.Deck, cards []deck.Card, declared []deck.FieldDefinition, headers []string) deck.Deck {
+	frontStyles := make(map[string]deck.CardLayout, len(d.FrontStyles))
+	for id, s := range d.FrontStyles {
+		frontStyles[id] = s
+	}
+	backStyles := make(map[string]deck.CardLayout, len(d.BackStyles))
+	for id, s := range d.BackStyles {
+		backStyles[id] = s
+	}
+
+	style := func(nameOrID, fallback, side string, styles map[string]deck.CardLayout) string {
+		if nameOrID == "" {
+			return fallback
+		}
+		id := resolveStyle(nameOrID, styles)
+		if _, ok := styles[id]; ok {
+			return id
+		}
+		used := make(map[string]bool, len(styles))
+		for existing := range styles {
+			used[existing] = true
+		}
+		id = uniqueID(side+"-"+Slugify(nameOrID), 0, used)
+		styles[id] = deck.CardLayout{Name: nameOrID, Elements: []deck.LayoutElement{}}
+		return id
+	}
+
+	defaultFront, defaultBack := d.DefaultFrontStyleID, d.DefaultBackStyleID
+	if defaultFront == "" {
+		defaultFront = "default-front"
+	}
+	if defaultBack == "" {
+		defaultBack = "default-back"
+	}
+
+	known := make(map[string]bool)
+	for _, f := range d.Fields {
+		known[f.Name] = true
+	}
+	types := make(map[string]deck.FieldDefinition)
+	for _, f := range declared {
+		types[f.Name] = f
+	}
+
+	// Card data is a map, so new fields follow the header order instead. Columns
+	// missing from headers are added last, sorted.
+	inData := make(map[string]bool)
+	merged := make([]deck.Card, len(cards))
+	for i, c := range cards {
+		c.FrontStyleID = style(c.FrontStyleID, defaultFront, "front", frontStyles)
+		c.BackStyleID = style(c.BackStyleID, defaultBack, "back", backStyles)
+		merged[i] = c
+		for name := range c.Data {
+			inData[name] = true
+		}
+	}
+	var rest []string
+	for name := range inData {
+		rest = append(rest, name)
+	}
+	sort.Strings(rest)
+
+	fields := d.Fields
+	for _, name := range append(append([]string(nil), headers...), rest...) {
+		if known[name] || !inData[name] {
+			continue
+		}
+		f, ok := types[name]
+		if !ok {
+			f = deck.FieldDefinition{Name: name, Type: deck.FieldText}
+		}
+		fields = append(fields[:len(fields):len(fields)], f)
+		known[name] = true
+	}
+
+	d.Cards = merged
+	d.Fields = fields
+	d.FrontStyles = frontStyles
+	d.BackStyles = backStyles
+	return d
+}
+
+// resolveStyle returns the ID of the style a cell names, by ID or case-insensitive
+// name. Of styles sharing a name, the first by ID wins. Unknown names are
+// returned as is for the caller to create.
+func resolveStyle(nameOrID string, styles map[string]deck.CardLayout) string {
+	if _, ok := styles[nameOrID]; ok || nameOrID == "" {
+		return nameOrID
+	}
+	for _, id := range sortedStyles(styles) {
+		if strings.EqualFold(styles[id].Name, nameOrID) {
+			return id
+		}
+	}
+	return nameOrID
+}
+
+// uniqueID returns id, or id with a numeric suffix if it is already taken
+func uniqueID(id string, row int, used map[string]bool) string {
+	if id == "" {
+		id = fmt.Sprintf("card-%d", row)
+	}
+	candidate := id
+	for n := 2; used[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", id, n)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// fieldValue converts a cell to the type of the deck field it fills, keeping the
+// text when it has no field or does not convert
+func fieldValue(fields map[string]deck.FieldDefinition, header, cell string) interface{} {
+	if f, ok := fields[header]; ok {
+		if v, err := f.Coerce(cell); err == nil {
+			return v
+		}
+	}
+	return cell
+}
+
+func cellAt(row []string, idx int) string {
+	if idx < len(row) {
+		return row[idx]
+	}
+	return ""
+}
+
+// cellString formats a card value the way it reads back from a sheet
+func cellString(v interface{}) string {
+	return deck.FormatValue(v)
+}
@@ -0,0 +1,234 @@
+package tabular
+
+import (
+	"reflect"
+	"testing"
+
+	"card_wizard/internal/deck"
+)
+
+func syncDeck() deck.Deck {
+	return deck.Deck{
+		Fields: []deck.FieldDefinition{{Name: "Name"}, {Name: "Cost"}, {Name: "Flavor"}},
+		FrontStyles: map[string]deck.CardLayout{
+			"default-front": {Name: "Default"},
+			"front-gold":    {Name: "Gold"},
+		},
+		Cards: []deck.Card{
+			{ID: "dagger", Count: 2, FrontStyleID: "default-front", BackStyleID: "back-1",
+				Data: map[string]interface{}{"Name": "Dagger", "Cost": float64(1), "Flavor": "Sharp"}},
+			{ID: "shield", Count: 1, FrontStyleID: "front-gold",
+				Data: map[string]interface{}{"Name": "Shield", "Cost": "3"}},
+			{ID: "potion", Count: 4,
+				Data: map[string]interface{}{"Name": "Potion", "Cost": "0"}},
+		},
+	}
+}
+
+func TestSyncCardsByKey(t *testing.T) {
+	d := syncDeck()
+	rows := [][]string{
+		{"Name", "Cost", "Copies", "Style"},
+		{"Shield", "3", "1", ""},
+		{"Dagger", "2", "2", "gold"},
+		{"Bow", "4", "", ""},
+		{"", "9", "", ""},
+	}
+	mapping := map[string]string{MapKey: "Name", MapCount: "Copies", MapFrontStyle: "Style"}
+
+	diff, err := SyncCards(d, rows, mapping)
+	if err != nil {
+		t.Fatalf("SyncCards() error = %v", err)
+	}
+
+	if diff.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", diff.Unchanged)
+	}
+	if !reflect.DeepEqual(diff.Skipped, []int{5}) {
+		t.Errorf("Skipped = %v, want [5]", diff.Skipped)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != "potion" {
+		t.Errorf("Removed = %+v, want potion", diff.Removed)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].ID != "bow" || diff.Added[0].Data["Cost"] != "4" {
+		t.Errorf("Added = %+v, want bow", diff.Added)
+	}
+
+	if len(diff.Changed) != 1 {
+		t.Fatalf("Changed = %+v, want one card", diff.Changed)
+	}
+	change := diff.Changed[0]
+	wantChanges := []FieldChange{
+		{Field: "Front Style", Old: "default-front", New: "front-gold"},
+		{Field: "Cost", Old: "1", New: "2"},
+	}
+	if change.Row != 3 || !reflect.DeepEqual(change.Changes, wantChanges) {
+		t.Errorf("Changed[0] row %d changes = %+v, want row 3 %+v", change.Row, change.Changes, wantChanges)
+	}
+	// Fields and settings the sheet has no column for are kept
+	c := change.Card
+	if c.ID != "dagger" || c.BackStyleID != "back-1" || c.Data["Flavor"] != "Sharp" {
+		t.Errorf("synced card = %+v", c)
+	}
+	if d.Cards[0].Data["Cost"] != float64(1) {
+		t.Error("SyncCards() modified the existing card data")
+	}
+}
+
+func TestSyncCardsByID(t *testing.T) {
+	d := syncDeck()
+
+	// Without a key, rows match on the ID generated from the name
+	diff, err := SyncCards(d, [][]string{{"Name", "Cost"}, {"Potion", "1"}}, map[string]string{MapGenerateIDFrom: "Name"})
+	if err != nil {
+		t.Fatalf("SyncCards() error = %v", err)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Card.ID != "potion" || len(diff.Removed) != 2 {
+		t.Errorf("SyncCards() by generated ID = %+v", diff)
+	}
+
+	// A key column that is not a field holds card IDs and is not copied into the data
+	diff, err = SyncCards(d, [][]string{{"ID", "Cost"}, {"shield", "5"}, {"shield", "6"}}, map[string]string{MapKey: "ID"})
+	if err == nil {
+		t.Errorf("SyncCards() with a duplicate key succeeded: %+v", diff)
+	}
+	diff, err = SyncCards(d, [][]string{{"ID", "Cost"}, {"shield", "5"}, {"dagger", "1"}}, map[string]string{MapKey: "ID"})
+	if err != nil {
+		t.Fatalf("SyncCards() error = %v", err)
+	}
+	if len(diff.Changed) != 1 || diff.Unchanged != 1 {
+		t.Fatalf("SyncCards() by ID column = %+v", diff)
+	}
+	if _, ok := diff.Changed[0].Card.Data["ID"]; ok {
+		t.Error("ID key column was copied into card data")
+	}
+
+	if _, err := SyncCards(d, [][]string{{"Name"}}, map[string]string{MapKey: "Title"}); err == nil {
+		t.Error("SyncCards() with a missing key column succeeded")
+	}
+}
+
+func TestSyncCardsLargeNumbersUnchanged(t *testing.T) {
+	d := syncDeck()
+	d.Cards[0].Data["Cost"] = float64(1000000)
+
+	diff, err := SyncCards(d, [][]string{{"ID", "Cost"}, {"dagger", "1000000"}}, map[string]string{MapKey: "ID"})
+	if err != nil {
+		t.Fatalf("SyncCards() error = %v", err)
+	}
+	if len(diff.Changed) != 0 || diff.Unchanged != 1 {
+		t.Errorf("SyncCards() = %+v, want 1000000 to match the card's cost", diff)
+	}
+}
+
+func TestSyncCardsReportsBadCounts(t *testing.T) {
+	d := syncDeck()
+	rows := [][]string{
+		{"Name", "Copies"},
+		{"Dagger", "abc"},
+		{"Shield", "3x"},
+		{"Potion", "-1"},
+	}
+	diff, err := SyncCards(d, rows, map[string]string{MapKey: "Name", MapCount: "Copies"})
+	if err != nil {
+		t.Fatalf("SyncCards() error = %v", err)
+	}
+
+	// Counts fall back as an import's would, and are reported the same way
+	want := []Issue{
+		{Row: 2, Column: "Copies", Severity: SeverityError, Message: `count "abc" is not a whole number; using 1`},
+		{Row: 3, Column: "Copies", Severity: SeverityError, Message: `count "3x" is not a whole number; using 1`},
+		{Row: 4, Column: "Copies", Severity: SeverityError, Message: "count -1 is negative; using 0"},
+	}
+	if !reflect.DeepEqual(diff.Issues, want) {
+		t.Errorf("SyncCards() issues =\n%+v\nwant\n%+v", diff.Issues, want)
+	}
+	counts := make(map[string]int)
+	for _, c := range diff.Changed {
+		counts[c.Card.ID] = c.Card.Count
+	}
+	// The shield already has one copy
+	if counts["dagger"] != 1 || counts["potion"] != 0 || len(counts) != 2 || diff.Unchanged != 1 {
+		t.Errorf("SyncCards() counts = %v, %d unchanged, want dagger 1, potion 0 and the shield unchanged", counts, diff.Unchanged)
+	}
+}
+
+func TestResolveStyleSharedName(t *testing.T) {
+	styles := map[string]deck.CardLayout{
+		"front-c": {Name: "Gold"},
+		"front-a": {Name: "gold"},
+		"front-b": {Name: "Gold"},
+	}
+	for i := 0; i < 20; i++ {
+		if got := resolveStyle("GOLD", styles); got != "front-a" {
+			t.Fatalf("resolveStyle() = %q, want the first ID, front-a", got)
+		}
+	}
+}
+
+func TestApplySync(t *testing.T) {
+	d := syncDeck()
+	rows := [][]string{{"Name", "Cost"}, {"Dagger", "7"}, {"Dagger 2", "1"}, {"Shield", "3"}}
+	diff, err := SyncCards(d, rows, map[string]string{MapKey: "Name", MapGenerateIDFrom: "Name"})
+	if err != nil {
+		t.Fatalf("SyncCards() error = %v", err)
+	}
+
+	ids := func(cards []deck.Card) []string {
+		var out []string
+		for _, c := range cards {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+
+	kept := ApplySync(d.Cards, diff, false)
+	if got, want := ids(kept), []string{"dagger", "shield", "potion", "dagger-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplySync() IDs = %v, want %v", got, want)
+	}
+	if kept[0].Data["Cost"] != "7" {
+		t.Errorf("ApplySync() did not update dagger: %+v", kept[0])
+	}
+
+	pruned := ApplySync(d.Cards, diff, true)
+	if got, want := ids(pruned), []string{"dagger", "shield", "dagger-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplySync() removing missing IDs = %v, want %v", got, want)
+	}
+}
+
+func TestMergeImport(t *testing.T) {
+	d := deck.Deck{
+		Fields:              []deck.FieldDefinition{{Name: "Name", Type: deck.FieldText}},
+		FrontStyles:         map[string]deck.CardLayout{"front-gold": {Name: "Gold"}},
+		BackStyles:          map[string]deck.CardLayout{"plain": {Name: "Plain"}},
+		DefaultFrontStyleID: "front-gold",
+		DefaultBackStyleID:  "plain",
+	}
+	cards := []deck.Card{
+		{ID: "a", FrontStyleID: "gold", Data: map[string]interface{}{"Name": "A", "Zeal": "1", "Art": "a.png"}},
+		{ID: "b", FrontStyleID: "Silver", BackStyleID: "Silver", Data: map[string]interface{}{"Name": "B", "Cost": 2}},
+	}
+	declared := []deck.FieldDefinition{{Name: "Art", Type: deck.FieldImage}}
+
+	merged := MergeImport(d, cards, declared, []string{"Name", "Cost", "Art"})
+
+	wantFields := []deck.FieldDefinition{
+		{Name: "Name", Type: deck.FieldText},
+		{Name: "Cost", Type: deck.FieldText},
+		{Name: "Art", Type: deck.FieldImage},
+		{Name: "Zeal", Type: deck.FieldText},
+	}
+	if !reflect.DeepEqual(merged.Fields, wantFields) {
+		t.Errorf("MergeImport() fields = %+v, want %+v", merged.Fields, wantFields)
+	}
+	if c := merged.Cards[0]; c.FrontStyleID != "front-gold" || c.BackStyleID != "plain" {
+		t.Errorf("card a styles = %s, %s, want front-gold, plain", c.FrontStyleID, c.BackStyleID)
+	}
+	c := merged.Cards[1]
+	if merged.FrontStyles[c.FrontStyleID].Name != "Silver" || merged.BackStyles[c.BackStyleID].Name != "Silver" {
+		t.Errorf("card b styles = %s, %s, want new Silver styles", c.FrontStyleID, c.BackStyleID)
+	}
+	if len(d.FrontStyles) != 1 {
+		t.Error("MergeImport() changed the original deck's styles")
+	}
+}
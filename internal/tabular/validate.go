@@ -0,0 +1,231 @@
+package tabular
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"card_wizard/internal/deck"
+)
+
+// Issue severities. Errors stop a strict import; warnings never do.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem found in an imported row
+type Issue struct {
+	Row      int    `json:"row"`    // 1-based sheet row, counting the header
+	Column   string `json:"column"` // Header of the cell, empty for the whole row
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// ImportOptions controls how ImportCards validates rows
+type ImportOptions struct {
+	// Strict imports nothing when any row has an error. Otherwise rows with
+	// errors are imported with a fallback: a count of 1 (or 0 when negative) and
+	// a numbered suffix on duplicate IDs.
+	Strict bool
+	// ImageDir is the directory relative image paths resolve against. When empty
+	// only absolute image paths are checked.
+	ImageDir string
+	// Metadata describes the sheet when it came from an export. Its fields add to
+	// the deck's and its style names resolve to the deck's style IDs.
+	Metadata *SheetMetadata
+	// Pictures are the images embedded in the sheet. Those in image fields, or in
+	// columns that are not fields yet, are saved with SaveImage and replaced by
+	// the path it returns.
+	Pictures  []Picture
+	SaveImage func(fileName string, data []byte) (string, error)
+}
+
+// ImportResult is the cards from an import and the issues found in them. Cards
+// is nil when a strict import failed.
+type ImportResult struct {
+	Cards  []deck.Card            `json:"cards"`
+	Issues []Issue                `json:"issues"`
+	Fields []deck.FieldDefinition `json:"fields,omitempty"` // Fields declared by the sheet's metadata or its images
+	Rows   [][]string             `json:"-"`                // The rows with the paths of saved pictures placed
+}
+
+// HasErrors reports whether any issue is an error
+func (r ImportResult) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportCards maps rows to cards like MapCards and checks them against the deck
+// they are imported into: counts must be whole non-negative numbers, IDs unique,
+// styles known, image files present, required fields filled in and typed fields
+// convertible. Values of typed fields are converted to their types; those that
+// cannot be are kept as text. Embedded pictures are saved first, so their paths
+// are checked like any other.
+func ImportCards(rows [][]string, mapping map[string]string, d deck.Deck, opts ImportOptions) (ImportResult, error) {
+	if len(rows) < 1 {
+		return ImportResult{}, fmt.Errorf("file is empty or missing header")
+	}
+
+	var result ImportResult
+	report := func(row int, column, severity, format string, args ...any) {
+		result.Issues = append(result.Issues, Issue{
+			Row:      row,
+			Column:   column,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Fields the deck does not have yet take their types from the metadata
+	fields := d.Fields
+	if meta := opts.Metadata; meta != nil {
+		result.Fields = meta.Fields
+		known := make(map[string]bool)
+		for _, f := range d.Fields {
+			known[f.Name] = true
+		}
+		for _, f := range meta.Fields {
+			if !known[f.Name] {
+				fields = append(fields[:len(fields):len(fields)], f)
+			}
+		}
+	}
+
+	if len(opts.Pictures) > 0 {
+		systemCols := map[string]bool{
+			mapping[MapID]:         true,
+			mapping[MapCount]:      true,
+			mapping[MapFrontStyle]: true,
+			mapping[MapBackStyle]:  true,
+		}
+		delete(systemCols, "")
+		var added []deck.FieldDefinition
+		rows, added = placePictures(rows, opts.Pictures, fields, systemCols, opts.SaveImage, report)
+		fields = append(fields[:len(fields):len(fields)], added...)
+		result.Fields = append(result.Fields, added...)
+	}
+
+	result.Rows = rows
+
+	cards, err := MapCards(rows, mapping)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	headerMap := make(map[string]int)
+	for i, h := range rows[0] {
+		headerMap[h] = i
+	}
+
+	usedIDs := make(map[string]int) // ID -> first sheet row using it
+	for i := range cards {
+		card := &cards[i]
+		rowNum := i + 2
+		row := rows[i+1]
+		cell := func(col string) string {
+			if idx, ok := headerMap[col]; ok && col != "" && idx < len(row) {
+				return strings.TrimSpace(row[idx])
+			}
+			return ""
+		}
+
+		// MapCards has already applied the fallback count
+		if col := mapping[MapCount]; col != "" {
+			if _, err := parseCount(cell(col)); err != nil {
+				report(rowNum, col, SeverityError, "%v", err)
+			}
+		}
+
+		if first, dup := usedIDs[card.ID]; dup {
+			// Point at the column the ID came from, as MapCards picks it
+			idCol := ""
+			switch {
+			case cell(mapping[MapID]) != "":
+				idCol = mapping[MapID]
+			case cell(mapping[MapGenerateIDFrom]) != "":
+				idCol = mapping[MapGenerateIDFrom]
+			}
+			renamed := uniqueID(card.ID, rowNum, idSet(usedIDs))
+			report(rowNum, idCol, SeverityError, "ID %q is already used by row %d; using %q", card.ID, first, renamed)
+			card.ID = renamed
+		}
+		usedIDs[card.ID] = rowNum
+
+		if meta := opts.Metadata; meta != nil {
+			card.FrontStyleID = exportedStyleID(card.FrontStyleID, meta.FrontStyles, d.FrontStyles)
+			card.BackStyleID = exportedStyleID(card.BackStyleID, meta.BackStyles, d.BackStyles)
+		}
+		if name := card.FrontStyleID; name != "" && len(d.FrontStyles) > 0 {
+			if _, ok := d.FrontStyles[resolveStyle(name, d.FrontStyles)]; !ok {
+				report(rowNum, mapping[MapFrontStyle], SeverityWarning, "front style %q does not exist and will be created", name)
+			}
+		}
+		if name := card.BackStyleID; name != "" && len(d.BackStyles) > 0 {
+			if _, ok := d.BackStyles[resolveStyle(name, d.BackStyles)]; !ok {
+				report(rowNum, mapping[MapBackStyle], SeverityWarning, "back style %q does not exist and will be created", name)
+			}
+		}
+
+		for _, f := range fields {
+			raw, ok := card.Data[f.Name]
+			if deck.IsEmpty(raw) {
+				if f.Required {
+					report(rowNum, f.Name, SeverityError, "required field %s is empty", f.Name)
+				}
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			// Store typed fields as numbers, booleans and canonical enum values
+			value, err := f.Coerce(raw)
+			if err != nil {
+				report(rowNum, f.Name, SeverityError, "%v", err)
+				continue
+			}
+			card.Data[f.Name] = value
+
+			if isImageField(f) && !ImageExists(value.(string), opts.ImageDir) {
+				report(rowNum, f.Name, SeverityWarning, "image file %s not found", value)
+			}
+		}
+	}
+
+	if opts.Strict && result.HasErrors() {
+		return result, nil
+	}
+	result.Cards = cards
+	return result, nil
+}
+
+// idSet returns the keys of an ID map as a set for uniqueID
+func idSet(ids map[string]int) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// ImageExists reports whether an image path points at a file. Relative paths
+// are only checked when dir is known; URLs and data URLs are not checked.
+func ImageExists(path, dir string) bool {
+	if strings.Contains(path, "://") || strings.HasPrefix(path, "data:") {
+		return true
+	}
+	if !filepath.IsAbs(path) {
+		if dir == "" {
+			return true
+		}
+		path = filepath.Join(dir, filepath.FromSlash(path))
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,127 @@
+package tabular
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"card_wizard/internal/deck"
+)
+
+func TestImportCardsReport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "images"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "images", "dagger.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := deck.Deck{
+		Fields: []deck.FieldDefinition{
+			{Name: "Name", Type: "text", Required: true},
+			{Name: "Art", Type: "image"},
+		},
+		FrontStyles: map[string]deck.CardLayout{"default-front": {Name: "Default"}, "front-gold": {Name: "Gold"}},
+	}
+	rows := [][]string{
+		{"Name", "Copies", "Style", "Art"},
+		{"Dagger", "2", "gold", "images/dagger.png"},
+		{"Dagger", "two", "Silver", "images/missing.png"},
+		{"", "-1", "", ""},
+	}
+	mapping := map[string]string{MapGenerateIDFrom: "Name", MapCount: "Copies", MapFrontStyle: "Style"}
+
+	result, err := ImportCards(rows, mapping, d, ImportOptions{ImageDir: dir})
+	if err != nil {
+		t.Fatalf("ImportCards() error = %v", err)
+	}
+
+	want := []Issue{
+		{Row: 3, Column: "Copies", Severity: SeverityError, Message: `count "two" is not a whole number; using 1`},
+		{Row: 3, Column: "Name", Severity: SeverityError, Message: `ID "dagger" is already used by row 2; using "dagger-2"`},
+		{Row: 3, Column: "Style", Severity: SeverityWarning, Message: `front style "Silver" does not exist and will be created`},
+		{Row: 3, Column: "Art", Severity: SeverityWarning, Message: "image file images/missing.png not found"},
+		{Row: 4, Column: "Copies", Severity: SeverityError, Message: "count -1 is negative; using 0"},
+		{Row: 4, Column: "Name", Severity: SeverityError, Message: "required field Name is empty"},
+	}
+	if !reflect.DeepEqual(result.Issues, want) {
+		t.Errorf("ImportCards() issues =\n%+v\nwant\n%+v", result.Issues, want)
+	}
+
+	// Lenient imports apply the fallbacks
+	if len(result.Cards) != 3 {
+		t.Fatalf("ImportCards() returned %d cards, want 3", len(result.Cards))
+	}
+	if c := result.Cards[1]; c.ID != "dagger-2" || c.Count != 1 {
+		t.Errorf("card 1 ID = %q, Count = %d, want dagger-2 and 1", c.ID, c.Count)
+	}
+	if c := result.Cards[2]; c.Count != 0 {
+		t.Errorf("card 2 Count = %d, want 0", c.Count)
+	}
+
+	// Strict imports stop on errors but still report them
+	strict, err := ImportCards(rows, mapping, d, ImportOptions{Strict: true, ImageDir: dir})
+	if err != nil {
+		t.Fatalf("ImportCards() strict error = %v", err)
+	}
+	if strict.Cards != nil || !strict.HasErrors() || len(strict.Issues) != len(want) {
+		t.Errorf("ImportCards() strict = %d cards, %d issues", len(strict.Cards), len(strict.Issues))
+	}
+
+	// Warnings alone do not stop a strict import
+	clean, err := ImportCards(rows[:2], mapping, d, ImportOptions{Strict: true, ImageDir: dir})
+	if err != nil || len(clean.Cards) != 1 || len(clean.Issues) != 0 {
+		t.Errorf("ImportCards() strict clean = %+v, %v", clean, err)
+	}
+}
+
+func TestImportCardsReportsIDColumn(t *testing.T) {
+	rows := [][]string{
+		{"ID", "Name"},
+		{"sword", "Sword"},
+		{"sword", "Long Sword"},
+		{"", "Sword"},
+	}
+	mapping := map[string]string{MapID: "ID", MapGenerateIDFrom: "Name"}
+
+	result, err := ImportCards(rows, mapping, deck.Deck{}, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportCards() error = %v", err)
+	}
+	// Row 3 took its ID from the ID column, row 4 generated it from the name
+	if len(result.Issues) != 2 || result.Issues[0].Column != "ID" || result.Issues[1].Column != "Name" {
+		t.Errorf("ImportCards() issues = %+v, want the ID and then the Name column", result.Issues)
+	}
+}
+
+func TestImportCardsCoercesTypedFields(t *testing.T) {
+	d := deck.Deck{Fields: []deck.FieldDefinition{
+		{Name: "Cost", Type: deck.FieldInteger},
+		{Name: "Flying", Type: deck.FieldBoolean},
+		{Name: "Rarity", Type: deck.FieldEnum, Options: []string{"Common", "Rare"}},
+	}}
+	rows := [][]string{
+		{"Cost", "Flying", "Rarity", "Notes"},
+		{"3", "yes", "rare", "007"},
+		{"x", "", "Mythic", ""},
+	}
+
+	result, err := ImportCards(rows, nil, d, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportCards() error = %v", err)
+	}
+
+	want := map[string]interface{}{"Cost": 3, "Flying": true, "Rarity": "Rare", "Notes": "007"}
+	if got := result.Cards[0].Data; !reflect.DeepEqual(got, want) {
+		t.Errorf("card 0 data = %#v, want %#v", got, want)
+	}
+	// Values that do not convert are reported and kept as text
+	if got := result.Cards[1].Data["Cost"]; got != "x" {
+		t.Errorf("card 1 Cost = %#v, want \"x\"", got)
+	}
+	if len(result.Issues) != 2 || result.Issues[0].Column != "Cost" || result.Issues[1].Column != "Rarity" {
+		t.Errorf("ImportCards() issues = %+v, want Cost and Rarity errors", result.Issues)
+	}
+}
@@ -0,0 +1,112 @@
+// Package watch reports changes to a single file, coalescing the bursts of
+// events editors produce while saving into one notification.
+package watch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDelay is how long a file must be quiet before a change is reported
+const DefaultDelay = 500 * time.Millisecond
+
+// Watcher watches one file for changes
+type Watcher struct {
+	path     string
+	delay    time.Duration
+	fsw      *fsnotify.Watcher
+	onChange func()
+	onError  func(error)
+	done     chan struct{}
+}
+
+// New starts watching path. onChange is called once the file has been quiet for
+// delay after a write; onError, if not nil, gets errors from the watcher. Both run
+// on the watcher's goroutine and must not call Close.
+func New(path string, delay time.Duration, onChange func(), onError func(error)) (*Watcher, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	// Watch the directory rather than the file: spreadsheet apps save by writing a
+	// temporary file and renaming it over the original, which ends a file watch
+	if err := fsw.Add(filepath.Dir(abs)); err != nil {
+		fsw.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", filepath.Dir(abs), err)
+	}
+
+	w := &Watcher{
+		path:     abs,
+		delay:    delay,
+		fsw:      fsw,
+		onChange: onChange,
+		onError:  onError,
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Path returns the absolute path being watched
+func (w *Watcher) Path() string {
+	return w.path
+}
+
+// Close stops the watcher and waits for any pending callback to return
+func (w *Watcher) Close() error {
+	err := w.fsw.Close()
+	<-w.done
+	return err
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	timer := time.NewTimer(w.delay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if w.matches(event) {
+				timer.Reset(w.delay)
+			}
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			if w.onError != nil {
+				w.onError(err)
+			}
+		case <-timer.C:
+			w.onChange()
+		}
+	}
+}
+
+// matches reports whether an event changed the watched file's contents
+func (w *Watcher) matches(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+		return false
+	}
+	name, err := filepath.Abs(event.Name)
+	if err != nil {
+		return false
+	}
+	// Case-insensitive file systems report the name as it was written
+	return name == w.path || strings.EqualFold(name, w.path)
+}
@@ -0,0 +1,83 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatcherDebouncesWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cards.csv")
+	if err := os.WriteFile(path, []byte("Name\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	changed := make(chan struct{}, 10)
+	w, err := New(path, 100*time.Millisecond, func() {
+		calls.Add(1)
+		changed <- struct{}{}
+	}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	// Other files in the directory are ignored
+	if err := os.WriteFile(filepath.Join(dir, "~$cards.csv"), []byte("lock"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A burst of writes is reported once
+	for i := 0; i < 5; i++ {
+		if err := os.WriteFile(path, []byte("Name\nDagger\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+	time.Sleep(300 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("onChange called %d times, want 1", n)
+	}
+
+	// Saving by renaming a temporary file over the original is a change too
+	tmp := filepath.Join(dir, "cards.tmp")
+	if err := os.WriteFile(tmp, []byte("Name\nShield\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported after rename")
+	}
+}
+
+func TestWatcherClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cards.csv")
+	w, err := New(path, 10*time.Millisecond, func() {}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if w.Path() != path {
+		t.Errorf("Path() = %q, want %q", w.Path(), path)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	if _, err := New(filepath.Join(t.TempDir(), "missing", "cards.csv"), time.Second, func() {}, nil); err == nil {
+		t.Error("New() in a missing directory succeeded")
+	}
+}
@@ -3,9 +3,6 @@ package main
 import (
 	"embed"
 
-	"net/http"
-	"strings"
-
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
@@ -25,40 +22,12 @@ func main() {
 		Height: 768,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
-			Middleware: func(next http.Handler) http.Handler {
-				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-					if strings.HasPrefix(r.URL.Path, "/local-image") {
-						path := r.URL.Query().Get("path")
-						if path != "" {
-							// Resolve path relative to game if needed
-							resolvedPath := app.ResolveImagePath(path)
-							http.ServeFile(w, r, resolvedPath)
-							return
-						}
-						http.Error(w, "No path specified", http.StatusBadRequest)
-						return
-					}
-					if strings.HasPrefix(r.URL.Path, "/local-font") {
-						path := r.URL.Query().Get("path")
-						if path != "" {
-							// Set appropriate content type for fonts
-							if strings.HasSuffix(strings.ToLower(path), ".ttf") {
-								w.Header().Set("Content-Type", "font/ttf")
-							} else if strings.HasSuffix(strings.ToLower(path), ".otf") {
-								w.Header().Set("Content-Type", "font/otf")
-							}
-							http.ServeFile(w, r, path)
-							return
-						}
-						http.Error(w, "No path specified", http.StatusBadRequest)
-						return
-					}
-					next.ServeHTTP(w, r)
-				})
-			},
+			// Serve local images and fonts, but only the project's own files
+			Middleware: app.project.AssetMiddleware,
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
 		WindowStartState: options.Maximised,
 		Bind: []interface{}{
 			app,